@@ -19,10 +19,13 @@ import (
 	"os"
 	"strings"
 	"sync"
+	"text/tabwriter"
 	"time"
 
 	"github.com/cloudical-io/ancientt/outputs"
 	"github.com/cloudical-io/ancientt/parsers"
+	"github.com/cloudical-io/ancientt/pkg/assertions"
+	"github.com/cloudical-io/ancientt/pkg/compare"
 	"github.com/cloudical-io/ancientt/pkg/config"
 	"github.com/cloudical-io/ancientt/runners"
 	"github.com/cloudical-io/ancientt/testers"
@@ -44,6 +47,11 @@ var (
 		Short: "Ancientt is a tool to automate network testing tools, like iperf3, in dynamic environments such as Kubernetes and more to come dynamic environments.",
 		RunE:  run,
 	}
+	compareCmd = &cobra.Command{
+		Use:   "compare",
+		Short: "Run the tests and compare the results per server and client host pair against the baseline configured in each test's compare options.",
+		RunE:  runCompare,
+	}
 	cfg      *config.Config
 	logLevel string
 )
@@ -66,6 +74,8 @@ func init() {
 	viper.SetDefault("no-cleanup", false)
 	viper.SetDefault("yes", false)
 	viper.SetDefault("testdefinition", "testdefinition.yaml")
+
+	rootCmd.AddCommand(compareCmd)
 }
 
 func main() {
@@ -88,7 +98,21 @@ func loadConfig() error {
 	return err
 }
 
+// dataCollector collects the data of a test for evaluation after the test, e.g., assertions
+type dataCollector interface {
+	Add(data outputs.Data)
+}
+
 func run(cmd *cobra.Command, args []string) error {
+	return runTests(false)
+}
+
+func runCompare(cmd *cobra.Command, args []string) error {
+	return runTests(true)
+}
+
+// runTests run all tests, when compareMode is true the results of each test are compared against its baseline
+func runTests(compareMode bool) error {
 	if viper.GetBool("version") {
 		fmt.Print(version.Print(os.Args[0]))
 		return nil
@@ -130,6 +154,9 @@ func run(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
+	failedAssertionsTests := []string{}
+	regressionsTests := []string{}
+
 	for i, test := range cfg.Tests {
 		log.WithFields(logrus.Fields{"runner": runnerName}).Infof("doing test '%s', %d of %d", test.Name, i+1, len(cfg.Tests))
 
@@ -143,6 +170,20 @@ func run(cmd *cobra.Command, args []string) error {
 			continue
 		}
 
+		evaluator, err := assertions.NewEvaluator(test.Assertions)
+		if err != nil {
+			return fmt.Errorf("failed to parse assertions of test '%s'. %+v", test.Name, err)
+		}
+		collectors := []dataCollector{evaluator}
+
+		var comparer *compare.Comparer
+		if compareMode {
+			if comparer, err = compare.New(test); err != nil {
+				return err
+			}
+			collectors = append(collectors, comparer)
+		}
+
 		// Get hosts for the test
 		hosts, err := runner.GetHostsForTest(test)
 		if err != nil {
@@ -159,6 +200,8 @@ func run(cmd *cobra.Command, args []string) error {
 		}
 		// Set TestStartTime for usage in output / results later on
 		plan.TestStartTime = time.Now()
+		// Record the seeds of random hosts selections to be able to re-run the test with the same hosts
+		plan.Seeds = hosts.Seeds
 
 		fmt.Println(outputSeparator)
 		// Pretty print the plan of the test to the shell
@@ -208,13 +251,26 @@ func run(cmd *cobra.Command, args []string) error {
 				errCh <- err
 				return
 			}
+			// All rounds are done when Parse() returns, send the summary of the test to the outputs
+			summary, err := parser.Summary()
+			if err != nil {
+				errCh <- err
+				return
+			}
+			if summary == nil {
+				return
+			}
+			select {
+			case dataCh <- *summary:
+			case <-doneCh:
+			}
 		}()
 
 		// Start each output
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
-			if err := doOutputs(outputsAssembled, test, doneCh, dataCh); err != nil {
+			if err := doOutputs(outputsAssembled, test, collectors, doneCh, dataCh); err != nil {
 				errCh <- err
 				return
 			}
@@ -240,8 +296,37 @@ func run(cmd *cobra.Command, args []string) error {
 
 		wg.Wait()
 
+		if !evaluator.Empty() {
+			passed, err := evaluateAssertions(outputsAssembled, test, evaluator)
+			if err != nil {
+				logger.Error(err)
+			}
+			if !passed {
+				logger.Error("assertions of test failed")
+				failedAssertionsTests = append(failedAssertionsTests, test.Name)
+			}
+		}
+
+		if comparer != nil {
+			passed, err := compareToBaseline(outputsAssembled, test, comparer)
+			if err != nil {
+				logger.Error(err)
+			}
+			if !passed {
+				logger.Error("regressions against baseline found for test")
+				regressionsTests = append(regressionsTests, test.Name)
+			}
+		}
+
 		close(doneCh)
 
+		closeOutputs(outputsAssembled, &outputs.ReportInfo{
+			TestName: test.Name,
+			Runner:   runnerName,
+			Hosts:    hosts,
+			Plan:     plan,
+		})
+
 		fmt.Println(outputSeparator)
 		fmt.Println(aurora.Magenta("Following files have been created / used:"))
 		for outName, output := range outputsAssembled {
@@ -261,6 +346,17 @@ func run(cmd *cobra.Command, args []string) error {
 
 	log.Info("done with tests")
 
+	failures := []string{}
+	if len(failedAssertionsTests) > 0 {
+		failures = append(failures, fmt.Sprintf("assertions failed for tests: %s", strings.Join(failedAssertionsTests, ", ")))
+	}
+	if len(regressionsTests) > 0 {
+		failures = append(failures, fmt.Sprintf("regressions found for tests: %s", strings.Join(regressionsTests, ", ")))
+	}
+	if len(failures) > 0 {
+		return fmt.Errorf("%s", strings.Join(failures, "; "))
+	}
+
 	return nil
 }
 
@@ -331,7 +427,7 @@ func prepare(test *config.Test, runnerName string) (*log.Entry, testers.Tester,
 	return logger, tester, parser, outputsAssembled, err
 }
 
-func doOutputs(outputsAssembled map[string]outputs.Output, test *config.Test, doneCh chan struct{}, dataCh chan outputs.Data) error {
+func doOutputs(outputsAssembled map[string]outputs.Output, test *config.Test, collectors []dataCollector, doneCh chan struct{}, dataCh chan outputs.Data) error {
 	for {
 		select {
 		case data, ok := <-dataCh:
@@ -339,6 +435,9 @@ func doOutputs(outputsAssembled map[string]outputs.Output, test *config.Test, do
 				log.Debug("dataCh closed, in doOutputs()")
 				return nil
 			}
+			for _, collector := range collectors {
+				collector.Add(data)
+			}
 			for _, outputItem := range test.Outputs {
 				outputName := outputItem.Name
 
@@ -353,6 +452,87 @@ func doOutputs(outputsAssembled map[string]outputs.Output, test *config.Test, do
 	}
 }
 
+// evaluateAssertions evaluate the assertions of the test, print the verdicts and send them to the outputs
+func evaluateAssertions(outputsAssembled map[string]outputs.Output, test *config.Test, evaluator *assertions.Evaluator) (bool, error) {
+	passed := evaluator.Evaluate()
+
+	fmt.Println(outputSeparator)
+	fmt.Println(aurora.Magenta("Assertions verdict:"))
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "SERVER\tCLIENT\tIP FAMILY\tASSERTION\tCOUNT\tACTUAL\tVERDICT")
+	for _, result := range evaluator.Results() {
+		verdict := aurora.Green(result.Verdict)
+		if result.Verdict != assertions.VerdictPass {
+			verdict = aurora.Red(result.Verdict)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%d\t%g\t%s\n", result.ServerHost, result.ClientHost, result.IPFamily, result.Assertion.Expression, result.Count, result.Actual, verdict)
+	}
+	w.Flush()
+	fmt.Println(outputSeparator)
+
+	if err := outputData(outputsAssembled, test, evaluator.Data()); err != nil {
+		return passed, fmt.Errorf("error in output Do() func for assertions. %+v", err)
+	}
+
+	return passed, nil
+}
+
+// compareToBaseline compare the results of the test against the baseline, print the regression report and send it to
+// the outputs
+func compareToBaseline(outputsAssembled map[string]outputs.Output, test *config.Test, comparer *compare.Comparer) (bool, error) {
+	passed := comparer.Compare()
+
+	fmt.Println(outputSeparator)
+	fmt.Println(aurora.Magenta("Baseline comparison:"))
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "SERVER\tCLIENT\tIP FAMILY\tCOLUMN\tBASELINE\tCURRENT\tCHANGE %\tSTATUS")
+	for _, result := range comparer.Results() {
+		status := aurora.Green(result.Status)
+		if result.Status == compare.StatusRegression {
+			status = aurora.Red(result.Status)
+		} else if result.Status != compare.StatusOK {
+			status = aurora.Yellow(result.Status)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s (%s)\t%g\t%g\t%.2f\t%s\n", result.ServerHost, result.ClientHost, result.IPFamily, result.Metric.Column, result.Metric.Aggregation, result.Baseline, result.Current, result.ChangePercent, status)
+	}
+	w.Flush()
+	fmt.Println(outputSeparator)
+
+	if err := outputData(outputsAssembled, test, comparer.Data()); err != nil {
+		return passed, fmt.Errorf("error in output Do() func for regression report. %+v", err)
+	}
+
+	return passed, nil
+}
+
+// outputData send data, which is not coming from the parser, to each output of the test
+func outputData(outputsAssembled map[string]outputs.Output, test *config.Test, data outputs.Data) error {
+	for _, outputItem := range test.Outputs {
+		if err := outputsAssembled[outputItem.Name].Do(data); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// closeOutputs pass the information about the test run to the outputs generating reports and close the outputs
+func closeOutputs(outputsAssembled map[string]outputs.Output, info *outputs.ReportInfo) {
+	info.OutputFiles = map[string][]string{}
+	for outName, output := range outputsAssembled {
+		info.OutputFiles[outName] = output.OutputFiles()
+	}
+
+	for outName, output := range outputsAssembled {
+		if reporter, ok := output.(outputs.Reporter); ok {
+			reporter.SetReportInfo(info)
+		}
+		if err := output.Close(); err != nil {
+			log.WithFields(logrus.Fields{"output": outName}).Errorf("error closing output. %+v", err)
+		}
+	}
+}
+
 func checkForErrors(plan *testers.Plan) error {
 	errorOccured := false
 
@@ -22,7 +22,14 @@ import (
 	_ "github.com/cloudical-io/ancientt/outputs/dump"
 	_ "github.com/cloudical-io/ancientt/outputs/excelize"
 	_ "github.com/cloudical-io/ancientt/outputs/gochart"
+	_ "github.com/cloudical-io/ancientt/outputs/html"
+	_ "github.com/cloudical-io/ancientt/outputs/influxdb"
+	_ "github.com/cloudical-io/ancientt/outputs/json"
+	_ "github.com/cloudical-io/ancientt/outputs/junit"
+	_ "github.com/cloudical-io/ancientt/outputs/markdown"
 	_ "github.com/cloudical-io/ancientt/outputs/mysql"
+	_ "github.com/cloudical-io/ancientt/outputs/postgres"
+	_ "github.com/cloudical-io/ancientt/outputs/prometheus"
 	_ "github.com/cloudical-io/ancientt/outputs/sqlite"
 
 	// Parsers
@@ -32,7 +39,9 @@ import (
 	// Runners
 	_ "github.com/cloudical-io/ancientt/runners/ansible"
 	_ "github.com/cloudical-io/ancientt/runners/kubernetes"
+	_ "github.com/cloudical-io/ancientt/runners/local"
 	_ "github.com/cloudical-io/ancientt/runners/mock"
+	_ "github.com/cloudical-io/ancientt/runners/ssh"
 
 	// Testers
 	_ "github.com/cloudical-io/ancientt/testers/iperf3"
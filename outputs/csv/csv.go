@@ -51,7 +51,7 @@ func NewCSVOutput(cfg *config.Config, outCfg *config.Output) (outputs.Output, er
 		writers: map[string]*csv.Writer{},
 	}
 	if c.config.FilePath.NamePattern == "" {
-		c.config.FilePath.NamePattern = "ancientt-{{ .TestStartTime }}-{{ .Data.Tester }}.csv"
+		c.config.FilePath.NamePattern = "ancientt-{{ .TestStartTime }}-{{ .Data.Tester }}{{ with .Data.Type }}-{{ . }}{{ end }}.csv"
 	}
 	return c, nil
 }
@@ -63,6 +63,11 @@ func (c CSV) Do(data outputs.Data) error {
 		return fmt.Errorf("data not in Table interface format for csv output")
 	}
 
+	if !outputs.SeparatesDataTypes(data, c.config.FilePath.NamePattern) {
+		c.logger.WithFields(logrus.Fields{"type": data.Type}).Debug("skipping non result data, the name pattern doesn't contain the data type")
+		return nil
+	}
+
 	filename, err := outputs.GetFilenameFromPattern(c.config.FilePath.NamePattern, "", data, nil)
 	if err != nil {
 		return err
@@ -20,13 +20,32 @@ import (
 	"github.com/cloudical-io/ancientt/pkg/config"
 )
 
+// DataType type of the data, used to tell the tester results apart from additional data, e.g., end results
+type DataType string
+
+const (
+	// DataTypeResult tester result data (e.g., iperf3 interval results), left empty so the names of
+	// files and tables of existing outputs don't change
+	DataTypeResult DataType = ""
+	// DataTypeEnd end of test results, e.g., iperf3 sender and receiver totals
+	DataTypeEnd DataType = "end"
+	// DataTypeSummary summary of all results of a test, e.g., min, avg, max per server and client host pair
+	DataTypeSummary DataType = "summary"
+	// DataTypeAssertions verdicts of the assertions of a test
+	DataTypeAssertions DataType = "assertions"
+	// DataTypeRegressions regression report of the comparison of a test against a baseline
+	DataTypeRegressions DataType = "regressions"
+)
+
 // Data structured parsed data
 type Data struct {
 	TestStartTime  time.Time
 	TestTime       time.Time
+	Type           DataType
 	Tester         string
 	ServerHost     string
 	ClientHost     string
+	IPFamily       string
 	AdditionalInfo string
 	Data           DataFormat
 }
@@ -55,7 +55,7 @@ func NewExcelizeOutput(cfg *config.Config, outCfg *config.Output) (outputs.Outpu
 		files:  map[string]*fileState{},
 	}
 	if excelize.config.FilePath.NamePattern == "" {
-		excelize.config.FilePath.NamePattern = "ancientt-{{ .TestStartTime }}-{{ .Data.Tester }}.xlsx"
+		excelize.config.FilePath.NamePattern = "ancientt-{{ .TestStartTime }}-{{ .Data.Tester }}{{ with .Data.Type }}-{{ . }}{{ end }}.xlsx"
 	}
 	if excelize.config.SaveAfterRows == 0 {
 		excelize.config.SaveAfterRows = 200
@@ -70,6 +70,11 @@ func (e Excelize) Do(data outputs.Data) error {
 		return fmt.Errorf("data not in Table data type for excel output")
 	}
 
+	if !outputs.SeparatesDataTypes(data, e.config.FilePath.NamePattern) {
+		e.logger.WithFields(logrus.Fields{"type": data.Type}).Debug("skipping non result data, the name pattern doesn't contain the data type")
+		return nil
+	}
+
 	outputFilename, err := outputs.GetFilenameFromPattern(e.config.FilePath.NamePattern, "", data, nil)
 	if err != nil {
 		return err
@@ -59,6 +59,11 @@ func (gc GoChart) Do(data outputs.Data) error {
 	if _, ok := data.Data.(*outputs.Table); !ok {
 		return fmt.Errorf("data not in data table format for gochart output")
 	}
+	// Only the tester results contain the interval / time columns needed for the charts
+	if data.Type != outputs.DataTypeResult {
+		gc.logger.WithFields(logrus.Fields{"type": data.Type}).Debug("skipping non result data")
+		return nil
+	}
 
 	// Iterate over wanted graph types
 	for _, graph := range gc.config.Graphs {
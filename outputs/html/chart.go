@@ -0,0 +1,114 @@
+/*
+Copyright 2022 Cloudical Deutschland GmbH. All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package html
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+
+	"github.com/cloudical-io/ancientt/outputs"
+	"github.com/cloudical-io/ancientt/pkg/config"
+	"github.com/cloudical-io/ancientt/pkg/util"
+	chart "github.com/wcharczuk/go-chart"
+)
+
+// renderChart render the chart for the results of a server and client host pair as SVG with one series per result
+// data (e.g., per round), false is returned when the results don't contain the columns of the chart
+func renderChart(chartOpts *config.HTMLChart, results []outputs.Data) (template.HTML, bool, error) {
+	graph := chart.Chart{
+		Width:  800,
+		Height: 300,
+		Series: []chart.Series{},
+		XAxis: chart.XAxis{
+			Name: chartOpts.TimeColumn,
+		},
+		YAxis: chart.YAxis{
+			Name: chartOpts.Column,
+		},
+	}
+
+	for i, data := range results {
+		dataTable, ok := data.Data.(*outputs.Table)
+		if !ok {
+			continue
+		}
+		xIndex, err := dataTable.GetHeaderIndexByName(chartOpts.TimeColumn)
+		if err != nil {
+			return "", false, err
+		}
+		yIndex, err := dataTable.GetHeaderIndexByName(chartOpts.Column)
+		if err != nil {
+			return "", false, err
+		}
+		if xIndex == -1 || yIndex == -1 {
+			continue
+		}
+		roundIndex, err := dataTable.GetHeaderIndexByName("round")
+		if err != nil {
+			return "", false, err
+		}
+
+		name := fmt.Sprintf("run %d", i+1)
+		xValues := []float64{}
+		yValues := []float64{}
+		for _, row := range dataTable.Rows {
+			if len(row) <= xIndex || len(row) <= yIndex || row[xIndex] == nil || row[yIndex] == nil {
+				continue
+			}
+			x, err := util.CastNumberToFloat64(row[xIndex].Value)
+			if err != nil {
+				continue
+			}
+			y, err := util.CastNumberToFloat64(row[yIndex].Value)
+			if err != nil {
+				continue
+			}
+			if roundIndex != -1 && len(row) > roundIndex && row[roundIndex] != nil {
+				name = fmt.Sprintf("round %s", util.CastToString(row[roundIndex].Value))
+			}
+			xValues = append(xValues, x)
+			yValues = append(yValues, y)
+		}
+		// A line needs at least two points
+		if len(xValues) < 2 {
+			continue
+		}
+
+		graph.Series = append(graph.Series, chart.ContinuousSeries{
+			Name: name,
+			Style: chart.Style{
+				StrokeColor: chart.GetDefaultColor(i),
+			},
+			XValues: xValues,
+			YValues: yValues,
+		})
+	}
+
+	if len(graph.Series) == 0 {
+		return "", false, nil
+	}
+
+	graph.Elements = []chart.Renderable{
+		chart.Legend(&graph),
+	}
+
+	buffer := bytes.NewBuffer([]byte{})
+	if err := graph.Render(chart.SVG, buffer); err != nil {
+		return "", false, fmt.Errorf("failed to render chart to SVG. %+v", err)
+	}
+
+	// The SVG is generated by the chart library from the numeric values and column names
+	return template.HTML(buffer.String()), true, nil
+}
@@ -0,0 +1,414 @@
+/*
+Copyright 2022 Cloudical Deutschland GmbH. All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package html
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/cloudical-io/ancientt/outputs"
+	"github.com/cloudical-io/ancientt/pkg/config"
+	"github.com/cloudical-io/ancientt/pkg/util"
+	"github.com/cloudical-io/ancientt/testers"
+	"github.com/sirupsen/logrus"
+	log "github.com/sirupsen/logrus"
+)
+
+// NameHTML HTML output name
+const NameHTML = "html"
+
+func init() {
+	outputs.Factories[NameHTML] = NewHTMLOutput
+}
+
+// HTML HTML output structure
+type HTML struct {
+	outputs.Output
+	logger  *log.Entry
+	config  *config.HTML
+	info    *outputs.ReportInfo
+	reports map[string][]outputs.Data
+	order   []string
+}
+
+// pair server and client host pair and the IP family the test has been run with
+type pair struct {
+	ServerHost string
+	ClientHost string
+	IPFamily   string
+}
+
+// reportView data for rendering the report template
+type reportView struct {
+	Title         string
+	Tester        string
+	TestStartTime string
+	GeneratedTime string
+	Info          *outputs.ReportInfo
+	Hosts         []hostView
+	Statuses      []statusView
+	Pairs         []*pairView
+	Tables        []tableView
+}
+
+type hostView struct {
+	Name   string
+	Role   string
+	IPv4   string
+	IPv6   string
+	Labels string
+}
+
+type statusView struct {
+	Round  int
+	Server string
+	Role   string
+	Host   string
+	Count  int
+	Failed bool
+	Errors []string
+}
+
+type pairView struct {
+	pair
+	Tables []tableView
+	Charts []template.HTML
+}
+
+type tableView struct {
+	Title   string
+	Headers []string
+	Rows    [][]string
+}
+
+// NewHTMLOutput return a new HTML output instance
+func NewHTMLOutput(cfg *config.Config, outCfg *config.Output) (outputs.Output, error) {
+	if outCfg == nil || outCfg.HTML == nil {
+		return nil, fmt.Errorf("no html output config given")
+	}
+	h := &HTML{
+		logger:  log.WithFields(logrus.Fields{"output": NameHTML}),
+		config:  outCfg.HTML,
+		reports: map[string][]outputs.Data{},
+	}
+	h.config.SetDefaults()
+	if h.config.FilePath.NamePattern == "" {
+		h.config.FilePath.NamePattern = "ancientt-{{ .TestStartTime }}-{{ .Data.Tester }}.html"
+	}
+	return h, nil
+}
+
+// Do collect the data for the report, the report is written on Close()
+func (h *HTML) Do(data outputs.Data) error {
+	if _, ok := data.Data.(*outputs.Table); !ok {
+		return fmt.Errorf("data not in data table format for html output")
+	}
+
+	outPath, err := h.outPath(data)
+	if err != nil {
+		return err
+	}
+
+	if _, ok := h.reports[outPath]; !ok {
+		h.order = append(h.order, outPath)
+	}
+	h.reports[outPath] = append(h.reports[outPath], data)
+
+	return nil
+}
+
+func (h *HTML) outPath(data outputs.Data) (string, error) {
+	filename, err := outputs.GetFilenameFromPattern(h.config.FilePath.NamePattern, "", data, nil)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(h.config.FilePath.FilePath, filename), nil
+}
+
+// SetReportInfo set the information about the test run for the report
+func (h *HTML) SetReportInfo(info *outputs.ReportInfo) {
+	h.info = info
+}
+
+// OutputFiles return a list of output files
+func (h *HTML) OutputFiles() []string {
+	return append([]string{}, h.order...)
+}
+
+// Close write the reports
+func (h *HTML) Close() error {
+	// Write a report even when no data has been received, e.g., all hosts failed, so the failed hosts are reported
+	if len(h.order) == 0 && h.info != nil && h.info.Plan != nil {
+		outPath, err := h.outPath(outputs.Data{
+			TestStartTime: h.info.Plan.TestStartTime,
+			TestTime:      time.Now(),
+			Tester:        h.info.Plan.Tester,
+		})
+		if err != nil {
+			return err
+		}
+		h.order = append(h.order, outPath)
+		h.reports[outPath] = []outputs.Data{}
+	}
+
+	for _, outPath := range h.order {
+		h.logger.WithFields(logrus.Fields{"filepath": outPath}).Debug("writing report")
+
+		out, err := h.render(h.reports[outPath])
+		if err != nil {
+			return fmt.Errorf("failed to render html report %s. %+v", outPath, err)
+		}
+		if err := util.WriteNewTruncFile(outPath, out); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (h *HTML) render(data []outputs.Data) ([]byte, error) {
+	view := &reportView{
+		Title:         h.config.Title,
+		GeneratedTime: time.Now().Format(util.TimeDateFormat),
+		Info:          h.info,
+	}
+	if len(data) > 0 {
+		view.Tester = data[0].Tester
+		view.TestStartTime = data[0].TestStartTime.Format(util.TimeDateFormat)
+	}
+	if h.info != nil {
+		if h.info.Plan != nil {
+			view.Tester = h.info.Plan.Tester
+			view.TestStartTime = h.info.Plan.TestStartTime.Format(util.TimeDateFormat)
+			view.Statuses = statuses(h.info.Plan)
+		}
+		view.Hosts = hosts(h.info.Hosts)
+	}
+
+	pairs := map[pair]*pairView{}
+	results := map[pair][]outputs.Data{}
+	for _, d := range data {
+		dataTable := d.Data.(*outputs.Table)
+		switch d.Type {
+		case outputs.DataTypeResult:
+			p := pair{ServerHost: d.ServerHost, ClientHost: d.ClientHost, IPFamily: d.IPFamily}
+			getPairView(pairs, p)
+			results[p] = append(results[p], d)
+		case outputs.DataTypeEnd, outputs.DataTypeSummary:
+			for p, table := range splitByPair(d, dataTable) {
+				pv := getPairView(pairs, p)
+				pv.Tables = append(pv.Tables, toTableView(typeTitle(d.Type), table))
+			}
+		default:
+			view.Tables = append(view.Tables, toTableView(typeTitle(d.Type), dataTable))
+		}
+	}
+
+	for p, pv := range pairs {
+		for _, chartOpts := range h.config.Charts {
+			svg, ok, err := renderChart(chartOpts, results[p])
+			if err != nil {
+				h.logger.WithFields(logrus.Fields{"server": p.ServerHost, "client": p.ClientHost, "ipFamily": p.IPFamily}).
+					Warnf("failed to render chart %s over %s. %+v", chartOpts.Column, chartOpts.TimeColumn, err)
+				continue
+			}
+			if ok {
+				pv.Charts = append(pv.Charts, svg)
+			}
+		}
+		view.Pairs = append(view.Pairs, pv)
+	}
+	sort.Slice(view.Pairs, func(i, j int) bool {
+		if view.Pairs[i].ServerHost != view.Pairs[j].ServerHost {
+			return view.Pairs[i].ServerHost < view.Pairs[j].ServerHost
+		}
+		if view.Pairs[i].ClientHost != view.Pairs[j].ClientHost {
+			return view.Pairs[i].ClientHost < view.Pairs[j].ClientHost
+		}
+		return view.Pairs[i].IPFamily < view.Pairs[j].IPFamily
+	})
+
+	var out bytes.Buffer
+	if err := reportTemplate.Execute(&out, view); err != nil {
+		return nil, err
+	}
+
+	return out.Bytes(), nil
+}
+
+func getPairView(pairs map[pair]*pairView, p pair) *pairView {
+	pv, ok := pairs[p]
+	if !ok {
+		pv = &pairView{pair: p}
+		pairs[p] = pv
+	}
+	return pv
+}
+
+func typeTitle(dataType outputs.DataType) string {
+	if dataType == outputs.DataTypeResult {
+		return "Results"
+	}
+	return strings.Title(string(dataType))
+}
+
+// splitByPair split the rows of the table by the `server_host`, `client_host` and `ip_family` columns, the hosts and IP
+// family of the data are used when the columns don't exist
+func splitByPair(data outputs.Data, dataTable *outputs.Table) map[pair]*outputs.Table {
+	serverIndex, _ := dataTable.GetHeaderIndexByName("server_host")
+	clientIndex, _ := dataTable.GetHeaderIndexByName("client_host")
+	ipFamilyIndex, _ := dataTable.GetHeaderIndexByName("ip_family")
+
+	tables := map[pair]*outputs.Table{}
+	for _, row := range dataTable.Rows {
+		p := pair{ServerHost: data.ServerHost, ClientHost: data.ClientHost, IPFamily: data.IPFamily}
+		if serverIndex != -1 && len(row) > serverIndex && row[serverIndex] != nil {
+			p.ServerHost = util.CastToString(row[serverIndex].Value)
+		}
+		if clientIndex != -1 && len(row) > clientIndex && row[clientIndex] != nil {
+			p.ClientHost = util.CastToString(row[clientIndex].Value)
+		}
+		if ipFamilyIndex != -1 && len(row) > ipFamilyIndex && row[ipFamilyIndex] != nil {
+			p.IPFamily = util.CastToString(row[ipFamilyIndex].Value)
+		}
+
+		table, ok := tables[p]
+		if !ok {
+			table = &outputs.Table{Headers: dataTable.Headers}
+			tables[p] = table
+		}
+		table.Rows = append(table.Rows, row)
+	}
+
+	return tables
+}
+
+func toTableView(title string, dataTable *outputs.Table) tableView {
+	view := tableView{Title: title}
+
+	skip := map[int]bool{}
+	for i, header := range dataTable.Headers {
+		if header == nil {
+			skip[i] = true
+			continue
+		}
+		view.Headers = append(view.Headers, util.CastToString(header.Value))
+	}
+	for _, row := range dataTable.Rows {
+		cells := []string{}
+		for i, r := range row {
+			if skip[i] || r == nil {
+				continue
+			}
+			cells = append(cells, util.CastToString(r.Value))
+		}
+		if len(cells) == 0 {
+			continue
+		}
+		view.Rows = append(view.Rows, cells)
+	}
+
+	return view
+}
+
+func hosts(hosts *testers.Hosts) []hostView {
+	if hosts == nil {
+		return nil
+	}
+
+	list := []hostView{}
+	for _, role := range []struct {
+		name  string
+		hosts map[string]*testers.Host
+	}{
+		{"server", hosts.Servers},
+		{"client", hosts.Clients},
+	} {
+		names := []string{}
+		for name := range role.hosts {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			host := role.hosts[name]
+			hv := hostView{Name: host.Name, Role: role.name}
+			if host.Addresses != nil {
+				hv.IPv4 = strings.Join(host.Addresses.IPv4, ", ")
+				hv.IPv6 = strings.Join(host.Addresses.IPv6, ", ")
+			}
+			labels := []string{}
+			for k, v := range host.Labels {
+				labels = append(labels, fmt.Sprintf("%s=%s", k, v))
+			}
+			sort.Strings(labels)
+			hv.Labels = strings.Join(labels, ", ")
+			list = append(list, hv)
+		}
+	}
+
+	return list
+}
+
+// statuses the failed and successful hosts of each task of the plan, same as printed after the test has been run
+func statuses(plan *testers.Plan) []statusView {
+	list := []statusView{}
+	for round, command := range plan.Commands {
+		for _, task := range command {
+			if task.Status == nil || task.Sleep != 0 {
+				continue
+			}
+			for _, status := range []struct {
+				role   string
+				failed bool
+				hosts  map[string]int
+			}{
+				{"server", true, task.Status.FailedHosts.Servers},
+				{"client", true, task.Status.FailedHosts.Clients},
+				{"server", false, task.Status.SuccessfulHosts.Servers},
+				{"client", false, task.Status.SuccessfulHosts.Clients},
+			} {
+				names := []string{}
+				for name := range status.hosts {
+					names = append(names, name)
+				}
+				sort.Strings(names)
+
+				for _, name := range names {
+					sv := statusView{
+						Round:  round + 1,
+						Server: task.Host.Name,
+						Role:   status.role,
+						Host:   name,
+						Count:  status.hosts[name],
+						Failed: status.failed,
+					}
+					if status.failed {
+						for _, err := range task.Status.Errors[name] {
+							sv.Errors = append(sv.Errors, err.Error())
+						}
+					}
+					list = append(list, sv)
+				}
+			}
+		}
+	}
+
+	return list
+}
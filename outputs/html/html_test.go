@@ -0,0 +1,208 @@
+/*
+Copyright 2022 Cloudical Deutschland GmbH. All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package html
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cloudical-io/ancientt/outputs"
+	"github.com/cloudical-io/ancientt/pkg/config"
+	"github.com/cloudical-io/ancientt/testers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testStartTime = time.Date(2022, 3, 1, 10, 0, 0, 0, time.UTC)
+
+func generateResultData(round int, server string, client string, ipFamily string) outputs.Data {
+	table := &outputs.Table{
+		Headers: []*outputs.Row{
+			{Value: "round"},
+			{Value: "server_host"},
+			{Value: "client_host"},
+			{Value: "start"},
+			{Value: "bits_per_second"},
+		},
+	}
+	for i := 0; i < 5; i++ {
+		table.Rows = append(table.Rows, []*outputs.Row{
+			{Value: round},
+			{Value: server},
+			{Value: client},
+			{Value: float64(i)},
+			{Value: float64(1000 * (i + 1))},
+		})
+	}
+
+	return outputs.Data{
+		TestStartTime: testStartTime,
+		TestTime:      testStartTime,
+		Tester:        "iperf3",
+		ServerHost:    server,
+		ClientHost:    client,
+		IPFamily:      ipFamily,
+		Data:          table,
+	}
+}
+
+func generateReportInfo() *outputs.ReportInfo {
+	server := &testers.Host{Name: "server1", Labels: map[string]string{"zone": "a"}, Addresses: &testers.IPAddresses{IPv4: []string{"10.0.0.1"}}}
+	client := &testers.Host{Name: "client1", Labels: map[string]string{}, Addresses: &testers.IPAddresses{IPv4: []string{"10.0.0.2"}}}
+	failedClient := &testers.Host{Name: "client2", Labels: map[string]string{}, Addresses: &testers.IPAddresses{}}
+
+	status := &testers.Status{
+		SuccessfulHosts: testers.StatusHosts{Servers: map[string]int{}, Clients: map[string]int{}},
+		FailedHosts:     testers.StatusHosts{Servers: map[string]int{}, Clients: map[string]int{}},
+		Errors:          map[string][]error{},
+	}
+	status.AddSuccessfulServer(server)
+	status.AddSuccessfulClient(client)
+	status.AddFailedClient(failedClient, fmt.Errorf("connection <refused>"))
+
+	return &outputs.ReportInfo{
+		TestName: "iperf3-test",
+		Runner:   "mock",
+		Hosts: &testers.Hosts{
+			Servers: map[string]*testers.Host{"server1": server},
+			Clients: map[string]*testers.Host{"client1": client, "client2": failedClient},
+		},
+		Plan: &testers.Plan{
+			TestStartTime: testStartTime,
+			Tester:        "iperf3",
+			Commands: [][]*testers.Task{{
+				{
+					Host:    server,
+					Command: "iperf3",
+					Args:    []string{"--server"},
+					SubTasks: []*testers.Task{
+						{Host: client, Command: "iperf3", Args: []string{"--client=10.0.0.1"}},
+						{Host: failedClient, Command: "iperf3", Args: []string{"--client=10.0.0.1"}},
+					},
+					Status: status,
+				},
+				{Sleep: 10 * time.Second},
+			}},
+		},
+	}
+}
+
+func TestHTML(t *testing.T) {
+	tempDir := t.TempDir()
+	outCfg := &config.Output{
+		HTML: &config.HTML{
+			FilePath: config.FilePath{
+				FilePath: tempDir,
+			},
+		},
+	}
+
+	h, err := NewHTMLOutput(nil, outCfg)
+	require.Nil(t, err)
+
+	require.Nil(t, h.Do(generateResultData(0, "server1", "client1", "v4")))
+	require.Nil(t, h.Do(generateResultData(1, "server1", "client1", "v4")))
+	require.Nil(t, h.Do(generateResultData(0, "server1", "client1", "v6")))
+	require.Nil(t, h.Do(outputs.Data{
+		TestStartTime: testStartTime,
+		Type:          outputs.DataTypeSummary,
+		Tester:        "iperf3",
+		Data: &outputs.Table{
+			Headers: []*outputs.Row{{Value: "server_host"}, {Value: "client_host"}, {Value: "ip_family"}, {Value: "metric"}, {Value: "avg"}},
+			Rows: [][]*outputs.Row{
+				{{Value: "server1"}, {Value: "client1"}, {Value: "v4"}, {Value: "bits_per_second"}, {Value: 3000.5}},
+				{{Value: "server1"}, {Value: "client1"}, {Value: "v6"}, {Value: "bits_per_second"}, {Value: 2000.5}},
+			},
+		},
+	}))
+	require.Nil(t, h.Do(outputs.Data{
+		TestStartTime: testStartTime,
+		Type:          outputs.DataTypeAssertions,
+		Tester:        "iperf3",
+		Data: &outputs.Table{
+			Headers: []*outputs.Row{{Value: "assertion"}, {Value: "verdict"}},
+			Rows: [][]*outputs.Row{
+				{{Value: "bits_per_second avg >= 1000"}, {Value: "pass"}},
+			},
+		},
+	}))
+
+	// Nothing is written before Close()
+	outPath := filepath.Join(tempDir, "ancientt-1646128800-iperf3.html")
+	assert.Equal(t, []string{outPath}, h.OutputFiles())
+	_, err = ioutil.ReadFile(outPath)
+	assert.NotNil(t, err)
+
+	reporter, ok := h.(outputs.Reporter)
+	require.True(t, ok)
+	reporter.SetReportInfo(generateReportInfo())
+	require.Nil(t, h.Close())
+
+	out, err := ioutil.ReadFile(outPath)
+	require.Nil(t, err)
+	report := string(out)
+
+	assert.Contains(t, report, "<title>ancientt report</title>")
+	assert.Contains(t, report, "<td>iperf3-test</td>")
+	// Hosts
+	assert.Contains(t, report, "<td>server1</td><td>server</td><td>10.0.0.1</td><td></td><td>zone=a</td>")
+	// Plan
+	assert.Contains(t, report, "Wait for 10s")
+	assert.Contains(t, report, "<td>server1</td><td>client2</td><td><pre>iperf3 --client=10.0.0.1 </pre></td>")
+	// Failed hosts with escaped error
+	assert.Contains(t, report, `<tr class="failed"><td>1</td><td>server1</td><td>client</td><td>client2</td><td>failed</td><td>1</td><td><pre>connection &lt;refused&gt;</pre></td></tr>`)
+	assert.Contains(t, report, `<td>client1</td><td>successful</td>`)
+	// Assertions
+	assert.Contains(t, report, "<h2>Assertions</h2>")
+	assert.Contains(t, report, "<td>bits_per_second avg &gt;= 1000</td>")
+	// Per pair and IP family summary and chart with a series per round
+	assert.Contains(t, report, "<h3>server1 &larr; client1 (v4)</h3>")
+	assert.Contains(t, report, "<h3>server1 &larr; client1 (v6)</h3>")
+	assert.Equal(t, 2, strings.Count(report, "<h4>Summary</h4>"))
+	assert.Contains(t, report, "<td>3000.500000</td>")
+	assert.Contains(t, report, "<td>2000.500000</td>")
+	assert.Equal(t, 2, strings.Count(report, "<svg"))
+	assert.Contains(t, report, "round 0")
+	assert.Contains(t, report, "round 1")
+}
+
+func TestHTMLWithoutData(t *testing.T) {
+	tempDir := t.TempDir()
+	outCfg := &config.Output{
+		HTML: &config.HTML{
+			FilePath: config.FilePath{
+				FilePath: tempDir,
+			},
+			Title: "Failed run",
+		},
+	}
+
+	h, err := NewHTMLOutput(nil, outCfg)
+	require.Nil(t, err)
+
+	h.(outputs.Reporter).SetReportInfo(generateReportInfo())
+	require.Nil(t, h.Close())
+
+	outPath := filepath.Join(tempDir, "ancientt-1646128800-iperf3.html")
+	assert.Equal(t, []string{outPath}, h.OutputFiles())
+	out, err := ioutil.ReadFile(outPath)
+	require.Nil(t, err)
+	assert.Contains(t, string(out), "<h1>Failed run</h1>")
+	assert.Contains(t, string(out), "connection &lt;refused&gt;")
+	assert.NotContains(t, string(out), "<svg")
+}
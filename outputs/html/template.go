@@ -0,0 +1,124 @@
+/*
+Copyright 2022 Cloudical Deutschland GmbH. All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package html
+
+import "html/template"
+
+// reportTemplate self-contained HTML report, the styles are inlined and the charts are embedded as SVG
+var reportTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	"inc": func(i int) int {
+		return i + 1
+	},
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{ .Title }}</title>
+<style>
+body { font-family: sans-serif; margin: 2em; color: #222; }
+h1, h2, h3 { color: #1d3557; }
+table { border-collapse: collapse; margin-bottom: 1.5em; font-size: 0.9em; }
+th, td { border: 1px solid #ccc; padding: 0.3em 0.6em; text-align: left; }
+th { background: #f1f1f1; }
+tr.failed td { background: #fde2e2; }
+tr.successful td { background: #e3f6e5; }
+pre { background: #f6f6f6; padding: 0.2em 0.4em; margin: 0; white-space: pre-wrap; }
+.chart { margin-bottom: 1em; }
+.meta td:first-child { font-weight: bold; }
+</style>
+</head>
+<body>
+<h1>{{ .Title }}</h1>
+<table class="meta">
+{{- with .Info }}
+<tr><td>Test</td><td>{{ .TestName }}</td></tr>
+<tr><td>Runner</td><td>{{ .Runner }}</td></tr>
+{{- with .Plan }}{{ range $name, $seed := .Seeds }}
+<tr><td>Seed {{ $name }}</td><td>{{ $seed }}</td></tr>
+{{- end }}{{ end }}
+{{- end }}
+<tr><td>Tester</td><td>{{ .Tester }}</td></tr>
+<tr><td>Test start time</td><td>{{ .TestStartTime }}</td></tr>
+<tr><td>Report generated</td><td>{{ .GeneratedTime }}</td></tr>
+</table>
+
+{{- if .Hosts }}
+<h2>Hosts</h2>
+<table>
+<tr><th>Name</th><th>Role</th><th>IPv4</th><th>IPv6</th><th>Labels</th></tr>
+{{- range .Hosts }}
+<tr><td>{{ .Name }}</td><td>{{ .Role }}</td><td>{{ .IPv4 }}</td><td>{{ .IPv6 }}</td><td>{{ .Labels }}</td></tr>
+{{- end }}
+</table>
+{{- end }}
+
+{{- with .Info }}{{ with .Plan }}
+<h2>Plan</h2>
+<table>
+<tr><th>Round</th><th>Server</th><th>Client</th><th>Command</th></tr>
+{{- range $round, $tasks := .Commands }}
+{{- range $tasks }}
+{{- if .Sleep }}
+<tr><td>{{ inc $round }}</td><td colspan="3">Wait for {{ .Sleep }}</td></tr>
+{{- else }}
+<tr><td>{{ inc $round }}</td><td>{{ .Host.Name }}</td><td></td><td><pre>{{ .Command }} {{ range .Args }}{{ . }} {{ end }}</pre></td></tr>
+{{- $server := .Host.Name }}
+{{- range .SubTasks }}
+<tr><td>{{ inc $round }}</td><td>{{ $server }}</td><td>{{ .Host.Name }}</td><td><pre>{{ .Command }} {{ range .Args }}{{ . }} {{ end }}</pre></td></tr>
+{{- end }}
+{{- end }}
+{{- end }}
+{{- end }}
+</table>
+{{- end }}{{ end }}
+
+{{- if .Statuses }}
+<h2>Hosts Status</h2>
+<table>
+<tr><th>Round</th><th>Server</th><th>Role</th><th>Host</th><th>Status</th><th>Count</th><th>Errors</th></tr>
+{{- range .Statuses }}
+<tr class="{{ if .Failed }}failed{{ else }}successful{{ end }}"><td>{{ .Round }}</td><td>{{ .Server }}</td><td>{{ .Role }}</td><td>{{ .Host }}</td><td>{{ if .Failed }}failed{{ else }}successful{{ end }}</td><td>{{ .Count }}</td><td>{{ range .Errors }}<pre>{{ . }}</pre>{{ end }}</td></tr>
+{{- end }}
+</table>
+{{- end }}
+
+{{- range .Tables }}
+<h2>{{ .Title }}</h2>
+{{ template "table" . }}
+{{- end }}
+
+{{- if .Pairs }}
+<h2>Results per Server and Client</h2>
+{{- range .Pairs }}
+<h3>{{ .ServerHost }} &larr; {{ .ClientHost }}{{ with .IPFamily }} ({{ . }}){{ end }}</h3>
+{{- range .Charts }}
+<div class="chart">{{ . }}</div>
+{{- end }}
+{{- range .Tables }}
+<h4>{{ .Title }}</h4>
+{{ template "table" . }}
+{{- end }}
+{{- end }}
+{{- end }}
+</body>
+</html>
+{{- define "table" }}
+<table>
+<tr>{{ range .Headers }}<th>{{ . }}</th>{{ end }}</tr>
+{{- range .Rows }}
+<tr>{{ range . }}<td>{{ . }}</td>{{ end }}</tr>
+{{- end }}
+</table>
+{{- end }}
+`))
@@ -0,0 +1,312 @@
+/*
+Copyright 2022 Cloudical Deutschland GmbH. All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package influxdb
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cloudical-io/ancientt/outputs"
+	"github.com/cloudical-io/ancientt/pkg/config"
+	"github.com/cloudical-io/ancientt/pkg/util"
+	"github.com/sirupsen/logrus"
+	log "github.com/sirupsen/logrus"
+)
+
+// NameInfluxDB InfluxDB output name
+const NameInfluxDB = "influxdb"
+
+const defaultNamePattern = "ancientt-{{ .TestStartTime }}-{{ .Data.Tester }}.lp"
+
+func init() {
+	outputs.Factories[NameInfluxDB] = NewInfluxDBOutput
+}
+
+// InfluxDB InfluxDB output structure
+type InfluxDB struct {
+	outputs.Output
+	logger   *log.Entry
+	config   *config.InfluxDB
+	files    map[string]*os.File
+	client   *http.Client
+	writeURL string
+}
+
+// NewInfluxDBOutput return a new InfluxDB output instance
+func NewInfluxDBOutput(cfg *config.Config, outCfg *config.Output) (outputs.Output, error) {
+	if outCfg == nil || outCfg.InfluxDB == nil {
+		return nil, fmt.Errorf("no influxdb output config given")
+	}
+	i := InfluxDB{
+		logger: log.WithFields(logrus.Fields{"output": NameInfluxDB}),
+		config: outCfg.InfluxDB,
+		files:  map[string]*os.File{},
+	}
+	i.config.SetDefaults()
+
+	if i.config.File == nil && i.config.HTTP == nil {
+		return nil, fmt.Errorf("neither file nor http configured for influxdb output")
+	}
+	if i.config.File != nil && i.config.File.NamePattern == "" {
+		i.config.File.NamePattern = defaultNamePattern
+	}
+
+	if i.config.HTTP != nil {
+		i.config.HTTP.SetDefaults()
+
+		u, err := url.Parse(i.config.HTTP.URL)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse influxdb url. %+v", err)
+		}
+		u.Path = path.Join(u.Path, "/api/v2/write")
+		query := u.Query()
+		query.Set("org", i.config.HTTP.Org)
+		query.Set("bucket", i.config.HTTP.Bucket)
+		query.Set("precision", "ns")
+		u.RawQuery = query.Encode()
+
+		i.writeURL = u.String()
+		i.client = &http.Client{Timeout: i.config.HTTP.Timeout}
+	}
+
+	return i, nil
+}
+
+// Do make InfluxDB outputs
+func (i InfluxDB) Do(data outputs.Data) error {
+	dataTable, ok := data.Data.(*outputs.Table)
+	if !ok {
+		return fmt.Errorf("data not in data table format for influxdb output")
+	}
+
+	lines, err := i.lines(data, dataTable)
+	if err != nil {
+		return err
+	}
+	if len(lines) == 0 {
+		return nil
+	}
+
+	if i.config.File != nil {
+		if err := i.writeFile(data, lines); err != nil {
+			return err
+		}
+	}
+
+	if i.config.HTTP != nil {
+		for start := 0; start < len(lines); start += i.config.HTTP.BatchSize {
+			end := start + i.config.HTTP.BatchSize
+			if end > len(lines) {
+				end = len(lines)
+			}
+			if err := i.write(lines[start:end]); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// lines convert each row of the data table into a point in the line protocol
+func (i InfluxDB) lines(data outputs.Data, dataTable *outputs.Table) ([]string, error) {
+	measurement, err := outputs.GetFilenameFromPattern(i.config.MeasurementPattern, "", data, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	tagColumns := map[string]bool{}
+	for _, tag := range i.config.Tags {
+		tagColumns[tag] = true
+	}
+
+	headers := make([]string, len(dataTable.Headers))
+	for k, header := range dataTable.Headers {
+		if header == nil {
+			continue
+		}
+		headers[k] = util.CastToString(header.Value)
+	}
+
+	lines := []string{}
+	for _, row := range dataTable.Rows {
+		p := point{
+			measurement: measurement,
+			tags: map[string]string{
+				"tester":      data.Tester,
+				"server_host": data.ServerHost,
+				"client_host": data.ClientHost,
+			},
+			fields: map[string]interface{}{},
+			time:   data.TestTime,
+		}
+		// Only keep the tags from the data which are configured
+		for tag := range p.tags {
+			if !tagColumns[tag] {
+				delete(p.tags, tag)
+			}
+		}
+
+		var interval float64
+		for k, r := range row {
+			if r == nil || k >= len(headers) || headers[k] == "" {
+				continue
+			}
+
+			switch column := headers[k]; {
+			case tagColumns[column]:
+				p.tags[column] = util.CastToString(r.Value)
+			case column == i.config.TimeColumn:
+				if ts, ok := r.Value.(time.Time); ok {
+					p.time = ts
+				} else if ts, err := time.Parse(util.TimeDateFormat, util.CastToString(r.Value)); err == nil {
+					p.time = ts
+				}
+			default:
+				if column == i.config.IntervalColumn {
+					if value, err := util.CastNumberToFloat64(r.Value); err == nil {
+						interval = value
+					}
+				}
+				if value, ok := fieldValue(r.Value); ok {
+					p.fields[column] = value
+				}
+			}
+		}
+
+		// Points without fields are not allowed in the line protocol
+		if len(p.fields) == 0 {
+			continue
+		}
+
+		p.time = p.time.Add(time.Duration(interval * float64(time.Second)))
+		lines = append(lines, p.line())
+	}
+
+	return lines, nil
+}
+
+func (i InfluxDB) writeFile(data outputs.Data, lines []string) error {
+	filename, err := outputs.GetFilenameFromPattern(i.config.File.NamePattern, "", data, nil)
+	if err != nil {
+		return err
+	}
+
+	outPath := filepath.Join(i.config.File.FilePath, filename)
+	file, ok := i.files[outPath]
+	if !ok {
+		file, err = os.Create(outPath)
+		if err != nil {
+			return err
+		}
+		i.files[outPath] = file
+	}
+
+	if _, err := file.WriteString(strings.Join(lines, "\n") + "\n"); err != nil {
+		return fmt.Errorf("failed to write influxdb line protocol file %s. %+v", outPath, err)
+	}
+
+	return nil
+}
+
+// write write the lines to the InfluxDB HTTP API, retrying on connection errors and retryable responses
+func (i InfluxDB) write(lines []string) error {
+	body := strings.Join(lines, "\n")
+
+	var err error
+	var retry bool
+	var wait time.Duration
+	for attempt := 0; attempt <= *i.config.HTTP.Retries; attempt++ {
+		if attempt > 0 {
+			if wait == 0 {
+				wait = i.config.HTTP.RetryInterval
+			}
+			i.logger.Warnf("retrying influxdb write in %s (attempt %d of %d). %+v", wait, attempt, *i.config.HTTP.Retries, err)
+			time.Sleep(wait)
+		}
+
+		if retry, wait, err = i.doWrite(body); err == nil || !retry {
+			break
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write %d points to influxdb. %+v", len(lines), err)
+	}
+
+	i.logger.Debugf("wrote %d points to influxdb", len(lines))
+
+	return nil
+}
+
+// doWrite run a write request, returns if the request should be retried and how long to wait when the response
+// contains a `Retry-After` header
+func (i InfluxDB) doWrite(body string) (bool, time.Duration, error) {
+	req, err := http.NewRequest(http.MethodPost, i.writeURL, strings.NewReader(body))
+	if err != nil {
+		return false, 0, err
+	}
+	req.Header.Set("Content-Type", "text/plain; charset=utf-8")
+	if i.config.HTTP.Token != "" {
+		req.Header.Set("Authorization", "Token "+i.config.HTTP.Token)
+	}
+
+	resp, err := i.client.Do(req)
+	if err != nil {
+		return true, 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false, 0, nil
+	}
+
+	out, _ := ioutil.ReadAll(resp.Body)
+	err = fmt.Errorf("unexpected status code %d (response: %s)", resp.StatusCode, strings.TrimSpace(string(out)))
+
+	var wait time.Duration
+	if seconds, perr := strconv.Atoi(resp.Header.Get("Retry-After")); perr == nil {
+		wait = time.Duration(seconds) * time.Second
+	}
+
+	return resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500, wait, err
+}
+
+// OutputFiles return a list of output files
+func (i InfluxDB) OutputFiles() []string {
+	list := []string{}
+	for file := range i.files {
+		list = append(list, file)
+	}
+	return list
+}
+
+// Close close all file descriptors here
+func (i InfluxDB) Close() error {
+	for name, file := range i.files {
+		i.logger.WithFields(logrus.Fields{"filepath": name}).Debug("closing file")
+		if err := file.Close(); err != nil {
+			i.logger.WithFields(logrus.Fields{"filepath": name}).Errorf("error closing file. %+v", err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,163 @@
+/*
+Copyright 2022 Cloudical Deutschland GmbH. All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package influxdb
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/cloudical-io/ancientt/outputs"
+	"github.com/cloudical-io/ancientt/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func generateTestData() outputs.Data {
+	testTime := time.Date(2022, 3, 1, 10, 0, 0, 0, time.UTC)
+	return outputs.Data{
+		TestStartTime: testTime,
+		TestTime:      testTime,
+		Tester:        "iperf3",
+		ServerHost:    "host1",
+		ClientHost:    "host 2",
+		Data: &outputs.Table{
+			Headers: []*outputs.Row{
+				{Value: "test_time"},
+				{Value: "round"},
+				{Value: "socket"},
+				{Value: "start"},
+				{Value: "bits_per_second"},
+				{Value: "retransmits"},
+				{Value: "omitted"},
+				{Value: "system_info"},
+			},
+			Rows: [][]*outputs.Row{
+				{{Value: "2022-03-01T10:00:00+0000"}, {Value: 0}, {Value: 5}, {Value: 0.0}, {Value: 12000.5}, {Value: 3}, {Value: false}, {Value: "Linux"}},
+				{{Value: "2022-03-01T10:00:00+0000"}, {Value: 0}, {Value: 5}, {Value: 1.5}, {Value: 14000.0}, {Value: 0}, {Value: false}, {Value: "Linux"}},
+			},
+		},
+	}
+}
+
+var expectedLines = []string{
+	`ancientt_iperf3,client_host=host\ 2,round=0,server_host=host1,socket=5,tester=iperf3 bits_per_second=12000.5,omitted=false,retransmits=3i,start=0 1646128800000000000`,
+	`ancientt_iperf3,client_host=host\ 2,round=0,server_host=host1,socket=5,tester=iperf3 bits_per_second=14000,omitted=false,retransmits=0i,start=1.5 1646128801500000000`,
+}
+
+func TestInfluxDBFile(t *testing.T) {
+	tempDir := t.TempDir()
+	outCfg := &config.Output{
+		InfluxDB: &config.InfluxDB{
+			File: &config.FilePath{
+				FilePath: tempDir,
+			},
+		},
+	}
+
+	i, err := NewInfluxDBOutput(nil, outCfg)
+	require.Nil(t, err)
+
+	data := generateTestData()
+	require.Nil(t, i.Do(data))
+	require.Nil(t, i.Close())
+
+	outPath := filepath.Join(tempDir, "ancientt-1646128800-iperf3.lp")
+	assert.Equal(t, []string{outPath}, i.OutputFiles())
+
+	out, err := ioutil.ReadFile(outPath)
+	require.Nil(t, err)
+	assert.Equal(t, strings.Join(expectedLines, "\n")+"\n", string(out))
+}
+
+func TestInfluxDBHTTP(t *testing.T) {
+	var lock sync.Mutex
+	requests := 0
+	bodies := []string{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lock.Lock()
+		defer lock.Unlock()
+		requests++
+
+		assert.Equal(t, "/api/v2/write", r.URL.Path)
+		assert.Equal(t, "myorg", r.URL.Query().Get("org"))
+		assert.Equal(t, "network", r.URL.Query().Get("bucket"))
+		assert.Equal(t, "ns", r.URL.Query().Get("precision"))
+		assert.Equal(t, "Token secret", r.Header.Get("Authorization"))
+
+		// Fail the first request to check that it is retried
+		if requests == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		out, _ := ioutil.ReadAll(r.Body)
+		bodies = append(bodies, string(out))
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	outCfg := &config.Output{
+		InfluxDB: &config.InfluxDB{
+			HTTP: &config.InfluxDBHTTP{
+				URL:           server.URL,
+				Org:           "myorg",
+				Bucket:        "network",
+				Token:         "secret",
+				BatchSize:     1,
+				RetryInterval: 10 * time.Millisecond,
+			},
+		},
+	}
+
+	i, err := NewInfluxDBOutput(nil, outCfg)
+	require.Nil(t, err)
+	require.Nil(t, i.Do(generateTestData()))
+
+	assert.Equal(t, 3, requests)
+	assert.Equal(t, expectedLines, bodies)
+}
+
+func TestInfluxDBHTTPNoRetry(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"code":"invalid","message":"unable to parse"}`))
+	}))
+	defer server.Close()
+
+	outCfg := &config.Output{
+		InfluxDB: &config.InfluxDB{
+			HTTP: &config.InfluxDBHTTP{
+				URL:    server.URL,
+				Org:    "myorg",
+				Bucket: "network",
+			},
+		},
+	}
+
+	i, err := NewInfluxDBOutput(nil, outCfg)
+	require.Nil(t, err)
+
+	err = i.Do(generateTestData())
+	require.NotNil(t, err)
+	assert.Contains(t, err.Error(), "unable to parse")
+	assert.Equal(t, 1, requests)
+}
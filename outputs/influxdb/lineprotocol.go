@@ -0,0 +1,113 @@
+/*
+Copyright 2022 Cloudical Deutschland GmbH. All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package influxdb
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	measurementEscaper = strings.NewReplacer(`,`, `\,`, ` `, `\ `)
+	keyEscaper         = strings.NewReplacer(`,`, `\,`, `=`, `\=`, ` `, `\ `)
+)
+
+// point a single point in the InfluxDB line protocol
+type point struct {
+	measurement string
+	tags        map[string]string
+	fields      map[string]interface{}
+	time        time.Time
+}
+
+// line return the point in the line protocol format, e.g., `measurement,tag=value field=1.5,count=10i 1556813561098000000`
+func (p point) line() string {
+	var b strings.Builder
+	b.WriteString(measurementEscaper.Replace(p.measurement))
+
+	tags := make([]string, 0, len(p.tags))
+	for key, value := range p.tags {
+		// Empty tag values are not allowed in the line protocol
+		if value == "" {
+			continue
+		}
+		tags = append(tags, key)
+	}
+	// Sorted tags are recommended for performance reasons by InfluxDB
+	sort.Strings(tags)
+	for _, key := range tags {
+		fmt.Fprintf(&b, ",%s=%s", keyEscaper.Replace(key), keyEscaper.Replace(p.tags[key]))
+	}
+
+	fields := make([]string, 0, len(p.fields))
+	for key := range p.fields {
+		fields = append(fields, key)
+	}
+	sort.Strings(fields)
+	for i, key := range fields {
+		sep := ","
+		if i == 0 {
+			sep = " "
+		}
+		fmt.Fprintf(&b, "%s%s=%s", sep, keyEscaper.Replace(key), formatFieldValue(p.fields[key]))
+	}
+
+	fmt.Fprintf(&b, " %d", p.time.UnixNano())
+
+	return b.String()
+}
+
+// fieldValue return the value in the type usable as a field value and if it is usable as a field at all
+func fieldValue(in interface{}) (interface{}, bool) {
+	switch v := in.(type) {
+	case float64:
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return nil, false
+		}
+		return v, true
+	case float32:
+		return fieldValue(float64(v))
+	case int:
+		return int64(v), true
+	case int8:
+		return int64(v), true
+	case int16:
+		return int64(v), true
+	case int32:
+		return int64(v), true
+	case int64:
+		return v, true
+	case bool:
+		return v, true
+	}
+
+	return nil, false
+}
+
+func formatFieldValue(in interface{}) string {
+	switch v := in.(type) {
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case int64:
+		return strconv.FormatInt(v, 10) + "i"
+	case bool:
+		return strconv.FormatBool(v)
+	}
+
+	return ""
+}
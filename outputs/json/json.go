@@ -0,0 +1,217 @@
+/*
+Copyright 2022 Cloudical Deutschland GmbH. All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package json
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/cloudical-io/ancientt/outputs"
+	"github.com/cloudical-io/ancientt/pkg/config"
+	"github.com/cloudical-io/ancientt/pkg/util"
+	"github.com/sirupsen/logrus"
+	log "github.com/sirupsen/logrus"
+)
+
+// NameJSON JSON output name
+const NameJSON = "json"
+
+// arrayEnd end of the array in the array format files, the records are written before it on each Do() so the file is
+// always valid JSON
+const arrayEnd = "\n]"
+
+func init() {
+	outputs.Factories[NameJSON] = NewJSONOutput
+}
+
+// JSON JSON output structure
+type JSON struct {
+	outputs.Output
+	logger *log.Entry
+	config *config.JSON
+	files  map[string]*os.File
+}
+
+// Record a row of the data together with the test metadata
+type Record struct {
+	TestStartTime  time.Time              `json:"test_start_time"`
+	TestTime       time.Time              `json:"test_time"`
+	Type           string                 `json:"type,omitempty"`
+	Tester         string                 `json:"tester"`
+	ServerHost     string                 `json:"server_host"`
+	ClientHost     string                 `json:"client_host"`
+	IPFamily       string                 `json:"ip_family,omitempty"`
+	AdditionalInfo string                 `json:"additional_info,omitempty"`
+	Data           map[string]interface{} `json:"data"`
+}
+
+// NewJSONOutput return a new JSON output instance
+func NewJSONOutput(cfg *config.Config, outCfg *config.Output) (outputs.Output, error) {
+	if outCfg == nil || outCfg.JSON == nil {
+		return nil, fmt.Errorf("no json output config given")
+	}
+	j := JSON{
+		logger: log.WithFields(logrus.Fields{"output": NameJSON}),
+		config: outCfg.JSON,
+		files:  map[string]*os.File{},
+	}
+	j.config.SetDefaults()
+	if j.config.FilePath.NamePattern == "" {
+		extension := "json"
+		if j.config.Format == config.JSONFormatNDJSON {
+			extension = "ndjson"
+		}
+		j.config.FilePath.NamePattern = "ancientt-{{ .TestStartTime }}-{{ .Data.Tester }}{{ with .Data.Type }}-{{ . }}{{ end }}." + extension
+	}
+	return j, nil
+}
+
+// Do make JSON outputs
+func (j JSON) Do(data outputs.Data) error {
+	dataTable, ok := data.Data.(*outputs.Table)
+	if !ok {
+		return fmt.Errorf("data not in data table format for json output")
+	}
+
+	records := toRecords(data, dataTable)
+	if len(records) == 0 {
+		return nil
+	}
+
+	filename, err := outputs.GetFilenameFromPattern(j.config.FilePath.NamePattern, "", data, nil)
+	if err != nil {
+		return err
+	}
+
+	outPath := filepath.Join(j.config.FilePath.FilePath, filename)
+	file, exists := j.files[outPath]
+	if !exists {
+		file, err = os.Create(outPath)
+		if err != nil {
+			return err
+		}
+		j.files[outPath] = file
+	}
+
+	var buf bytes.Buffer
+	switch j.config.Format {
+	case config.JSONFormatNDJSON:
+		encoder := json.NewEncoder(&buf)
+		for _, record := range records {
+			if err := encoder.Encode(record); err != nil {
+				return fmt.Errorf("failed to encode json record. %+v", err)
+			}
+		}
+	default:
+		if exists {
+			// Overwrite the end of the array with the next records
+			if _, err := file.Seek(-int64(len(arrayEnd)), io.SeekEnd); err != nil {
+				return fmt.Errorf("failed to seek in json file %s. %+v", outPath, err)
+			}
+			buf.WriteString(",\n")
+		} else {
+			buf.WriteString("[\n")
+		}
+		for i, record := range records {
+			out, err := json.Marshal(record)
+			if err != nil {
+				return fmt.Errorf("failed to encode json record. %+v", err)
+			}
+			if i > 0 {
+				buf.WriteString(",\n")
+			}
+			buf.Write(out)
+		}
+		buf.WriteString(arrayEnd)
+	}
+
+	if _, err := file.Write(buf.Bytes()); err != nil {
+		return fmt.Errorf("failed to write json file %s. %+v", outPath, err)
+	}
+
+	return nil
+}
+
+// toRecords convert the rows of the data table into records
+func toRecords(data outputs.Data, dataTable *outputs.Table) []Record {
+	records := []Record{}
+	for _, row := range dataTable.Rows {
+		values := map[string]interface{}{}
+		for i, r := range row {
+			if r == nil || i >= len(dataTable.Headers) || dataTable.Headers[i] == nil {
+				continue
+			}
+			values[util.CastToString(dataTable.Headers[i].Value)] = jsonValue(r.Value)
+		}
+		if len(values) == 0 {
+			continue
+		}
+
+		records = append(records, Record{
+			TestStartTime:  data.TestStartTime,
+			TestTime:       data.TestTime,
+			Type:           string(data.Type),
+			Tester:         data.Tester,
+			ServerHost:     data.ServerHost,
+			ClientHost:     data.ClientHost,
+			IPFamily:       data.IPFamily,
+			AdditionalInfo: data.AdditionalInfo,
+			Data:           values,
+		})
+	}
+
+	return records
+}
+
+// jsonValue return the value as is, except for NaN and infinity floats which can't be encoded in JSON
+func jsonValue(in interface{}) interface{} {
+	switch v := in.(type) {
+	case float64:
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return nil
+		}
+	case float32:
+		if math.IsNaN(float64(v)) || math.IsInf(float64(v), 0) {
+			return nil
+		}
+	}
+	return in
+}
+
+// OutputFiles return a list of output files
+func (j JSON) OutputFiles() []string {
+	list := []string{}
+	for file := range j.files {
+		list = append(list, file)
+	}
+	return list
+}
+
+// Close close all file descriptors here
+func (j JSON) Close() error {
+	for name, file := range j.files {
+		j.logger.WithFields(logrus.Fields{"filepath": name}).Debug("closing file")
+		if err := file.Close(); err != nil {
+			j.logger.WithFields(logrus.Fields{"filepath": name}).Errorf("error closing file. %+v", err)
+		}
+	}
+
+	return nil
+}
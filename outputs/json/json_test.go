@@ -0,0 +1,103 @@
+/*
+Copyright 2022 Cloudical Deutschland GmbH. All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package json
+
+import (
+	"bufio"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/cloudical-io/ancientt/outputs/tests"
+	"github.com/cloudical-io/ancientt/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJSONArray(t *testing.T) {
+	outCfg := &config.Output{
+		JSON: &config.JSON{
+			FilePath: config.FilePath{
+				FilePath:    t.TempDir(),
+				NamePattern: "ancientt-test.json",
+			},
+		},
+	}
+
+	j, err := NewJSONOutput(nil, outCfg)
+	require.Nil(t, err)
+
+	require.Nil(t, j.Do(tests.GenerateMockTableData(3)))
+	files := j.OutputFiles()
+	require.Equal(t, 1, len(files))
+
+	// The file must be valid JSON after each Do()
+	out, err := ioutil.ReadFile(files[0])
+	require.Nil(t, err)
+	records := []Record{}
+	require.Nil(t, json.Unmarshal(out, &records))
+	assert.Equal(t, 3, len(records))
+
+	require.Nil(t, j.Do(tests.GenerateMockTableData(2)))
+	require.Nil(t, j.Close())
+
+	out, err = ioutil.ReadFile(files[0])
+	require.Nil(t, err)
+	records = []Record{}
+	require.Nil(t, json.Unmarshal(out, &records))
+	require.Equal(t, 5, len(records))
+
+	assert.Equal(t, "foobar", records[0].Tester)
+	assert.Equal(t, "host2", records[0].ServerHost)
+	assert.Equal(t, "host1", records[0].ClientHost)
+	assert.Equal(t, "data", records[0].Data["data"])
+	assert.Equal(t, true, records[0].Data["isittrue"])
+	assert.Equal(t, float64(1), records[4].Data["interval"])
+}
+
+func TestJSONNDJSON(t *testing.T) {
+	outCfg := &config.Output{
+		JSON: &config.JSON{
+			FilePath: config.FilePath{
+				FilePath: t.TempDir(),
+			},
+			Format: config.JSONFormatNDJSON,
+		},
+	}
+
+	j, err := NewJSONOutput(nil, outCfg)
+	require.Nil(t, err)
+	assert.Contains(t, outCfg.JSON.NamePattern, ".ndjson")
+
+	require.Nil(t, j.Do(tests.GenerateMockTableData(3)))
+	require.Nil(t, j.Do(tests.GenerateMockTableData(2)))
+	require.Nil(t, j.Close())
+
+	files := j.OutputFiles()
+	require.Equal(t, 1, len(files))
+	file, err := os.Open(files[0])
+	require.Nil(t, err)
+	defer file.Close()
+
+	lines := 0
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		record := Record{}
+		require.Nil(t, json.Unmarshal(scanner.Bytes(), &record))
+		assert.Equal(t, "foobar", record.Tester)
+		lines++
+	}
+	assert.Equal(t, 5, lines)
+}
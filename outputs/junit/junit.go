@@ -0,0 +1,431 @@
+/*
+Copyright 2022 Cloudical Deutschland GmbH. All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package junit
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cloudical-io/ancientt/outputs"
+	"github.com/cloudical-io/ancientt/pkg/assertions"
+	"github.com/cloudical-io/ancientt/pkg/compare"
+	"github.com/cloudical-io/ancientt/pkg/config"
+	"github.com/cloudical-io/ancientt/pkg/util"
+	"github.com/cloudical-io/ancientt/testers"
+	"github.com/sirupsen/logrus"
+	log "github.com/sirupsen/logrus"
+)
+
+// NameJUnit JUnit output name
+const NameJUnit = "junit"
+
+func init() {
+	outputs.Factories[NameJUnit] = NewJUnitOutput
+}
+
+// JUnit JUnit output structure
+type JUnit struct {
+	outputs.Output
+	logger  *log.Entry
+	config  *config.JUnit
+	info    *outputs.ReportInfo
+	reports map[string]*report
+	order   []string
+}
+
+// report collected data for one JUnit XML file
+type report struct {
+	tester        string
+	testStartTime time.Time
+	// results server / client host pairs per round with results, used when there is no plan
+	results     []caseKey
+	seen        map[caseKey]bool
+	assertions  []outputs.Data
+	regressions []outputs.Data
+}
+
+type caseKey struct {
+	round      int
+	serverHost string
+	clientHost string
+	ipFamily   string
+}
+
+type testSuites struct {
+	XMLName  xml.Name     `xml:"testsuites"`
+	Name     string       `xml:"name,attr"`
+	Tests    int          `xml:"tests,attr"`
+	Failures int          `xml:"failures,attr"`
+	Skipped  int          `xml:"skipped,attr"`
+	Suites   []*testSuite `xml:"testsuite"`
+}
+
+type testSuite struct {
+	Name       string      `xml:"name,attr"`
+	Tests      int         `xml:"tests,attr"`
+	Failures   int         `xml:"failures,attr"`
+	Errors     int         `xml:"errors,attr"`
+	Skipped    int         `xml:"skipped,attr"`
+	Timestamp  string      `xml:"timestamp,attr"`
+	Properties []property  `xml:"properties>property,omitempty"`
+	TestCases  []*testCase `xml:"testcase"`
+}
+
+type property struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value,attr"`
+}
+
+type testCase struct {
+	ClassName string   `xml:"classname,attr"`
+	Name      string   `xml:"name,attr"`
+	Failure   *message `xml:"failure,omitempty"`
+	Skipped   *message `xml:"skipped,omitempty"`
+}
+
+type message struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr,omitempty"`
+	Text    string `xml:",chardata"`
+}
+
+// NewJUnitOutput return a new JUnit output instance
+func NewJUnitOutput(cfg *config.Config, outCfg *config.Output) (outputs.Output, error) {
+	if outCfg == nil || outCfg.JUnit == nil {
+		return nil, fmt.Errorf("no junit output config given")
+	}
+	j := &JUnit{
+		logger:  log.WithFields(logrus.Fields{"output": NameJUnit}),
+		config:  outCfg.JUnit,
+		reports: map[string]*report{},
+	}
+	if j.config.FilePath.NamePattern == "" {
+		j.config.FilePath.NamePattern = "ancientt-{{ .TestStartTime }}-{{ .Data.Tester }}.xml"
+	}
+	return j, nil
+}
+
+// Do collect the results, assertions and regressions, the JUnit XML file is written on Close()
+func (j *JUnit) Do(data outputs.Data) error {
+	dataTable, ok := data.Data.(*outputs.Table)
+	if !ok {
+		return fmt.Errorf("data not in data table format for junit output")
+	}
+
+	outPath, err := j.outPath(data)
+	if err != nil {
+		return err
+	}
+	r := j.getReport(outPath, data.Tester, data.TestStartTime)
+
+	switch data.Type {
+	case outputs.DataTypeResult:
+		roundIndex, err := dataTable.GetHeaderIndexByName("round")
+		if err != nil {
+			return err
+		}
+		for _, row := range dataTable.Rows {
+			key := caseKey{serverHost: data.ServerHost, clientHost: data.ClientHost, ipFamily: data.IPFamily}
+			if roundIndex != -1 && len(row) > roundIndex && row[roundIndex] != nil {
+				if round, ok := row[roundIndex].Value.(int); ok {
+					key.round = round
+				}
+			}
+			if !r.seen[key] {
+				r.seen[key] = true
+				r.results = append(r.results, key)
+			}
+		}
+	case outputs.DataTypeAssertions:
+		r.assertions = append(r.assertions, data)
+	case outputs.DataTypeRegressions:
+		r.regressions = append(r.regressions, data)
+	}
+
+	return nil
+}
+
+func (j *JUnit) outPath(data outputs.Data) (string, error) {
+	filename, err := outputs.GetFilenameFromPattern(j.config.FilePath.NamePattern, "", data, nil)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(j.config.FilePath.FilePath, filename), nil
+}
+
+func (j *JUnit) getReport(outPath string, tester string, testStartTime time.Time) *report {
+	r, ok := j.reports[outPath]
+	if !ok {
+		r = &report{
+			tester:        tester,
+			testStartTime: testStartTime,
+			seen:          map[caseKey]bool{},
+		}
+		j.reports[outPath] = r
+		j.order = append(j.order, outPath)
+	}
+	return r
+}
+
+// SetReportInfo set the information about the test run, the test cases are generated from the plan
+func (j *JUnit) SetReportInfo(info *outputs.ReportInfo) {
+	j.info = info
+}
+
+// OutputFiles return a list of output files
+func (j *JUnit) OutputFiles() []string {
+	return append([]string{}, j.order...)
+}
+
+// Close write the JUnit XML files
+func (j *JUnit) Close() error {
+	// Write the file even when no data has been received, the failed hosts are in the plan
+	if len(j.order) == 0 && j.info != nil && j.info.Plan != nil {
+		outPath, err := j.outPath(outputs.Data{
+			TestStartTime: j.info.Plan.TestStartTime,
+			TestTime:      time.Now(),
+			Tester:        j.info.Plan.Tester,
+		})
+		if err != nil {
+			return err
+		}
+		j.getReport(outPath, j.info.Plan.Tester, j.info.Plan.TestStartTime)
+	}
+
+	for _, outPath := range j.order {
+		j.logger.WithFields(logrus.Fields{"filepath": outPath}).Debug("writing junit xml")
+
+		out, err := j.render(j.reports[outPath])
+		if err != nil {
+			return fmt.Errorf("failed to render junit xml %s. %+v", outPath, err)
+		}
+		if err := util.WriteNewTruncFile(outPath, out); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (j *JUnit) render(r *report) ([]byte, error) {
+	suite := &testSuite{
+		Name:      j.config.SuiteName,
+		Timestamp: r.testStartTime.UTC().Format("2006-01-02T15:04:05"),
+		Properties: []property{
+			{Name: "tester", Value: r.tester},
+		},
+		TestCases: []*testCase{},
+	}
+	if j.info != nil {
+		if suite.Name == "" {
+			suite.Name = j.info.TestName
+		}
+		suite.Properties = append(suite.Properties, property{Name: "runner", Value: j.info.Runner})
+		if j.info.Plan != nil {
+			for _, name := range j.info.Plan.SeedNames() {
+				suite.Properties = append(suite.Properties, property{Name: "seed." + name, Value: strconv.FormatInt(j.info.Plan.Seeds[name], 10)})
+			}
+		}
+	}
+	if suite.Name == "" {
+		suite.Name = r.tester
+	}
+
+	if j.info != nil && j.info.Plan != nil {
+		suite.TestCases = append(suite.TestCases, planTestCases(r.tester, j.info.Plan)...)
+	} else {
+		for _, key := range r.results {
+			suite.TestCases = append(suite.TestCases, &testCase{
+				ClassName: fmt.Sprintf("%s.round%d", r.tester, key.round+1),
+				Name:      pairName(key.serverHost, key.clientHost, key.ipFamily),
+			})
+		}
+	}
+	for _, data := range r.assertions {
+		suite.TestCases = append(suite.TestCases, assertionTestCases(r.tester, data)...)
+	}
+	for _, data := range r.regressions {
+		suite.TestCases = append(suite.TestCases, regressionTestCases(r.tester, data)...)
+	}
+
+	for _, tc := range suite.TestCases {
+		suite.Tests++
+		if tc.Failure != nil {
+			suite.Failures++
+		}
+		if tc.Skipped != nil {
+			suite.Skipped++
+		}
+	}
+
+	suites := &testSuites{
+		Name:     "ancientt",
+		Tests:    suite.Tests,
+		Failures: suite.Failures,
+		Skipped:  suite.Skipped,
+		Suites:   []*testSuite{suite},
+	}
+
+	out, err := xml.MarshalIndent(suites, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+	buf.Write(out)
+	buf.WriteString("\n")
+	return buf.Bytes(), nil
+}
+
+// planTestCases return a test case per server / client host pair and IP family per round of the plan, failures are
+// taken from the status of the tasks. Tasks whose hosts are neither in the successful nor in the failed hosts of the
+// status have not been run (e.g., because an earlier task failed) and are skipped.
+func planTestCases(tester string, plan *testers.Plan) []*testCase {
+	testCases := []*testCase{}
+	for round, tasks := range plan.Commands {
+		for _, task := range tasks {
+			if task.Sleep != 0 || task.Host == nil {
+				continue
+			}
+			status := task.Status
+			if status == nil {
+				status = &testers.Status{}
+			}
+			for _, subTask := range task.SubTasks {
+				tc := &testCase{
+					ClassName: fmt.Sprintf("%s.round%d", tester, round+1),
+					Name:      pairName(task.Host.Name, subTask.Host.Name, string(subTask.IPFamily)),
+				}
+				testCases = append(testCases, tc)
+
+				messages := []string{}
+				errs := []string{}
+				if _, ok := status.FailedHosts.Servers[task.Host.Name]; ok {
+					messages = append(messages, fmt.Sprintf("server %s failed", task.Host.Name))
+					errs = append(errs, errorStrings(status.Errors[task.Host.Name])...)
+				}
+				if _, ok := status.FailedHosts.Clients[subTask.Host.Name]; ok {
+					messages = append(messages, fmt.Sprintf("client %s failed", subTask.Host.Name))
+					errs = append(errs, errorStrings(status.Errors[subTask.Host.Name])...)
+				}
+				if len(messages) > 0 {
+					tc.Failure = &message{
+						Message: strings.Join(messages, ", "),
+						Type:    "error",
+						Text:    strings.Join(errs, "\n"),
+					}
+					continue
+				}
+
+				_, serverRun := status.SuccessfulHosts.Servers[task.Host.Name]
+				_, clientRun := status.SuccessfulHosts.Clients[subTask.Host.Name]
+				if !serverRun || !clientRun {
+					tc.Skipped = &message{Message: "not run"}
+				}
+			}
+		}
+	}
+
+	return testCases
+}
+
+// assertionTestCases return a test case per assertion and server / client host pair
+func assertionTestCases(tester string, data outputs.Data) []*testCase {
+	dataTable := data.Data.(*outputs.Table)
+	get := cellGetter(dataTable)
+
+	testCases := []*testCase{}
+	for _, row := range dataTable.Rows {
+		tc := &testCase{
+			ClassName: fmt.Sprintf("%s.assertions", tester),
+			Name:      fmt.Sprintf("%s: %s", pairName(get(row, "server_host"), get(row, "client_host"), get(row, "ip_family")), get(row, "assertion")),
+		}
+		if get(row, "verdict") != assertions.VerdictPass {
+			tc.Failure = &message{
+				Message: fmt.Sprintf("expected %s %s %s %s, got %s (count %s)", get(row, "column"), get(row, "aggregation"),
+					get(row, "operator"), get(row, "expected"), get(row, "actual"), get(row, "count")),
+				Type: "assertion",
+			}
+		}
+		testCases = append(testCases, tc)
+	}
+
+	return testCases
+}
+
+// regressionTestCases return a test case per compared metric and server / client host pair
+func regressionTestCases(tester string, data outputs.Data) []*testCase {
+	dataTable := data.Data.(*outputs.Table)
+	get := cellGetter(dataTable)
+
+	testCases := []*testCase{}
+	for _, row := range dataTable.Rows {
+		tc := &testCase{
+			ClassName: fmt.Sprintf("%s.regressions", tester),
+			Name: fmt.Sprintf("%s: %s %s", pairName(get(row, "server_host"), get(row, "client_host"), get(row, "ip_family")),
+				get(row, "column"), get(row, "aggregation")),
+		}
+		switch get(row, "status") {
+		case compare.StatusRegression:
+			tc.Failure = &message{
+				Message: fmt.Sprintf("changed by %s%% from baseline %s to %s, allowed are %s%%", get(row, "change_percent"),
+					get(row, "baseline"), get(row, "current"), get(row, "max_change_percent")),
+				Type: "regression",
+			}
+		case compare.StatusMissing:
+			tc.Failure = &message{
+				Message: fmt.Sprintf("no values in current run, baseline %s", get(row, "baseline")),
+				Type:    "regression",
+			}
+		case compare.StatusNoBaseline:
+			tc.Skipped = &message{Message: "no baseline"}
+		}
+		testCases = append(testCases, tc)
+	}
+
+	return testCases
+}
+
+// cellGetter return a func to get the value of a column of a row as a string, an empty string is returned when the
+// column doesn't exist
+func cellGetter(dataTable *outputs.Table) func(row []*outputs.Row, column string) string {
+	return func(row []*outputs.Row, column string) string {
+		index, err := dataTable.GetHeaderIndexByName(column)
+		if err != nil || index == -1 || len(row) <= index || row[index] == nil {
+			return ""
+		}
+		return util.CastToString(row[index].Value)
+	}
+}
+
+func pairName(serverHost string, clientHost string, ipFamily string) string {
+	if ipFamily == "" {
+		return fmt.Sprintf("%s -> %s", clientHost, serverHost)
+	}
+	return fmt.Sprintf("%s -> %s (%s)", clientHost, serverHost, ipFamily)
+}
+
+func errorStrings(errs []error) []string {
+	out := []string{}
+	for _, err := range errs {
+		out = append(out, err.Error())
+	}
+	return out
+}
@@ -0,0 +1,226 @@
+/*
+Copyright 2022 Cloudical Deutschland GmbH. All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package junit
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/cloudical-io/ancientt/outputs"
+	"github.com/cloudical-io/ancientt/pkg/config"
+	"github.com/cloudical-io/ancientt/testers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testStartTime = time.Date(2022, 3, 1, 10, 0, 0, 0, time.UTC)
+
+func newStatus() *testers.Status {
+	return &testers.Status{
+		SuccessfulHosts: testers.StatusHosts{Servers: map[string]int{}, Clients: map[string]int{}},
+		FailedHosts:     testers.StatusHosts{Servers: map[string]int{}, Clients: map[string]int{}},
+		Errors:          map[string][]error{},
+	}
+}
+
+func TestJUnit(t *testing.T) {
+	tempDir := t.TempDir()
+	outCfg := &config.Output{
+		JUnit: &config.JUnit{
+			FilePath: config.FilePath{
+				FilePath: tempDir,
+			},
+		},
+	}
+
+	j, err := NewJUnitOutput(nil, outCfg)
+	require.Nil(t, err)
+
+	server := &testers.Host{Name: "server1"}
+	client1 := &testers.Host{Name: "client1"}
+	client2 := &testers.Host{Name: "client2"}
+
+	status := newStatus()
+	status.AddSuccessfulServer(server)
+	status.AddSuccessfulClient(client1)
+	status.AddFailedClient(client2, fmt.Errorf("connection refused"))
+
+	require.Nil(t, j.Do(outputs.Data{
+		TestStartTime: testStartTime,
+		Type:          outputs.DataTypeAssertions,
+		Tester:        "iperf3",
+		Data: &outputs.Table{
+			Headers: []*outputs.Row{
+				{Value: "server_host"}, {Value: "client_host"}, {Value: "ip_family"}, {Value: "assertion"}, {Value: "column"},
+				{Value: "aggregation"}, {Value: "operator"}, {Value: "expected"}, {Value: "count"},
+				{Value: "actual"}, {Value: "verdict"},
+			},
+			Rows: [][]*outputs.Row{
+				{
+					{Value: "server1"}, {Value: "client1"}, {Value: "v4"}, {Value: "retransmits max == 0"}, {Value: "retransmits"},
+					{Value: "max"}, {Value: "=="}, {Value: "0"}, {Value: 10}, {Value: "0"}, {Value: "pass"},
+				},
+				{
+					{Value: "server1"}, {Value: "client1"}, {Value: "v6"}, {Value: "bits_per_second p95 >= 9e9"}, {Value: "bits_per_second"},
+					{Value: "p95"}, {Value: ">="}, {Value: "9e9"}, {Value: 10}, {Value: "1e9"}, {Value: "fail"},
+				},
+			},
+		},
+	}))
+	require.Nil(t, j.Do(outputs.Data{
+		TestStartTime: testStartTime,
+		Type:          outputs.DataTypeRegressions,
+		Tester:        "iperf3",
+		Data: &outputs.Table{
+			Headers: []*outputs.Row{
+				{Value: "server_host"}, {Value: "client_host"}, {Value: "column"}, {Value: "aggregation"},
+				{Value: "baseline"}, {Value: "current"}, {Value: "change_percent"}, {Value: "max_change_percent"},
+				{Value: "status"},
+			},
+			Rows: [][]*outputs.Row{
+				{
+					{Value: "server1"}, {Value: "client1"}, {Value: "bits_per_second"}, {Value: "avg"},
+					{Value: "2e9"}, {Value: "1e9"}, {Value: "-50"}, {Value: "10"}, {Value: "regression"},
+				},
+				{
+					{Value: "server1"}, {Value: "client2"}, {Value: "bits_per_second"}, {Value: "avg"},
+					{Value: ""}, {Value: "1e9"}, {Value: ""}, {Value: "10"}, {Value: "no_baseline"},
+				},
+			},
+		},
+	}))
+
+	j.(outputs.Reporter).SetReportInfo(&outputs.ReportInfo{
+		TestName: "iperf3-test",
+		Runner:   "mock",
+		Plan: &testers.Plan{
+			TestStartTime: testStartTime,
+			Tester:        "iperf3",
+			Seeds:         map[string]int64{"servers/random": 42},
+			Commands: [][]*testers.Task{
+				{
+					{
+						Host: server,
+						SubTasks: []*testers.Task{
+							{Host: client1, IPFamily: config.IPFamilyV4},
+							{Host: client1, IPFamily: config.IPFamilyV6},
+							{Host: client2, IPFamily: config.IPFamilyV4},
+						},
+						Status: status,
+					},
+					{Sleep: 10 * time.Second},
+				},
+				// Not run as the run has been aborted after the first round
+				{
+					{
+						Host:     server,
+						SubTasks: []*testers.Task{{Host: client1, IPFamily: config.IPFamilyV4}},
+						Status:   newStatus(),
+					},
+				},
+			},
+		},
+	})
+	require.Nil(t, j.Close())
+
+	outPath := filepath.Join(tempDir, "ancientt-1646128800-iperf3.xml")
+	assert.Equal(t, []string{outPath}, j.OutputFiles())
+	out, err := ioutil.ReadFile(outPath)
+	require.Nil(t, err)
+	assert.Equal(t, `<?xml version="1.0" encoding="UTF-8"?>
+<testsuites name="ancientt" tests="8" failures="3" skipped="2">
+  <testsuite name="iperf3-test" tests="8" failures="3" errors="0" skipped="2" timestamp="2022-03-01T10:00:00">
+    <properties>
+      <property name="tester" value="iperf3"></property>
+      <property name="runner" value="mock"></property>
+      <property name="seed.servers/random" value="42"></property>
+    </properties>
+    <testcase classname="iperf3.round1" name="client1 -&gt; server1 (v4)"></testcase>
+    <testcase classname="iperf3.round1" name="client1 -&gt; server1 (v6)"></testcase>
+    <testcase classname="iperf3.round1" name="client2 -&gt; server1 (v4)">
+      <failure message="client client2 failed" type="error">connection refused</failure>
+    </testcase>
+    <testcase classname="iperf3.round2" name="client1 -&gt; server1 (v4)">
+      <skipped message="not run"></skipped>
+    </testcase>
+    <testcase classname="iperf3.assertions" name="client1 -&gt; server1 (v4): retransmits max == 0"></testcase>
+    <testcase classname="iperf3.assertions" name="client1 -&gt; server1 (v6): bits_per_second p95 &gt;= 9e9">
+      <failure message="expected bits_per_second p95 &gt;= 9e9, got 1e9 (count 10)" type="assertion"></failure>
+    </testcase>
+    <testcase classname="iperf3.regressions" name="client1 -&gt; server1: bits_per_second avg">
+      <failure message="changed by -50% from baseline 2e9 to 1e9, allowed are 10%" type="regression"></failure>
+    </testcase>
+    <testcase classname="iperf3.regressions" name="client2 -&gt; server1: bits_per_second avg">
+      <skipped message="no baseline"></skipped>
+    </testcase>
+  </testsuite>
+</testsuites>
+`, string(out))
+}
+
+func TestJUnitWithoutPlan(t *testing.T) {
+	tempDir := t.TempDir()
+	outCfg := &config.Output{
+		JUnit: &config.JUnit{
+			FilePath: config.FilePath{
+				FilePath:    tempDir,
+				NamePattern: "junit.xml",
+			},
+			SuiteName: "network",
+		},
+	}
+
+	j, err := NewJUnitOutput(nil, outCfg)
+	require.Nil(t, err)
+
+	for round := 0; round < 2; round++ {
+		require.Nil(t, j.Do(outputs.Data{
+			TestStartTime: testStartTime,
+			Tester:        "iperf3",
+			ServerHost:    "server1",
+			ClientHost:    "client1",
+			IPFamily:      "v4",
+			Data: &outputs.Table{
+				Headers: []*outputs.Row{{Value: "round"}, {Value: "bits_per_second"}},
+				Rows: [][]*outputs.Row{
+					{{Value: round}, {Value: 1e9}},
+					{{Value: round}, {Value: 2e9}},
+				},
+			},
+		}))
+	}
+	// The results of the other IP family of the pair are a test case of their own
+	require.Nil(t, j.Do(outputs.Data{
+		TestStartTime: testStartTime,
+		Tester:        "iperf3",
+		ServerHost:    "server1",
+		ClientHost:    "client1",
+		IPFamily:      "v6",
+		Data: &outputs.Table{
+			Headers: []*outputs.Row{{Value: "round"}, {Value: "bits_per_second"}},
+			Rows:    [][]*outputs.Row{{{Value: 0}, {Value: 1e9}}},
+		},
+	}))
+	require.Nil(t, j.Close())
+
+	out, err := ioutil.ReadFile(filepath.Join(tempDir, "junit.xml"))
+	require.Nil(t, err)
+	assert.Contains(t, string(out), `<testsuite name="network" tests="3" failures="0" errors="0" skipped="0" timestamp="2022-03-01T10:00:00">`)
+	assert.Contains(t, string(out), `<testcase classname="iperf3.round1" name="client1 -&gt; server1 (v4)"></testcase>`)
+	assert.Contains(t, string(out), `<testcase classname="iperf3.round2" name="client1 -&gt; server1 (v4)"></testcase>`)
+	assert.Contains(t, string(out), `<testcase classname="iperf3.round1" name="client1 -&gt; server1 (v6)"></testcase>`)
+}
@@ -0,0 +1,331 @@
+/*
+Copyright 2022 Cloudical Deutschland GmbH. All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package markdown
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/cloudical-io/ancientt/outputs"
+	"github.com/cloudical-io/ancientt/pkg/config"
+	"github.com/cloudical-io/ancientt/pkg/stats"
+	"github.com/cloudical-io/ancientt/pkg/util"
+	"github.com/sirupsen/logrus"
+	log "github.com/sirupsen/logrus"
+)
+
+// NameMarkdown Markdown output name
+const NameMarkdown = "markdown"
+
+// unitDivisors divisors for the units the values can be converted to automatically
+var unitDivisors = map[string]float64{
+	"bit/s":  1,
+	"Kbit/s": 1e3,
+	"Mbit/s": 1e6,
+	"Gbit/s": 1e9,
+	"Tbit/s": 1e12,
+	"B":      1,
+	"KB":     1e3,
+	"MB":     1e6,
+	"GB":     1e9,
+	"TB":     1e12,
+	"KiB":    1 << 10,
+	"MiB":    1 << 20,
+	"GiB":    1 << 30,
+	"TiB":    1 << 40,
+}
+
+func init() {
+	outputs.Factories[NameMarkdown] = NewMarkdownOutput
+}
+
+// Markdown Markdown output structure
+type Markdown struct {
+	outputs.Output
+	logger    *log.Entry
+	config    *config.Markdown
+	info      *outputs.ReportInfo
+	summaries map[string]*summary
+	order     []string
+}
+
+// summary collected values for one Markdown file
+type summary struct {
+	tester        string
+	testStartTime time.Time
+	collector     *stats.Collector
+}
+
+// NewMarkdownOutput return a new Markdown output instance
+func NewMarkdownOutput(cfg *config.Config, outCfg *config.Output) (outputs.Output, error) {
+	if outCfg == nil || outCfg.Markdown == nil {
+		return nil, fmt.Errorf("no markdown output config given")
+	}
+	m := &Markdown{
+		logger:    log.WithFields(logrus.Fields{"output": NameMarkdown}),
+		config:    outCfg.Markdown,
+		summaries: map[string]*summary{},
+	}
+	m.config.SetDefaults()
+	if m.config.FilePath.NamePattern == "" {
+		m.config.FilePath.NamePattern = "ancientt-{{ .TestStartTime }}-{{ .Data.Tester }}.md"
+	}
+	return m, nil
+}
+
+// Do collect the values of the columns, the Markdown file is written on Close()
+func (m *Markdown) Do(data outputs.Data) error {
+	if _, ok := data.Data.(*outputs.Table); !ok {
+		return fmt.Errorf("data not in data table format for markdown output")
+	}
+
+	outPath, err := m.outPath(data)
+	if err != nil {
+		return err
+	}
+
+	m.getSummary(outPath, data.Tester, data.TestStartTime).collector.Add(data)
+
+	return nil
+}
+
+func (m *Markdown) outPath(data outputs.Data) (string, error) {
+	filename, err := outputs.GetFilenameFromPattern(m.config.FilePath.NamePattern, "", data, nil)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(m.config.FilePath.FilePath, filename), nil
+}
+
+func (m *Markdown) getSummary(outPath string, tester string, testStartTime time.Time) *summary {
+	s, ok := m.summaries[outPath]
+	if !ok {
+		// A column can be shown multiple times (e.g., in different units), but its values must only be collected once
+		columns := []string{}
+		seen := map[string]bool{}
+		for _, column := range m.config.Columns {
+			if !seen[column.Name] {
+				seen[column.Name] = true
+				columns = append(columns, column.Name)
+			}
+		}
+		s = &summary{
+			tester:        tester,
+			testStartTime: testStartTime,
+			collector:     stats.NewCollector(columns...),
+		}
+		m.summaries[outPath] = s
+		m.order = append(m.order, outPath)
+	}
+	return s
+}
+
+// SetReportInfo set the information about the test run for the summary
+func (m *Markdown) SetReportInfo(info *outputs.ReportInfo) {
+	m.info = info
+}
+
+// OutputFiles return a list of output files
+func (m *Markdown) OutputFiles() []string {
+	return append([]string{}, m.order...)
+}
+
+// Close write the Markdown files
+func (m *Markdown) Close() error {
+	// Write a summary even when no data has been received, so it is visible that there are no results
+	if len(m.order) == 0 && m.info != nil && m.info.Plan != nil {
+		outPath, err := m.outPath(outputs.Data{
+			TestStartTime: m.info.Plan.TestStartTime,
+			TestTime:      time.Now(),
+			Tester:        m.info.Plan.Tester,
+		})
+		if err != nil {
+			return err
+		}
+		m.getSummary(outPath, m.info.Plan.Tester, m.info.Plan.TestStartTime)
+	}
+
+	for _, outPath := range m.order {
+		m.logger.WithFields(logrus.Fields{"filepath": outPath}).Debug("writing markdown summary")
+
+		if err := util.WriteNewTruncFile(outPath, m.render(m.summaries[outPath])); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (m *Markdown) render(s *summary) []byte {
+	var out bytes.Buffer
+
+	fmt.Fprintf(&out, "# %s\n\n", m.config.Title)
+	if m.info != nil {
+		fmt.Fprintf(&out, "* **Test:** %s\n", m.info.TestName)
+		fmt.Fprintf(&out, "* **Runner:** %s\n", m.info.Runner)
+		if m.info.Plan != nil {
+			for _, name := range m.info.Plan.SeedNames() {
+				fmt.Fprintf(&out, "* **Seed %s:** %d\n", name, m.info.Plan.Seeds[name])
+			}
+		}
+	}
+	fmt.Fprintf(&out, "* **Tester:** %s\n", s.tester)
+	fmt.Fprintf(&out, "* **Test start time:** %s\n", s.testStartTime.Format(util.TimeDateFormat))
+
+	pairs := append([]stats.Pair{}, s.collector.Pairs()...)
+	sort.Slice(pairs, func(i, j int) bool {
+		if pairs[i].ServerHost != pairs[j].ServerHost {
+			return pairs[i].ServerHost < pairs[j].ServerHost
+		}
+		if pairs[i].ClientHost != pairs[j].ClientHost {
+			return pairs[i].ClientHost < pairs[j].ClientHost
+		}
+		return pairs[i].IPFamily < pairs[j].IPFamily
+	})
+
+	// Test aggregates over the values of all server / client host pairs
+	fmt.Fprint(&out, "\n## Test\n\n")
+	rows := [][]string{}
+	for _, column := range m.config.Columns {
+		values := []float64{}
+		for _, pair := range pairs {
+			values = append(values, s.collector.Values(pair, column.Name)...)
+		}
+		if len(values) == 0 {
+			continue
+		}
+		rows = append(rows, append([]string{columnTitle(column)}, m.aggregations(column, values)...))
+	}
+	m.writeTable(&out, []string{"Column"}, rows)
+
+	fmt.Fprint(&out, "\n## Server / Client Host Pairs\n\n")
+	rows = [][]string{}
+	for _, pair := range pairs {
+		for _, column := range m.config.Columns {
+			values := s.collector.Values(pair, column.Name)
+			if len(values) == 0 {
+				continue
+			}
+			row := []string{pair.ServerHost, pair.ClientHost, pair.IPFamily, columnTitle(column)}
+			rows = append(rows, append(row, m.aggregations(column, values)...))
+		}
+	}
+	m.writeTable(&out, []string{"Server", "Client", "IP Family", "Column"}, rows)
+
+	if *m.config.OutputFiles && m.info != nil {
+		m.writeOutputFiles(&out)
+	}
+
+	return out.Bytes()
+}
+
+// aggregations return the formatted aggregations of the values
+func (m *Markdown) aggregations(column *config.MarkdownColumn, values []float64) []string {
+	sum := stats.Summarize(values)
+	cells := []string{}
+	for _, aggregation := range m.config.Aggregations {
+		value, ok := sum.Get(aggregation)
+		if !ok {
+			cells = append(cells, "")
+			continue
+		}
+		// The count is not a value of the column, so it is not converted
+		if aggregation == "count" {
+			cells = append(cells, fmt.Sprintf("%d", sum.Count))
+			continue
+		}
+		cells = append(cells, formatValue(column, value))
+	}
+	return cells
+}
+
+// writeTable write a GitHub-flavoured Markdown table with the given leading headers followed by the aggregations
+func (m *Markdown) writeTable(out *bytes.Buffer, headers []string, rows [][]string) {
+	if len(rows) == 0 {
+		fmt.Fprint(out, "No results.\n")
+		return
+	}
+
+	separators := []string{}
+	for range headers {
+		separators = append(separators, "---")
+	}
+	for range m.config.Aggregations {
+		separators = append(separators, "---:")
+	}
+
+	writeRow(out, append(append([]string{}, headers...), m.config.Aggregations...))
+	writeRow(out, separators)
+	for _, row := range rows {
+		writeRow(out, row)
+	}
+}
+
+func (m *Markdown) writeOutputFiles(out *bytes.Buffer) {
+	names := []string{}
+	for name, files := range m.info.OutputFiles {
+		if name == NameMarkdown || len(files) == 0 {
+			continue
+		}
+		names = append(names, name)
+	}
+	if len(names) == 0 {
+		return
+	}
+	sort.Strings(names)
+
+	fmt.Fprint(out, "\n## Output Files\n\n")
+	for _, name := range names {
+		for _, file := range m.info.OutputFiles[name] {
+			fmt.Fprintf(out, "* `%s` (output: %s)\n", file, name)
+		}
+	}
+}
+
+func writeRow(out *bytes.Buffer, cells []string) {
+	escaped := make([]string, len(cells))
+	for i, cell := range cells {
+		escaped[i] = strings.ReplaceAll(cell, "|", `\|`)
+	}
+	fmt.Fprintf(out, "| %s |\n", strings.Join(escaped, " | "))
+}
+
+func columnTitle(column *config.MarkdownColumn) string {
+	title := column.Title
+	if title == "" {
+		title = column.Name
+	}
+	if column.Unit != "" {
+		title = fmt.Sprintf("%s (%s)", title, column.Unit)
+	}
+	return title
+}
+
+// formatValue convert the value to the unit of the column and format it with the column's precision
+func formatValue(column *config.MarkdownColumn, value float64) string {
+	divisor := column.Divisor
+	if divisor == 0 {
+		if d, ok := unitDivisors[column.Unit]; ok {
+			divisor = d
+		} else {
+			divisor = 1
+		}
+	}
+
+	return fmt.Sprintf("%.*f", *column.Precision, value/divisor)
+}
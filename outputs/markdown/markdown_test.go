@@ -0,0 +1,174 @@
+/*
+Copyright 2022 Cloudical Deutschland GmbH. All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package markdown
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/cloudical-io/ancientt/outputs"
+	"github.com/cloudical-io/ancientt/pkg/config"
+	"github.com/cloudical-io/ancientt/testers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testStartTime = time.Date(2022, 3, 1, 10, 0, 0, 0, time.UTC)
+
+func generateData(server string, client string, throughput []float64, retransmits []int) outputs.Data {
+	table := &outputs.Table{
+		Headers: []*outputs.Row{
+			{Value: "bits_per_second"},
+			{Value: "retransmits"},
+		},
+	}
+	for i := range throughput {
+		table.Rows = append(table.Rows, []*outputs.Row{
+			{Value: throughput[i]},
+			{Value: retransmits[i]},
+		})
+	}
+
+	return outputs.Data{
+		TestStartTime: testStartTime,
+		TestTime:      testStartTime,
+		Tester:        "iperf3",
+		ServerHost:    server,
+		ClientHost:    client,
+		IPFamily:      "v4",
+		Data:          table,
+	}
+}
+
+func TestMarkdown(t *testing.T) {
+	tempDir := t.TempDir()
+	outCfg := &config.Output{
+		Markdown: &config.Markdown{
+			FilePath: config.FilePath{
+				FilePath: tempDir,
+			},
+		},
+	}
+
+	m, err := NewMarkdownOutput(nil, outCfg)
+	require.Nil(t, err)
+
+	require.Nil(t, m.Do(generateData("server2", "client1", []float64{4e9, 4e9}, []int{0, 0})))
+	require.Nil(t, m.Do(generateData("server1", "client1", []float64{1e9, 2e9, 3e9}, []int{0, 1, 5})))
+
+	m.(outputs.Reporter).SetReportInfo(&outputs.ReportInfo{
+		TestName: "iperf3-test",
+		Runner:   "mock",
+		Plan:     &testers.Plan{TestStartTime: testStartTime, Tester: "iperf3", Seeds: map[string]int64{"servers/random": 42}},
+		OutputFiles: map[string][]string{
+			"csv":      {"/tmp/ancientt-1646128800-iperf3.csv"},
+			"markdown": m.OutputFiles(),
+			"mysql":    {},
+		},
+	})
+	require.Nil(t, m.Close())
+
+	outPath := filepath.Join(tempDir, "ancientt-1646128800-iperf3.md")
+	assert.Equal(t, []string{outPath}, m.OutputFiles())
+	out, err := ioutil.ReadFile(outPath)
+	require.Nil(t, err)
+	assert.Equal(t, `# ancientt summary
+
+* **Test:** iperf3-test
+* **Runner:** mock
+* **Seed servers/random:** 42
+* **Tester:** iperf3
+* **Test start time:** 2022-03-01T10:00:00+0000
+
+## Test
+
+| Column | min | avg | max | p95 |
+| --- | ---: | ---: | ---: | ---: |
+| Throughput (Gbit/s) | 1.00 | 2.80 | 4.00 | 4.00 |
+| Retransmits | 0 | 1 | 5 | 4 |
+
+## Server / Client Host Pairs
+
+| Server | Client | IP Family | Column | min | avg | max | p95 |
+| --- | --- | --- | --- | ---: | ---: | ---: | ---: |
+| server1 | client1 | v4 | Throughput (Gbit/s) | 1.00 | 2.00 | 3.00 | 2.90 |
+| server1 | client1 | v4 | Retransmits | 0 | 2 | 5 | 5 |
+| server2 | client1 | v4 | Throughput (Gbit/s) | 4.00 | 4.00 | 4.00 | 4.00 |
+| server2 | client1 | v4 | Retransmits | 0 | 0 | 0 | 0 |
+
+## Output Files
+
+* `+"`/tmp/ancientt-1646128800-iperf3.csv`"+` (output: csv)
+`, string(out))
+}
+
+func TestMarkdownColumnFormatting(t *testing.T) {
+	tempDir := t.TempDir()
+	outCfg := &config.Output{
+		Markdown: &config.Markdown{
+			FilePath: config.FilePath{
+				FilePath:    tempDir,
+				NamePattern: "summary.md",
+			},
+			Title: "Throughput",
+			Columns: []*config.MarkdownColumn{
+				{Name: "bits_per_second", Unit: "Mbit/s"},
+				{Name: "bits_per_second", Title: "Custom", Unit: "x", Divisor: 4e9},
+				{Name: "does_not_exist"},
+			},
+			Aggregations: []string{"count", "p50"},
+			OutputFiles:  new(bool),
+		},
+	}
+
+	m, err := NewMarkdownOutput(nil, outCfg)
+	require.Nil(t, err)
+	require.Nil(t, m.Do(generateData("server|1", "client1", []float64{1e9, 2e9}, []int{0, 0})))
+	require.Nil(t, m.Close())
+
+	out, err := ioutil.ReadFile(filepath.Join(tempDir, "summary.md"))
+	require.Nil(t, err)
+	assert.Contains(t, string(out), `| Column | count | p50 |
+| --- | ---: | ---: |
+| bits_per_second (Mbit/s) | 2 | 1500.00 |
+| Custom (x) | 2 | 0.38 |
+`)
+	assert.Contains(t, string(out), `| server\|1 | client1 | v4 | bits_per_second (Mbit/s) | 2 | 1500.00 |`)
+	assert.NotContains(t, string(out), "Output Files")
+}
+
+func TestMarkdownWithoutData(t *testing.T) {
+	tempDir := t.TempDir()
+	outCfg := &config.Output{
+		Markdown: &config.Markdown{
+			FilePath: config.FilePath{
+				FilePath: tempDir,
+			},
+		},
+	}
+
+	m, err := NewMarkdownOutput(nil, outCfg)
+	require.Nil(t, err)
+
+	m.(outputs.Reporter).SetReportInfo(&outputs.ReportInfo{
+		Plan: &testers.Plan{TestStartTime: testStartTime, Tester: "iperf3"},
+	})
+	require.Nil(t, m.Close())
+
+	out, err := ioutil.ReadFile(filepath.Join(tempDir, "ancientt-1646128800-iperf3.md"))
+	require.Nil(t, err)
+	assert.Contains(t, string(out), "## Test\n\nNo results.\n")
+}
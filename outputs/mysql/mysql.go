@@ -49,7 +49,7 @@ type MySQL struct {
 }
 
 const (
-	defaultTableNamePattern = "ancientt{{ .TestStartTime }}{{ .Data.Tester }}{{ .Data.ServerHost }}{{ .Data.ClientHost }}"
+	defaultTableNamePattern = "ancientt{{ .TestStartTime }}{{ .Data.Tester }}{{ .Data.ServerHost }}{{ .Data.ClientHost }}{{ .Data.Type }}"
 
 	checkIfTableExistsQuery = "SELECT 1 FROM `%s` LIMIT 1;"
 	createTableBeginQuery   = "CREATE TABLE IF NOT EXISTS `%s` (\n"
@@ -87,6 +87,11 @@ func (m MySQL) Do(data outputs.Data) error {
 		return fmt.Errorf("data not in data table format for mysql output")
 	}
 
+	if !outputs.SeparatesDataTypes(data, m.config.TableNamePattern) {
+		m.logger.WithFields(logrus.Fields{"type": data.Type}).Debug("skipping non result data, the name pattern doesn't contain the data type")
+		return nil
+	}
+
 	tableName, err := outputs.GetFilenameFromPattern(m.config.TableNamePattern, "", data, nil)
 	if err != nil {
 		return err
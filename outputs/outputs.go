@@ -18,6 +18,7 @@ import (
 	"html/template"
 
 	"github.com/cloudical-io/ancientt/pkg/config"
+	"github.com/cloudical-io/ancientt/testers"
 )
 
 // Factories contains the list of all available outputs.
@@ -34,6 +35,23 @@ type Output interface {
 	Close() error
 }
 
+// Reporter can be implemented by outputs which need information about the test run, e.g., to generate a report. It is
+// called after the test has been run, before Close().
+type Reporter interface {
+	// SetReportInfo set the information about the test run
+	SetReportInfo(info *ReportInfo)
+}
+
+// ReportInfo information about a test run
+type ReportInfo struct {
+	TestName string
+	Runner   string
+	Hosts    *testers.Hosts
+	Plan     *testers.Plan
+	// OutputFiles files of the outputs of the test by output name
+	OutputFiles map[string][]string
+}
+
 // GetFilenameFromPattern get filename from given pattern, data and extra data for templating.
 func GetFilenameFromPattern(pattern string, role string, data Data, extra map[string]interface{}) (string, error) {
 	t, err := template.New("main").Parse(pattern)
@@ -61,3 +79,28 @@ func GetFilenameFromPattern(pattern string, role string, data Data, extra map[st
 	}
 	return out.String(), nil
 }
+
+// SeparatesDataTypes return true when the data is a tester result or the name patterns name the data differently than
+// a tester result (e.g., by using `{{ .Data.Type }}`). Table outputs skip the other data types otherwise, as their rows
+// would be mixed into the tester results.
+func SeparatesDataTypes(data Data, patterns ...string) bool {
+	if data.Type == DataTypeResult {
+		return true
+	}
+
+	result := data
+	result.Type = DataTypeResult
+	for _, pattern := range patterns {
+		name, err := GetFilenameFromPattern(pattern, "", data, nil)
+		if err != nil {
+			// The error is returned when the output uses the pattern
+			return true
+		}
+		resultName, err := GetFilenameFromPattern(pattern, "", result, nil)
+		if err != nil || name != resultName {
+			return true
+		}
+	}
+
+	return false
+}
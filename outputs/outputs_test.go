@@ -0,0 +1,31 @@
+/*
+Copyright 2022 Cloudical Deutschland GmbH. All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package outputs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSeparatesDataTypes(t *testing.T) {
+	result := Data{Tester: "iperf3"}
+	end := Data{Tester: "iperf3", Type: DataTypeEnd}
+
+	assert.True(t, SeparatesDataTypes(result, "ancientt-{{ .Data.Tester }}.csv"))
+	assert.False(t, SeparatesDataTypes(end, "ancientt-{{ .Data.Tester }}.csv"))
+	assert.True(t, SeparatesDataTypes(end, "ancientt-{{ .Data.Tester }}{{ with .Data.Type }}-{{ . }}{{ end }}.csv"))
+	// One of the patterns containing the data type is enough, e.g., the table name of the SQLite output
+	assert.True(t, SeparatesDataTypes(end, "ancientt-{{ .Data.Tester }}.sqlite3", "ancientt{{ .Data.Type }}"))
+}
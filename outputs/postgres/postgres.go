@@ -0,0 +1,325 @@
+/*
+Copyright 2022 Cloudical Deutschland GmbH. All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package postgres
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/cloudical-io/ancientt/pkg/config"
+	"github.com/cloudical-io/ancientt/pkg/util"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+
+	"github.com/cloudical-io/ancientt/outputs"
+	"github.com/sirupsen/logrus"
+	log "github.com/sirupsen/logrus"
+)
+
+// NamePostgres Postgres output name
+const (
+	NamePostgres          = "postgres"
+	PostgresIntType       = "BIGINT"
+	PostgresFloatType     = "DOUBLE PRECISION"
+	PostgresBoolType      = "BOOLEAN"
+	PostgresTimestampType = "TIMESTAMPTZ"
+	PostgresTextType      = "TEXT"
+)
+
+func init() {
+	outputs.Factories[NamePostgres] = NewPostgresOutput
+}
+
+// Postgres Postgres output structure
+type Postgres struct {
+	outputs.Output
+	logger *log.Entry
+	config *config.Postgres
+	dbCons map[string]*sqlx.DB
+	tables map[string]bool
+}
+
+const (
+	defaultTableNamePattern = "ancientt_{{ .Data.Tester }}{{ with .Data.Type }}_{{ . }}{{ end }}"
+
+	checkIfTableExistsQuery    = "SELECT 1 FROM %s LIMIT 1;"
+	createTableBeginQuery      = "CREATE TABLE IF NOT EXISTS %s (\n"
+	createTableEndQuery        = `);`
+	createHypertableQuery      = "SELECT create_hypertable($1, $2, if_not_exists => TRUE);"
+	createHypertableChunkQuery = "SELECT create_hypertable($1, $2, if_not_exists => TRUE, chunk_time_interval => $3::interval);"
+	insertDataQuery            = "INSERT INTO %s (%s) VALUES (%s);"
+)
+
+// NewPostgresOutput return a new Postgres output instance
+func NewPostgresOutput(cfg *config.Config, outCfg *config.Output) (outputs.Output, error) {
+	if outCfg == nil || outCfg.Postgres == nil {
+		return nil, fmt.Errorf("no postgres output config given")
+	}
+	p := Postgres{
+		logger: log.WithFields(logrus.Fields{"output": NamePostgres}),
+		config: outCfg.Postgres,
+		dbCons: map[string]*sqlx.DB{},
+		tables: map[string]bool{},
+	}
+	if p.config.DSN == "" {
+		return nil, fmt.Errorf("no DSN for postgres connection given")
+	}
+	p.config.SetDefaults()
+	p.config.TimescaleDB.SetDefaults()
+	if p.config.TableNamePattern == "" {
+		p.config.TableNamePattern = defaultTableNamePattern
+	}
+
+	return p, nil
+}
+
+// Do make Postgres outputs
+func (p Postgres) Do(data outputs.Data) error {
+	dataTable, ok := data.Data.(*outputs.Table)
+	if !ok {
+		return fmt.Errorf("data not in data table format for postgres output")
+	}
+
+	if !outputs.SeparatesDataTypes(data, p.config.TableNamePattern) {
+		p.logger.WithFields(logrus.Fields{"type": data.Type}).Debug("skipping non result data, the name pattern doesn't contain the data type")
+		return nil
+	}
+
+	tableName, err := outputs.GetFilenameFromPattern(p.config.TableNamePattern, "", data, nil)
+	if err != nil {
+		return err
+	}
+
+	db, ok := p.dbCons[p.config.DSN]
+	if !ok {
+		db, err = sqlx.Connect("postgres", p.config.DSN)
+		if err != nil {
+			return err
+		}
+
+		p.dbCons[p.config.DSN] = db
+	}
+
+	// Iterate over headers
+	columns := []string{}
+	for _, r := range dataTable.Headers {
+		if r == nil {
+			continue
+		}
+		columns = append(columns, util.CastToString(r.Value))
+	}
+
+	rows := [][]interface{}{}
+	for _, row := range dataTable.Rows {
+		cells := []interface{}{}
+		for _, r := range row {
+			if r == nil {
+				continue
+			}
+			cells = append(cells, r.Value)
+		}
+		if len(cells) == 0 {
+			continue
+		}
+		rows = append(rows, p.convertTime(columns, cells))
+	}
+	if len(rows) == 0 {
+		return nil
+	}
+
+	if !p.tables[tableName] {
+		if err := p.createTable(db, tableName, columns, rows[0]); err != nil {
+			return err
+		}
+		p.tables[tableName] = true
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("couldn't begin transaction in postgres database. %+v", err)
+	}
+	stmt, err := tx.Prepare(p.buildInsertQuery(tableName, columns))
+	if err != nil {
+		tx.Rollback()
+		return fmt.Errorf("couldn't prepare insert in postgres database. %+v", err)
+	}
+	for _, cells := range rows {
+		if _, err := stmt.Exec(cells...); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("couldn't insert data in postgres database. %+v", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("couldn't commit inserts in postgres database. %+v", err)
+	}
+
+	return nil
+}
+
+// convertTime convert the value of the time column to time.Time, so it can be stored as a timestamp
+func (p Postgres) convertTime(columns []string, cells []interface{}) []interface{} {
+	for i, c := range columns {
+		if c != p.config.TimeColumn || len(cells) < i+1 {
+			continue
+		}
+		if val, ok := cells[i].(string); ok {
+			if ts, err := time.Parse(util.TimeDateFormat, val); err == nil {
+				cells[i] = ts
+			}
+		}
+	}
+
+	return cells
+}
+
+func (p Postgres) createTable(db *sqlx.DB, tableName string, columns []string, firstRow []interface{}) error {
+	// The query should only return an error when the table doesn't exist
+	if _, err := db.Exec(fmt.Sprintf(checkIfTableExistsQuery, pq.QuoteIdentifier(tableName))); err == nil {
+		return nil
+	}
+
+	// Only auto create tables when enabled
+	if !*p.config.AutoCreateTables {
+		return fmt.Errorf("table %s doesn't exist in postgres database and AutoCreateTables is false", tableName)
+	}
+
+	// Start transaction, exec the CREATE TABLE query (and create the hypertable) and commit the result
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("couldn't begin transaction in postgres database. %+v", err)
+	}
+	if _, err := tx.Exec(p.buildCreateTableQuery(tableName, columns, firstRow)); err != nil {
+		tx.Rollback()
+		return fmt.Errorf("couldn't create table in postgres database. %+v", err)
+	}
+	if *p.config.TimescaleDB.Enabled {
+		if err := p.createHypertable(tx, tableName, columns); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("couldn't create table in postgres database. %+v", err)
+	}
+
+	return nil
+}
+
+// createHypertable turn the table into a TimescaleDB hypertable, tables without the time column (e.g., the assertions
+// and regressions tables) can't be hypertables and are kept as normal tables
+func (p Postgres) createHypertable(tx *sql.Tx, tableName string, columns []string) error {
+	hasTimeColumn := false
+	for _, c := range columns {
+		if c == p.config.TimeColumn {
+			hasTimeColumn = true
+			break
+		}
+	}
+	if !hasTimeColumn {
+		p.logger.WithFields(logrus.Fields{"table": tableName, "column": p.config.TimeColumn}).Debug("table has no time column, not creating timescaledb hypertable")
+		return nil
+	}
+
+	var err error
+	if p.config.TimescaleDB.ChunkTimeInterval != "" {
+		_, err = tx.Exec(createHypertableChunkQuery, pq.QuoteIdentifier(tableName), p.config.TimeColumn, p.config.TimescaleDB.ChunkTimeInterval)
+	} else {
+		_, err = tx.Exec(createHypertableQuery, pq.QuoteIdentifier(tableName), p.config.TimeColumn)
+	}
+	if err != nil {
+		return fmt.Errorf("couldn't create timescaledb hypertable in postgres database. %+v", err)
+	}
+
+	return nil
+}
+
+func (p Postgres) buildCreateTableQuery(tableName string, columns []string, firstRow []interface{}) string {
+	query := fmt.Sprintf(createTableBeginQuery, pq.QuoteIdentifier(tableName))
+
+	for i, c := range columns {
+		cType := PostgresTextType
+
+		if len(firstRow) >= i+1 {
+			switch firstRow[i].(type) {
+			case bool:
+				cType = PostgresBoolType
+			case float32:
+				cType = PostgresFloatType
+			case float64:
+				cType = PostgresFloatType
+			case int:
+				cType = PostgresIntType
+			case int8:
+				cType = PostgresIntType
+			case int16:
+				cType = PostgresIntType
+			case int32:
+				cType = PostgresIntType
+			case int64:
+				cType = PostgresIntType
+			case time.Time:
+				cType = PostgresTimestampType
+			}
+		}
+		if t, ok := p.config.ColumnTypes[c]; ok {
+			cType = t
+		}
+		// TimescaleDB requires the time column to be not null
+		if *p.config.TimescaleDB.Enabled && c == p.config.TimeColumn {
+			cType += " NOT NULL"
+		}
+
+		query += fmt.Sprintf("    %s %s", pq.QuoteIdentifier(c), cType)
+		if len(columns) != i+1 {
+			query += ","
+		}
+		query += "\n"
+	}
+
+	query += createTableEndQuery
+
+	return query
+}
+
+func (p Postgres) buildInsertQuery(tableName string, columns []string) string {
+	quoted := []string{}
+	placeholders := []string{}
+	// Generate the placeholder `$1` and so on
+	for i, c := range columns {
+		quoted = append(quoted, pq.QuoteIdentifier(c))
+		placeholders = append(placeholders, fmt.Sprintf("$%d", i+1))
+	}
+
+	return fmt.Sprintf(insertDataQuery, pq.QuoteIdentifier(tableName), strings.Join(quoted, ", "), strings.Join(placeholders, ", "))
+}
+
+// OutputFiles return a list of output files
+func (p Postgres) OutputFiles() []string {
+	return []string{}
+}
+
+// Close closes all Postgres connections
+func (p Postgres) Close() error {
+	for name, db := range p.dbCons {
+		p.logger.Debug("closing db connection")
+		if err := db.Close(); err != nil {
+			p.logger.Errorf("error closing db connection. %+v", err)
+		}
+		delete(p.dbCons, name)
+	}
+
+	return nil
+}
@@ -0,0 +1,309 @@
+/*
+Copyright 2022 Cloudical Deutschland GmbH. All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package prometheus
+
+import (
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/cloudical-io/ancientt/outputs"
+	"github.com/cloudical-io/ancientt/pkg/config"
+	"github.com/cloudical-io/ancientt/pkg/util"
+	promclient "github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/push"
+	"github.com/prometheus/common/model"
+	"github.com/sirupsen/logrus"
+	log "github.com/sirupsen/logrus"
+)
+
+// NamePrometheus Prometheus output name
+const NamePrometheus = "prometheus"
+
+const (
+	defaultNamePattern = "ancientt-{{ .Data.Tester }}.prom"
+
+	// roundColumn column of the tester results with the round, used as a label
+	roundColumn = "round"
+)
+
+// defaultLabels labels every gauge has, the values are taken from the data
+var defaultLabels = []string{"tester", "server_host", "client_host", "ip_family", "round"}
+
+// hostColumns columns of the tables which contain the server / client hosts and the IP family. The summary, assertions
+// and regressions data contain the results of multiple pairs, so the columns are used over the fields of the data.
+var hostColumns = []string{"server_host", "client_host", "ip_family"}
+
+var invalidMetricNameChars = regexp.MustCompile(`[^a-zA-Z0-9_:]`)
+
+func init() {
+	outputs.Factories[NamePrometheus] = NewPrometheusOutput
+}
+
+// Prometheus Prometheus output structure
+type Prometheus struct {
+	outputs.Output
+	logger     *log.Entry
+	config     *config.Prometheus
+	labelNames []string
+	files      map[string]*registry
+	push       *registry
+	pusher     *push.Pusher
+}
+
+// registry Prometheus registry with the gauges registered in it
+type registry struct {
+	registry *promclient.Registry
+	gauges   map[string]*promclient.GaugeVec
+}
+
+// NewPrometheusOutput return a new Prometheus output instance
+func NewPrometheusOutput(cfg *config.Config, outCfg *config.Output) (outputs.Output, error) {
+	if outCfg == nil || outCfg.Prometheus == nil {
+		return nil, fmt.Errorf("no prometheus output config given")
+	}
+	p := Prometheus{
+		logger: log.WithFields(logrus.Fields{"output": NamePrometheus}),
+		config: outCfg.Prometheus,
+		files:  map[string]*registry{},
+	}
+	p.config.SetDefaults()
+
+	if p.config.TextFile == nil && p.config.Pushgateway == nil {
+		return nil, fmt.Errorf("neither textfile nor pushgateway configured for prometheus output")
+	}
+	if p.config.TextFile != nil && p.config.TextFile.NamePattern == "" {
+		p.config.TextFile.NamePattern = defaultNamePattern
+	}
+
+	p.labelNames = append([]string{}, defaultLabels...)
+	extraLabels := []string{}
+	for label := range p.config.Labels {
+		if !model.LabelName(label).IsValid() {
+			return nil, fmt.Errorf("invalid prometheus label name %q", label)
+		}
+		for _, l := range defaultLabels {
+			if label == l {
+				return nil, fmt.Errorf("prometheus label %s is always added and can't be mapped to a column", label)
+			}
+		}
+		extraLabels = append(extraLabels, label)
+	}
+	sort.Strings(extraLabels)
+	p.labelNames = append(p.labelNames, extraLabels...)
+
+	if p.config.Pushgateway != nil {
+		p.config.Pushgateway.SetDefaults()
+		p.push = newRegistry()
+		p.pusher = push.New(p.config.Pushgateway.URL, p.config.Pushgateway.Job).
+			Gatherer(p.push.registry).
+			Client(&http.Client{Timeout: p.config.Pushgateway.Timeout})
+		for name, value := range p.config.Pushgateway.Grouping {
+			p.pusher = p.pusher.Grouping(name, value)
+		}
+	}
+
+	return p, nil
+}
+
+func newRegistry() *registry {
+	return &registry{
+		registry: promclient.NewRegistry(),
+		gauges:   map[string]*promclient.GaugeVec{},
+	}
+}
+
+// Do make Prometheus outputs
+func (p Prometheus) Do(data outputs.Data) error {
+	dataTable, ok := data.Data.(*outputs.Table)
+	if !ok {
+		return fmt.Errorf("data not in data table format for prometheus output")
+	}
+
+	if p.config.TextFile != nil {
+		filename, err := outputs.GetFilenameFromPattern(p.config.TextFile.NamePattern, "", data, nil)
+		if err != nil {
+			return err
+		}
+		outPath := filepath.Join(p.config.TextFile.FilePath, filename)
+
+		reg, ok := p.files[outPath]
+		if !ok {
+			reg = newRegistry()
+			p.files[outPath] = reg
+		}
+		if err := p.setGauges(reg, data, dataTable); err != nil {
+			return err
+		}
+
+		// The file is written to a temporary file first and then renamed, so the textfile collector never reads a
+		// partially written file
+		if err := promclient.WriteToTextfile(outPath, reg.registry); err != nil {
+			return fmt.Errorf("failed to write prometheus textfile %s. %+v", outPath, err)
+		}
+	}
+
+	if p.pusher != nil {
+		if err := p.setGauges(p.push, data, dataTable); err != nil {
+			return err
+		}
+		// All metrics of the job are replaced, the registry contains the metrics of all data received till now
+		if err := p.pusher.Push(); err != nil {
+			return fmt.Errorf("failed to push metrics to prometheus pushgateway %s. %+v", p.config.Pushgateway.URL, err)
+		}
+	}
+
+	return nil
+}
+
+// setGauges set the gauges for the numeric columns of each row of the data table
+func (p Prometheus) setGauges(reg *registry, data outputs.Data, dataTable *outputs.Table) error {
+	// Columns used for labels are not converted into gauges
+	labelColumns := map[string]string{
+		roundColumn: "round",
+	}
+	for _, column := range hostColumns {
+		labelColumns[column] = column
+	}
+	for label, column := range p.config.Labels {
+		labelColumns[column] = label
+	}
+
+	headers := make([]string, len(dataTable.Headers))
+	for i, header := range dataTable.Headers {
+		if header == nil {
+			continue
+		}
+		headers[i] = util.CastToString(header.Value)
+	}
+
+	for _, row := range dataTable.Rows {
+		labels := promclient.Labels{}
+		for _, label := range p.labelNames {
+			labels[label] = ""
+		}
+		labels["tester"] = data.Tester
+		labels["server_host"] = data.ServerHost
+		labels["client_host"] = data.ClientHost
+		labels["ip_family"] = data.IPFamily
+
+		for i, r := range row {
+			if r == nil || i >= len(headers) {
+				continue
+			}
+			if label, ok := labelColumns[headers[i]]; ok {
+				if value := util.CastToString(r.Value); value != "" || !isHostColumn(headers[i]) {
+					labels[label] = value
+				}
+			}
+		}
+
+		for i, r := range row {
+			if r == nil || i >= len(headers) || headers[i] == "" {
+				continue
+			}
+			if _, ok := labelColumns[headers[i]]; ok {
+				continue
+			}
+
+			metric := headers[i]
+			if len(p.config.Columns) > 0 {
+				m, ok := p.config.Columns[headers[i]]
+				if !ok {
+					continue
+				}
+				metric = m
+			}
+
+			value, err := util.CastNumberToFloat64(r.Value)
+			if err != nil {
+				continue
+			}
+
+			gauge, err := p.gauge(reg, data.Type, headers[i], metric)
+			if err != nil {
+				return err
+			}
+			gauge.With(labels).Set(value)
+		}
+	}
+
+	return nil
+}
+
+func isHostColumn(column string) bool {
+	for _, c := range hostColumns {
+		if c == column {
+			return true
+		}
+	}
+	return false
+}
+
+// gauge return the (registered) gauge for the metric
+func (p Prometheus) gauge(reg *registry, dataType outputs.DataType, column string, metric string) (*promclient.GaugeVec, error) {
+	name := metricName(p.config.MetricPrefix, string(dataType), metric)
+	if gauge, ok := reg.gauges[name]; ok {
+		return gauge, nil
+	}
+
+	if !model.IsValidMetricName(model.LabelValue(name)) {
+		return nil, fmt.Errorf("invalid prometheus metric name %q for column %s", name, column)
+	}
+
+	help := fmt.Sprintf("ancientt tester data column %s", column)
+	if dataType != outputs.DataTypeResult {
+		help = fmt.Sprintf("ancientt %s data column %s", dataType, column)
+	}
+	gauge := promclient.NewGaugeVec(promclient.GaugeOpts{
+		Name: name,
+		Help: help,
+	}, p.labelNames)
+	if err := reg.registry.Register(gauge); err != nil {
+		return nil, fmt.Errorf("failed to register prometheus gauge %s. %+v", name, err)
+	}
+	reg.gauges[name] = gauge
+
+	return gauge, nil
+}
+
+// metricName build the metric name from the given parts, characters not allowed in metric names are replaced by `_`
+func metricName(parts ...string) string {
+	name := []string{}
+	for _, part := range parts {
+		if part == "" {
+			continue
+		}
+		name = append(name, invalidMetricNameChars.ReplaceAllString(part, "_"))
+	}
+
+	return strings.Join(name, "_")
+}
+
+// OutputFiles return a list of output files
+func (p Prometheus) OutputFiles() []string {
+	list := []string{}
+	for file := range p.files {
+		list = append(list, file)
+	}
+	return list
+}
+
+// Close nothing to do here, the metrics are written / pushed on each Do() call
+func (p Prometheus) Close() error {
+	return nil
+}
@@ -0,0 +1,153 @@
+/*
+Copyright 2022 Cloudical Deutschland GmbH. All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package prometheus
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/cloudical-io/ancientt/outputs"
+	"github.com/cloudical-io/ancientt/parsers"
+	"github.com/cloudical-io/ancientt/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// generateTestData return the summary of the Summarizer for two pairs, one of them tested with both IP families
+func generateTestData() outputs.Data {
+	summarizer := parsers.NewSummarizer()
+	for _, input := range []parsers.Input{
+		{Tester: "iperf3", ServerHost: "host1", ClientHost: "host2", IPFamily: "v4"},
+		{Tester: "iperf3", ServerHost: "host1", ClientHost: "host2", IPFamily: "v6"},
+		{Tester: "iperf3", ServerHost: "host1", ClientHost: "host3", IPFamily: "v4"},
+	} {
+		input.TestStartTime = time.Now()
+		input.TestTime = time.Now()
+		summarizer.Add(input, "bits_per_second", 12000, 12001)
+		summarizer.Add(input, "rtt", 42)
+	}
+	data, _ := summarizer.Data()
+	// The values differ per pair, to be able to tell the gauges apart
+	table := data.Data.(*outputs.Table)
+	avg, _ := table.GetHeaderIndexByName("avg")
+	table.Rows[2][avg].Value = 11000.5
+	table.Rows[4][avg].Value = 10000.5
+	return data
+}
+
+func TestPrometheusTextFile(t *testing.T) {
+	tempDir := t.TempDir()
+	outCfg := &config.Output{
+		Prometheus: &config.Prometheus{
+			TextFile: &config.FilePath{
+				FilePath: tempDir,
+			},
+		},
+	}
+
+	p, err := NewPrometheusOutput(nil, outCfg)
+	require.Nil(t, err)
+
+	require.Nil(t, p.Do(generateTestData()))
+	require.Nil(t, p.Close())
+
+	outPath := filepath.Join(tempDir, "ancientt-iperf3.prom")
+	assert.Equal(t, []string{outPath}, p.OutputFiles())
+
+	out, err := ioutil.ReadFile(outPath)
+	require.Nil(t, err)
+	assert.Contains(t, string(out), "# TYPE ancientt_summary_avg gauge\n")
+	// The hosts and IP family are taken from the columns of the summary
+	assert.Contains(t, string(out), `ancientt_summary_avg{client_host="host2",ip_family="v4",metric="bits_per_second",round="0",server_host="host1",tester="iperf3"} 12000.5`)
+	assert.Contains(t, string(out), `ancientt_summary_avg{client_host="host2",ip_family="v6",metric="bits_per_second",round="0",server_host="host1",tester="iperf3"} 11000.5`)
+	assert.Contains(t, string(out), `ancientt_summary_avg{client_host="host3",ip_family="v4",metric="bits_per_second",round="0",server_host="host1",tester="iperf3"} 10000.5`)
+	assert.Contains(t, string(out), `ancientt_summary_avg{client_host="host2",ip_family="v4",metric="rtt",round="0",server_host="host1",tester="iperf3"} 42`)
+	assert.Contains(t, string(out), `ancientt_summary_count{client_host="host2",ip_family="v4",metric="rtt",round="0",server_host="host1",tester="iperf3"} 1`)
+	// String columns are not converted into gauges
+	assert.NotContains(t, string(out), "ancientt_summary_tester")
+}
+
+func TestPrometheusResultData(t *testing.T) {
+	tempDir := t.TempDir()
+	p, err := NewPrometheusOutput(nil, &config.Output{Prometheus: &config.Prometheus{
+		TextFile: &config.FilePath{FilePath: tempDir},
+	}})
+	require.Nil(t, err)
+
+	// Tester results without host columns are labeled with the hosts of the data
+	require.Nil(t, p.Do(outputs.Data{
+		Tester:     "iperf3",
+		ServerHost: "host1",
+		ClientHost: "host2",
+		IPFamily:   "v6",
+		Data: &outputs.Table{
+			Headers: []*outputs.Row{{Value: "bits_per_second"}},
+			Rows:    [][]*outputs.Row{{{Value: 1000.0}}},
+		},
+	}))
+
+	out, err := ioutil.ReadFile(filepath.Join(tempDir, "ancientt-iperf3.prom"))
+	require.Nil(t, err)
+	assert.Contains(t, string(out), `ancientt_bits_per_second{client_host="host2",ip_family="v6",metric="",round="",server_host="host1",tester="iperf3"} 1000`)
+}
+
+func TestPrometheusPushgateway(t *testing.T) {
+	var method, path, body string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		method = r.Method
+		path = r.URL.Path
+		out, _ := ioutil.ReadAll(r.Body)
+		body = string(out)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	outCfg := &config.Output{
+		Prometheus: &config.Prometheus{
+			Pushgateway: &config.PrometheusPushgateway{
+				URL:      server.URL,
+				Grouping: map[string]string{"instance": "ci"},
+			},
+			MetricPrefix: "network",
+			Columns: map[string]string{
+				"avg": "average",
+			},
+		},
+	}
+
+	p, err := NewPrometheusOutput(nil, outCfg)
+	require.Nil(t, err)
+	require.Nil(t, p.Do(generateTestData()))
+
+	assert.Equal(t, http.MethodPut, method)
+	assert.Equal(t, "/metrics/job/ancientt/instance/ci", path)
+	// The metrics are pushed in the protobuf format, the metric names are contained as is
+	assert.Contains(t, body, "network_summary_average")
+	assert.NotContains(t, body, "network_summary_count")
+}
+
+func TestPrometheusConfig(t *testing.T) {
+	_, err := NewPrometheusOutput(nil, &config.Output{Prometheus: &config.Prometheus{}})
+	assert.NotNil(t, err)
+
+	_, err = NewPrometheusOutput(nil, &config.Output{Prometheus: &config.Prometheus{
+		TextFile: &config.FilePath{FilePath: t.TempDir()},
+		Labels:   map[string]string{"tester": "tester"},
+	}})
+	assert.NotNil(t, err)
+}
@@ -38,7 +38,7 @@ const (
 	SQLiteBoolType  = "BOOLEAN"
 
 	defaultNamePattern      = "ancientt-{{ .TestStartTime }}-{{ .Data.Tester }}.sqlite3"
-	defaultTableNamePattern = "ancientt{{ .TestStartTime }}{{ .Data.Tester }}{{ .Data.ServerHost }}{{ .Data.ClientHost }}"
+	defaultTableNamePattern = "ancientt{{ .TestStartTime }}{{ .Data.Tester }}{{ .Data.ServerHost }}{{ .Data.ClientHost }}{{ .Data.Type }}"
 
 	createTableBeginQuery = "CREATE TABLE IF NOT EXISTS `%s` (\n"
 	createTableEndQuery   = `);`
@@ -87,6 +87,11 @@ func (s SQLite) Do(data outputs.Data) error {
 		return fmt.Errorf("data not in data table format for sqlite output")
 	}
 
+	if !outputs.SeparatesDataTypes(data, s.config.FilePath.NamePattern, s.config.TableNamePattern) {
+		s.logger.WithFields(logrus.Fields{"type": data.Type}).Debug("skipping non result data, the name pattern doesn't contain the data type")
+		return nil
+	}
+
 	filename, err := outputs.GetFilenameFromPattern(s.config.FilePath.NamePattern, "", data, nil)
 	if err != nil {
 		return err
@@ -16,6 +16,7 @@ package iperf3
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 
@@ -38,15 +39,17 @@ func init() {
 // IPerf3 IPerf3 tester structure
 type IPerf3 struct {
 	parsers.Parser
-	logger *log.Entry
-	config *config.Test
+	logger  *log.Entry
+	config  *config.Test
+	summary *parsers.Summarizer
 }
 
 // NewIPerf3Tester return a new IPerf3 tester instance
 func NewIPerf3Tester(cfg *config.Config, test *config.Test) (parsers.Parser, error) {
 	return IPerf3{
-		logger: log.WithFields(logrus.Fields{"parers": NameIPerf3}),
-		config: test,
+		logger:  log.WithFields(logrus.Fields{"parers": NameIPerf3}),
+		config:  test,
+		summary: parsers.NewSummarizer(),
 	}, nil
 }
 
@@ -62,7 +65,6 @@ func (p IPerf3) Parse(doneCh chan struct{}, inCh <-chan parsers.Input, dataCh ch
 			}
 			if input.ClientHost == "" && input.ServerHost == "" && input.Tester == "" {
 				log.Warn("received input.Data with empty input.Tester and others are empty, 'signal' channel closed")
-				close(dataCh)
 				return nil
 			}
 			if err := p.parse(input, dataCh); err != nil {
@@ -72,11 +74,26 @@ func (p IPerf3) Parse(doneCh chan struct{}, inCh <-chan parsers.Input, dataCh ch
 	}
 }
 
+// Summary return the summary of all parsed results for the outputs
+func (p IPerf3) Summary() (*outputs.Data, error) {
+	data, ok := p.summary.Data()
+	if !ok {
+		return nil, nil
+	}
+	return &data, nil
+}
+
 func (p IPerf3) parse(input parsers.Input, dataCh chan<- outputs.Data) error {
 	var logs *bytes.Buffer
 	if input.DataStream != nil {
 		logs = new(bytes.Buffer)
 		if _, err := io.Copy(logs, *input.DataStream); err != nil {
+			if errors.Is(err, parsers.ErrTaskFailed) {
+				(*input.DataStream).Close()
+				p.logger.WithFields(logrus.Fields{"server": input.ServerHost, "client": input.ClientHost}).
+					Warnf("skipping output of failed task. %+v", err)
+				return nil
+			}
 			return fmt.Errorf("error in copy information from logs to buffer")
 		}
 		if err := (*input.DataStream).Close(); err != nil {
@@ -103,6 +120,7 @@ func (p IPerf3) parse(input parsers.Input, dataCh chan<- outputs.Data) error {
 			{Value: "tester"},
 			{Value: "server_host"},
 			{Value: "client_host"},
+			{Value: "ip_family"},
 			{Value: "socket"},
 			{Value: "start"},
 			{Value: "end"},
@@ -122,7 +140,11 @@ func (p IPerf3) parse(input parsers.Input, dataCh chan<- outputs.Data) error {
 		Rows: [][]*outputs.Row{},
 	}
 
+	throughput := []float64{}
 	for _, interval := range result.Intervals {
+		if !interval.Sum.Omitted {
+			throughput = append(throughput, interval.Sum.BitsPerSecond)
+		}
 		for _, stream := range interval.Streams {
 			intervalTable.Rows = append(intervalTable.Rows, []*outputs.Row{
 				{Value: input.TestTime.Format(util.TimeDateFormat)},
@@ -130,6 +152,7 @@ func (p IPerf3) parse(input parsers.Input, dataCh chan<- outputs.Data) error {
 				{Value: input.Tester},
 				{Value: input.ServerHost},
 				{Value: input.ClientHost},
+				{Value: input.IPFamily},
 				{Value: stream.Socket},
 				{Value: stream.Start},
 				{Value: stream.End},
@@ -149,6 +172,8 @@ func (p IPerf3) parse(input parsers.Input, dataCh chan<- outputs.Data) error {
 		}
 	}
 
+	p.summary.Add(input, "bits_per_second", throughput...)
+
 	p.logger.Debug("parsed data input")
 
 	// Transform Input into outputs.Data struct
@@ -158,6 +183,7 @@ func (p IPerf3) parse(input parsers.Input, dataCh chan<- outputs.Data) error {
 		AdditionalInfo: input.AdditionalInfo,
 		ServerHost:     input.ServerHost,
 		ClientHost:     input.ClientHost,
+		IPFamily:       input.IPFamily,
 		Tester:         input.Tester,
 		Data:           intervalTable,
 	}
@@ -166,9 +192,115 @@ func (p IPerf3) parse(input parsers.Input, dataCh chan<- outputs.Data) error {
 
 	dataCh <- data
 
-	// TODO generate sum and / or end table and send to output
-
 	p.logger.Debug("sent parsed data to dataCh")
 
+	// Send the end results as a separate Data to the outputs
+	dataCh <- outputs.Data{
+		TestStartTime:  input.TestStartTime,
+		TestTime:       input.TestTime,
+		Type:           outputs.DataTypeEnd,
+		AdditionalInfo: input.AdditionalInfo,
+		ServerHost:     input.ServerHost,
+		ClientHost:     input.ClientHost,
+		IPFamily:       input.IPFamily,
+		Tester:         input.Tester,
+		Data:           p.endTable(input, result),
+	}
+
+	p.logger.Debug("sent end data to dataCh")
+
 	return nil
 }
+
+// endTable generate a table with one row containing the sender and receiver totals of the test
+func (p IPerf3) endTable(input parsers.Input, result *models.ClientResult) *outputs.Table {
+	end := result.End
+
+	sent := models.SumSent{
+		Start:         end.SumSent.Start,
+		End:           end.SumSent.End,
+		Seconds:       end.SumSent.Seconds,
+		Bytes:         end.SumSent.Bytes,
+		BitsPerSecond: end.SumSent.BitsPerSecond,
+		Retransmits:   end.SumSent.Retransmits,
+	}
+	// Older iperf3 versions only return the `sum` for UDP tests
+	if sent.Bytes == 0 && end.Sum.Bytes > 0 {
+		sent.Start = end.Sum.Start
+		sent.End = end.Sum.End
+		sent.Seconds = end.Sum.Seconds
+		sent.Bytes = end.Sum.Bytes
+		sent.BitsPerSecond = end.Sum.BitsPerSecond
+	}
+
+	return &outputs.Table{
+		Headers: []*outputs.Row{
+			{Value: "test_time"},
+			{Value: "round"},
+			{Value: "tester"},
+			{Value: "server_host"},
+			{Value: "client_host"},
+			{Value: "ip_family"},
+			{Value: "protocol"},
+			{Value: "num_streams"},
+			{Value: "start"},
+			{Value: "end"},
+			{Value: "seconds"},
+			{Value: "sent_bytes"},
+			{Value: "sent_bits_per_second"},
+			{Value: "sent_retransmits"},
+			{Value: "received_bytes"},
+			{Value: "received_bits_per_second"},
+			{Value: "cpu_host_total"},
+			{Value: "cpu_host_user"},
+			{Value: "cpu_host_system"},
+			{Value: "cpu_remote_total"},
+			{Value: "cpu_remote_user"},
+			{Value: "cpu_remote_system"},
+			{Value: "sender_tcp_congestion"},
+			{Value: "receiver_tcp_congestion"},
+			{Value: "jitter_ms"},
+			{Value: "lost_packets"},
+			{Value: "packets"},
+			{Value: "lost_percent"},
+			{Value: "iperf3_version"},
+			{Value: "system_info"},
+			{Value: "additional_info"},
+		},
+		Rows: [][]*outputs.Row{
+			{
+				{Value: input.TestTime.Format(util.TimeDateFormat)},
+				{Value: input.Round},
+				{Value: input.Tester},
+				{Value: input.ServerHost},
+				{Value: input.ClientHost},
+				{Value: input.IPFamily},
+				{Value: result.Start.TestStart.Protocol},
+				{Value: result.Start.TestStart.NumStreams},
+				{Value: sent.Start},
+				{Value: sent.End},
+				{Value: sent.Seconds},
+				{Value: sent.Bytes},
+				{Value: sent.BitsPerSecond},
+				{Value: sent.Retransmits},
+				{Value: end.SumReceived.Bytes},
+				{Value: end.SumReceived.BitsPerSecond},
+				{Value: end.CPUUtilizationPercent.HostTotal},
+				{Value: end.CPUUtilizationPercent.HostUser},
+				{Value: end.CPUUtilizationPercent.HostSystem},
+				{Value: end.CPUUtilizationPercent.RemoteTotal},
+				{Value: end.CPUUtilizationPercent.RemoteUser},
+				{Value: end.CPUUtilizationPercent.RemoteSystem},
+				{Value: end.SenderTCPCongestion},
+				{Value: end.ReceiverTCPCongestion},
+				{Value: end.Sum.JitterMS},
+				{Value: end.Sum.LostPackets},
+				{Value: end.Sum.Packets},
+				{Value: end.Sum.LostPercent},
+				{Value: result.Start.Version},
+				{Value: result.Start.SystemInfo},
+				{Value: input.AdditionalInfo},
+			},
+		},
+	}
+}
@@ -0,0 +1,128 @@
+/*
+Copyright 2019 Cloudical Deutschland GmbH. All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package iperf3
+
+import (
+	"fmt"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/cloudical-io/ancientt/outputs"
+	"github.com/cloudical-io/ancientt/parsers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testIPerf3Result = `{
+	"start": {
+		"version": "iperf 3.7",
+		"system_info": "Linux test",
+		"test_start": {"protocol": "TCP", "num_streams": 1, "duration": 2}
+	},
+	"intervals": [
+		{"streams": [{"socket": 5, "start": 0, "end": 1, "seconds": 1, "bytes": 1000, "bits_per_second": 8000, "retransmits": 1}], "sum": {"start": 0, "end": 1, "seconds": 1, "bytes": 1000, "bits_per_second": 8000}},
+		{"streams": [{"socket": 5, "start": 1, "end": 2, "seconds": 1, "bytes": 2000, "bits_per_second": 16000, "retransmits": 0}], "sum": {"start": 1, "end": 2, "seconds": 1, "bytes": 2000, "bits_per_second": 16000}}
+	],
+	"end": {
+		"sum_sent": {"start": 0, "end": 2, "seconds": 2, "bytes": 3000, "bits_per_second": 12000, "retransmits": 1},
+		"sum_received": {"start": 0, "end": 2, "seconds": 2, "bytes": 2900, "bits_per_second": 11600},
+		"cpu_utilization_percent": {"host_total": 10.5, "remote_total": 2.5},
+		"sender_tcp_congestion": "cubic",
+		"receiver_tcp_congestion": "cubic"
+	}
+}`
+
+func TestIPerf3Parse(t *testing.T) {
+	p, err := NewIPerf3Tester(nil, nil)
+	require.Nil(t, err)
+
+	dataCh := make(chan outputs.Data, 3)
+	err = p.(IPerf3).parse(parsers.Input{
+		TestStartTime: time.Now(),
+		TestTime:      time.Now(),
+		Tester:        NameIPerf3,
+		ServerHost:    "server1",
+		ClientHost:    "client1",
+		IPFamily:      "v6",
+		Data:          []byte(testIPerf3Result),
+	}, dataCh)
+	require.Nil(t, err)
+	close(dataCh)
+	summary, err := p.Summary()
+	require.Nil(t, err)
+	require.NotNil(t, summary)
+
+	intervals := <-dataCh
+	assert.Equal(t, outputs.DataTypeResult, intervals.Type)
+	intervalTable, ok := intervals.Data.(*outputs.Table)
+	require.True(t, ok)
+	assert.Equal(t, 2, len(intervalTable.Rows))
+
+	end := <-dataCh
+	assert.Equal(t, outputs.DataTypeEnd, end.Type)
+	assert.Equal(t, "server1", end.ServerHost)
+	assert.Equal(t, "v6", end.IPFamily)
+	endTable, ok := end.Data.(*outputs.Table)
+	require.True(t, ok)
+	require.Equal(t, 1, len(endTable.Rows))
+	assert.Equal(t, len(endTable.Headers), len(endTable.Rows[0]))
+
+	index, err := endTable.GetHeaderIndexByName("ip_family")
+	require.Nil(t, err)
+	assert.Equal(t, "v6", endTable.Rows[0][index].Value)
+	index, err = endTable.GetHeaderIndexByName("received_bits_per_second")
+	require.Nil(t, err)
+	assert.Equal(t, float64(11600), endTable.Rows[0][index].Value)
+	index, err = endTable.GetHeaderIndexByName("sent_retransmits")
+	require.Nil(t, err)
+	assert.Equal(t, int64(1), endTable.Rows[0][index].Value)
+	index, err = endTable.GetHeaderIndexByName("cpu_host_total")
+	require.Nil(t, err)
+	assert.Equal(t, 10.5, endTable.Rows[0][index].Value)
+
+	assert.Equal(t, outputs.DataTypeSummary, summary.Type)
+	summaryTable, ok := summary.Data.(*outputs.Table)
+	require.True(t, ok)
+	require.Equal(t, 1, len(summaryTable.Rows))
+	index, err = summaryTable.GetHeaderIndexByName("avg")
+	require.Nil(t, err)
+	assert.Equal(t, float64(12000), summaryTable.Rows[0][index].Value)
+	index, err = summaryTable.GetHeaderIndexByName("max")
+	require.Nil(t, err)
+	assert.Equal(t, float64(16000), summaryTable.Rows[0][index].Value)
+}
+
+func TestIPerf3ParseFailedTask(t *testing.T) {
+	p, err := NewIPerf3Tester(nil, nil)
+	require.Nil(t, err)
+
+	reader, writer := io.Pipe()
+	go func() {
+		writer.Write([]byte(`{"start": {`))
+		writer.CloseWithError(fmt.Errorf("%w. exit status 1", parsers.ErrTaskFailed))
+	}()
+
+	// The output of a failed task is skipped without an error, so the parser keeps running
+	dataCh := make(chan outputs.Data, 3)
+	r := io.ReadCloser(reader)
+	require.Nil(t, p.(IPerf3).parse(parsers.Input{
+		Tester:     NameIPerf3,
+		ServerHost: "server1",
+		ClientHost: "client1",
+		DataStream: &r,
+	}, dataCh))
+	close(dataCh)
+	assert.Equal(t, 0, len(dataCh))
+}
@@ -14,6 +14,7 @@ limitations under the License.
 package parsers
 
 import (
+	"errors"
 	"io"
 	"time"
 
@@ -25,12 +26,17 @@ import (
 // The parser can each then be created using the function saved in the map.
 var Factories = make(map[string]func(cfg *config.Config, test *config.Test) (Parser, error))
 
+// ErrTaskFailed the error a runner closes an Input.DataStream with when the task command failed after its output has
+// been sent, the parsers skip such an Input instead of returning an error
+var ErrTaskFailed = errors.New("task command failed")
+
 // Parser is the interface a parser has to implement
 type Parser interface {
-	// Parse parse data from runners.Execute() func
+	// Parse parse data from runners.Execute() func, dataCh is never closed by the parser
 	Parse(doneCh chan struct{}, inCh <-chan Input, dataCh chan<- outputs.Data) error
-	// Summary send summary of parsed data to outputs.Output
-	Summary(doneCh chan struct{}, inCh <-chan Input, dataCh chan<- outputs.Data) error
+	// Summary return the summary of all parsed data for the outputs, called after Parse() returned. nil is returned
+	// when nothing has been parsed
+	Summary() (*outputs.Data, error)
 }
 
 // Input structured parse
@@ -43,5 +49,6 @@ type Input struct {
 	Tester         string
 	ServerHost     string
 	ClientHost     string
+	IPFamily       string
 	AdditionalInfo string
 }
@@ -16,6 +16,7 @@ package pingparsing
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 
@@ -38,15 +39,17 @@ func init() {
 // PingParsing PingParsing tester structure
 type PingParsing struct {
 	parsers.Parser
-	logger *log.Entry
-	config *config.Test
+	logger  *log.Entry
+	config  *config.Test
+	summary *parsers.Summarizer
 }
 
 // NewPingParsingTester return a new PingParsing tester instance
 func NewPingParsingTester(cfg *config.Config, test *config.Test) (parsers.Parser, error) {
 	return PingParsing{
-		logger: log.WithFields(logrus.Fields{"parers": NamePingParsing}),
-		config: test,
+		logger:  log.WithFields(logrus.Fields{"parers": NamePingParsing}),
+		config:  test,
+		summary: parsers.NewSummarizer(),
 	}, nil
 }
 
@@ -62,7 +65,6 @@ func (p PingParsing) Parse(doneCh chan struct{}, inCh <-chan parsers.Input, data
 			}
 			if input.ClientHost == "" && input.ServerHost == "" && input.Tester == "" {
 				log.Warn("received input.Data with empty input.Tester and others are empty, 'signal' channel closed")
-				close(dataCh)
 				return nil
 			}
 			if err := p.parse(input, dataCh); err != nil {
@@ -72,11 +74,26 @@ func (p PingParsing) Parse(doneCh chan struct{}, inCh <-chan parsers.Input, data
 	}
 }
 
+// Summary return the summary of all parsed results for the outputs
+func (p PingParsing) Summary() (*outputs.Data, error) {
+	data, ok := p.summary.Data()
+	if !ok {
+		return nil, nil
+	}
+	return &data, nil
+}
+
 func (p PingParsing) parse(input parsers.Input, dataCh chan<- outputs.Data) error {
 	var logs *bytes.Buffer
 	if input.DataStream != nil {
 		logs = new(bytes.Buffer)
 		if _, err := io.Copy(logs, *input.DataStream); err != nil {
+			if errors.Is(err, parsers.ErrTaskFailed) {
+				(*input.DataStream).Close()
+				p.logger.WithFields(logrus.Fields{"server": input.ServerHost, "client": input.ClientHost}).
+					Warnf("skipping output of failed task. %+v", err)
+				return nil
+			}
 			return fmt.Errorf("error in copy information from logs to buffer")
 		}
 		if err := (*input.DataStream).Close(); err != nil {
@@ -103,6 +120,7 @@ func (p PingParsing) parse(input parsers.Input, dataCh chan<- outputs.Data) erro
 			{Value: "tester"},
 			{Value: "server_host"},
 			{Value: "client_host"},
+			{Value: "ip_family"},
 			{Value: "target"},
 			{Value: "destination"},
 			{Value: "packet_transmit"},
@@ -132,6 +150,7 @@ func (p PingParsing) parse(input parsers.Input, dataCh chan<- outputs.Data) erro
 			{Value: input.Tester},
 			{Value: input.ServerHost},
 			{Value: input.ClientHost},
+			{Value: input.IPFamily},
 			{Value: name},
 			{Value: r.Destination},
 			{Value: r.PacketTransmit},
@@ -146,6 +165,7 @@ func (p PingParsing) parse(input parsers.Input, dataCh chan<- outputs.Data) erro
 			{Value: r.PacketDuplicateCount},
 		}
 		for _, e := range r.ICMPReplies {
+			p.summary.Add(input, "rtt", e.Time)
 			table.Rows = append(table.Rows, append(base, []*outputs.Row{
 				{Value: e.Timestamp},
 				{Value: e.ICMPSeq},
@@ -166,6 +186,7 @@ func (p PingParsing) parse(input parsers.Input, dataCh chan<- outputs.Data) erro
 		AdditionalInfo: input.AdditionalInfo,
 		ServerHost:     input.ServerHost,
 		ClientHost:     input.ClientHost,
+		IPFamily:       input.IPFamily,
 		Tester:         input.Tester,
 		Data:           table,
 	}
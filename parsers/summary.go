@@ -0,0 +1,122 @@
+/*
+Copyright 2022 Cloudical Deutschland GmbH. All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package parsers
+
+import (
+	"time"
+
+	"github.com/cloudical-io/ancientt/outputs"
+	"github.com/cloudical-io/ancientt/pkg/stats"
+	"github.com/cloudical-io/ancientt/pkg/util"
+)
+
+// Summarizer collects values of a metric per round and server / client host pair of a test for the test summary
+type Summarizer struct {
+	testStartTime time.Time
+	tester        string
+	keys          []summaryKey
+	testTimes     map[summaryKey]time.Time
+	values        map[summaryKey][]float64
+}
+
+type summaryKey struct {
+	round      int
+	serverHost string
+	clientHost string
+	ipFamily   string
+	metric     string
+}
+
+// NewSummarizer return a new empty Summarizer
+func NewSummarizer() *Summarizer {
+	return &Summarizer{
+		keys:      []summaryKey{},
+		testTimes: map[summaryKey]time.Time{},
+		values:    map[summaryKey][]float64{},
+	}
+}
+
+// Add add values of a metric for the round and server / client host pair of the given Input
+func (s *Summarizer) Add(input Input, metric string, values ...float64) {
+	s.testStartTime = input.TestStartTime
+	s.tester = input.Tester
+
+	key := summaryKey{
+		round:      input.Round,
+		serverHost: input.ServerHost,
+		clientHost: input.ClientHost,
+		ipFamily:   input.IPFamily,
+		metric:     metric,
+	}
+	if _, ok := s.values[key]; !ok {
+		s.keys = append(s.keys, key)
+		s.testTimes[key] = input.TestTime
+	}
+	s.values[key] = append(s.values[key], values...)
+}
+
+// Data return the summary as outputs.Data, false is returned when no values have been added
+func (s *Summarizer) Data() (outputs.Data, bool) {
+	if len(s.keys) == 0 {
+		return outputs.Data{}, false
+	}
+
+	table := &outputs.Table{
+		Headers: []*outputs.Row{
+			{Value: "test_time"},
+			{Value: "round"},
+			{Value: "tester"},
+			{Value: "server_host"},
+			{Value: "client_host"},
+			{Value: "ip_family"},
+			{Value: "metric"},
+			{Value: "count"},
+			{Value: "min"},
+			{Value: "avg"},
+			{Value: "max"},
+			{Value: "p50"},
+			{Value: "p95"},
+			{Value: "p99"},
+		},
+		Rows: [][]*outputs.Row{},
+	}
+
+	for _, key := range s.keys {
+		summary := stats.Summarize(s.values[key])
+		table.Rows = append(table.Rows, []*outputs.Row{
+			{Value: s.testTimes[key].Format(util.TimeDateFormat)},
+			{Value: key.round},
+			{Value: s.tester},
+			{Value: key.serverHost},
+			{Value: key.clientHost},
+			{Value: key.ipFamily},
+			{Value: key.metric},
+			{Value: summary.Count},
+			{Value: summary.Min},
+			{Value: summary.Avg},
+			{Value: summary.Max},
+			{Value: summary.P50},
+			{Value: summary.P95},
+			{Value: summary.P99},
+		})
+	}
+
+	return outputs.Data{
+		TestStartTime: s.testStartTime,
+		TestTime:      time.Now(),
+		Type:          outputs.DataTypeSummary,
+		Tester:        s.tester,
+		Data:          table,
+	}, true
+}
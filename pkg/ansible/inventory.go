@@ -15,13 +15,18 @@ package ansible
 
 import (
 	"encoding/json"
+	"strconv"
 )
 
 /*
-// The _meta is ignored
+// The _meta contains the host vars, which can be used as labels of the hosts
 {
     "_meta": {
-        "hostvars": {}
+        "hostvars": {
+            "server1": {
+                "rack": "r1"
+            }
+        }
     },
     "all": {
         "children": [
@@ -51,6 +56,8 @@ type InventoryList map[string]HostGroup
 type HostGroup struct {
 	Children []string `json:"children"`
 	Hosts    []string `json:"hosts"`
+	// HostVars only set for the `_meta` "group"
+	HostVars map[string]map[string]interface{} `json:"hostvars,omitempty"`
 }
 
 // Parse raw JSON into
@@ -83,3 +90,26 @@ func (inv *InventoryList) GetHostsForGroup(group string) []string {
 
 	return hosts
 }
+
+// GetHostLabels return the host vars of a host as labels, only vars with a string, number or boolean value are returned
+func (inv *InventoryList) GetHostLabels(host string) map[string]string {
+	labels := map[string]string{}
+
+	meta, ok := (*inv)["_meta"]
+	if !ok {
+		return labels
+	}
+
+	for key, value := range meta.HostVars[host] {
+		switch v := value.(type) {
+		case string:
+			labels[key] = v
+		case float64:
+			labels[key] = strconv.FormatFloat(v, 'f', -1, 64)
+		case bool:
+			labels[key] = strconv.FormatBool(v)
+		}
+	}
+
+	return labels
+}
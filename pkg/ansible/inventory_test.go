@@ -85,3 +85,35 @@ func TestGetHostsForTest(t *testing.T) {
 	assert.Contains(t, test123, "server8")
 	assert.Contains(t, test123, "server9")
 }
+
+func TestGetHostLabels(t *testing.T) {
+	inv, err := Parse([]byte(`{
+		"_meta": {
+			"hostvars": {
+				"server1": {
+					"rack": "r1",
+					"rack_unit": 12,
+					"ssd": true,
+					"interfaces": ["eth0", "eth1"],
+					"bmc": {
+						"address": "192.0.2.100"
+					}
+				}
+			}
+		},
+		"all": {
+			"hosts": [
+				"server1",
+				"server2"
+			]
+		}
+	}`))
+	require.Nil(t, err)
+
+	assert.Equal(t, map[string]string{
+		"rack":      "r1",
+		"rack_unit": "12",
+		"ssd":       "true",
+	}, inv.GetHostLabels("server1"))
+	assert.Equal(t, map[string]string{}, inv.GetHostLabels("server2"))
+}
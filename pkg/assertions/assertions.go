@@ -0,0 +1,146 @@
+/*
+Copyright 2022 Cloudical Deutschland GmbH. All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package assertions
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/cloudical-io/ancientt/pkg/stats"
+)
+
+// Aggregation aggregation applied to the values of a column before comparing it
+type Aggregation string
+
+const (
+	// AggregationCount amount of values
+	AggregationCount Aggregation = "count"
+	// AggregationMin minimum value
+	AggregationMin Aggregation = "min"
+	// AggregationAvg average value
+	AggregationAvg Aggregation = "avg"
+	// AggregationMax maximum value
+	AggregationMax Aggregation = "max"
+	// AggregationP50 50th percentile (median)
+	AggregationP50 Aggregation = "p50"
+	// AggregationP95 95th percentile
+	AggregationP95 Aggregation = "p95"
+	// AggregationP99 99th percentile
+	AggregationP99 Aggregation = "p99"
+)
+
+// Operator comparison operator of an Assertion
+type Operator string
+
+const (
+	// OperatorEqual equal to
+	OperatorEqual Operator = "=="
+	// OperatorNotEqual not equal to
+	OperatorNotEqual Operator = "!="
+	// OperatorLess less than
+	OperatorLess Operator = "<"
+	// OperatorLessEqual less than or equal to
+	OperatorLessEqual Operator = "<="
+	// OperatorGreater greater than
+	OperatorGreater Operator = ">"
+	// OperatorGreaterEqual greater than or equal to
+	OperatorGreaterEqual Operator = ">="
+)
+
+// Assertion parsed assertion, e.g., `bits_per_second p95 >= 9e9`
+type Assertion struct {
+	Expression  string
+	Column      string
+	Aggregation Aggregation
+	Operator    Operator
+	Value       float64
+}
+
+// Parse parse an assertion expression in the format `COLUMN AGGREGATION OPERATOR VALUE`
+func Parse(expression string) (*Assertion, error) {
+	fields := strings.Fields(expression)
+	if len(fields) != 4 {
+		return nil, fmt.Errorf("invalid assertion '%s', expected format 'COLUMN AGGREGATION OPERATOR VALUE'", expression)
+	}
+
+	a := &Assertion{
+		Expression:  strings.Join(fields, " "),
+		Column:      fields[0],
+		Aggregation: Aggregation(strings.ToLower(fields[1])),
+		Operator:    Operator(fields[2]),
+	}
+
+	switch a.Aggregation {
+	case AggregationCount, AggregationMin, AggregationAvg, AggregationMax, AggregationP50, AggregationP95, AggregationP99:
+	default:
+		return nil, fmt.Errorf("invalid aggregation '%s' in assertion '%s'", fields[1], expression)
+	}
+
+	switch a.Operator {
+	case OperatorEqual, OperatorNotEqual, OperatorLess, OperatorLessEqual, OperatorGreater, OperatorGreaterEqual:
+	default:
+		return nil, fmt.Errorf("invalid operator '%s' in assertion '%s'", fields[2], expression)
+	}
+
+	value, err := strconv.ParseFloat(fields[3], 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid value '%s' in assertion '%s'. %+v", fields[3], expression, err)
+	}
+	a.Value = value
+
+	return a, nil
+}
+
+// Aggregate return the value of the Assertion's aggregation from the given summary
+func (a *Assertion) Aggregate(summary stats.Summary) float64 {
+	switch a.Aggregation {
+	case AggregationCount:
+		return float64(summary.Count)
+	case AggregationMin:
+		return summary.Min
+	case AggregationAvg:
+		return summary.Avg
+	case AggregationMax:
+		return summary.Max
+	case AggregationP50:
+		return summary.P50
+	case AggregationP95:
+		return summary.P95
+	case AggregationP99:
+		return summary.P99
+	}
+
+	return 0
+}
+
+// Compare compare the given (aggregated) value against the Assertion's value using its operator
+func (a *Assertion) Compare(value float64) bool {
+	switch a.Operator {
+	case OperatorEqual:
+		return value == a.Value
+	case OperatorNotEqual:
+		return value != a.Value
+	case OperatorLess:
+		return value < a.Value
+	case OperatorLessEqual:
+		return value <= a.Value
+	case OperatorGreater:
+		return value > a.Value
+	case OperatorGreaterEqual:
+		return value >= a.Value
+	}
+
+	return false
+}
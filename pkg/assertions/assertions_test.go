@@ -0,0 +1,121 @@
+/*
+Copyright 2022 Cloudical Deutschland GmbH. All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package assertions
+
+import (
+	"testing"
+
+	"github.com/cloudical-io/ancientt/outputs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	a, err := Parse("bits_per_second  p95 >= 9e9")
+	require.Nil(t, err)
+	assert.Equal(t, "bits_per_second p95 >= 9e9", a.Expression)
+	assert.Equal(t, "bits_per_second", a.Column)
+	assert.Equal(t, AggregationP95, a.Aggregation)
+	assert.Equal(t, OperatorGreaterEqual, a.Operator)
+	assert.Equal(t, 9e9, a.Value)
+
+	for _, expression := range []string{
+		"bits_per_second p95 >=",
+		"bits_per_second p90 >= 1",
+		"bits_per_second p95 => 1",
+		"bits_per_second p95 >= abc",
+	} {
+		_, err := Parse(expression)
+		assert.NotNil(t, err, expression)
+	}
+}
+
+func testData(server string, client string, values ...interface{}) outputs.Data {
+	table := &outputs.Table{
+		Headers: []*outputs.Row{{Value: "server_host"}, {Value: "packet_loss_rate"}},
+		Rows:    [][]*outputs.Row{},
+	}
+	for _, value := range values {
+		table.Rows = append(table.Rows, []*outputs.Row{{Value: server}, {Value: value}})
+	}
+
+	return outputs.Data{
+		Tester:     "pingparsing",
+		ServerHost: server,
+		ClientHost: client,
+		IPFamily:   "v4",
+		Data:       table,
+	}
+}
+
+func TestEvaluator(t *testing.T) {
+	e, err := NewEvaluator([]string{
+		"packet_loss_rate max == 0",
+		"rtt_avg avg < 1",
+	})
+	require.Nil(t, err)
+	require.False(t, e.Empty())
+
+	e.Add(testData("server1", "client1", float64(0), int64(0)))
+	e.Add(testData("server1", "client2", float64(0), 2.5))
+	// Summary data must be ignored
+	summary := testData("server1", "client1", float64(100))
+	summary.Type = outputs.DataTypeSummary
+	e.Add(summary)
+	// The results of the IP families are evaluated separately
+	v6 := testData("server1", "client1", float64(5))
+	v6.IPFamily = "v6"
+	e.Add(v6)
+
+	assert.False(t, e.Evaluate())
+	results := e.Results()
+	require.Equal(t, 6, len(results))
+
+	assert.Equal(t, "client1", results[0].ClientHost)
+	assert.Equal(t, 2, results[0].Count)
+	assert.Equal(t, VerdictPass, results[0].Verdict)
+	// Column not in the results
+	assert.Equal(t, 0, results[1].Count)
+	assert.Equal(t, VerdictFail, results[1].Verdict)
+	assert.Equal(t, "client2", results[2].ClientHost)
+	assert.Equal(t, 2.5, results[2].Actual)
+	assert.Equal(t, VerdictFail, results[2].Verdict)
+	assert.Equal(t, "client1", results[4].ClientHost)
+	assert.Equal(t, "v6", results[4].IPFamily)
+	assert.Equal(t, 1, results[4].Count)
+	assert.Equal(t, VerdictFail, results[4].Verdict)
+
+	data := e.Data()
+	assert.Equal(t, outputs.DataTypeAssertions, data.Type)
+	table, ok := data.Data.(*outputs.Table)
+	require.True(t, ok)
+	assert.Equal(t, 6, len(table.Rows))
+	index, err := table.GetHeaderIndexByName("verdict")
+	require.Nil(t, err)
+	assert.Equal(t, VerdictPass, table.Rows[0][index].Value)
+}
+
+func TestEvaluatorNoData(t *testing.T) {
+	e, err := NewEvaluator([]string{"bits_per_second min > 0"})
+	require.Nil(t, err)
+
+	assert.False(t, e.Evaluate())
+	require.Equal(t, 1, len(e.Results()))
+	assert.Equal(t, VerdictFail, e.Results()[0].Verdict)
+
+	e, err = NewEvaluator(nil)
+	require.Nil(t, err)
+	assert.True(t, e.Empty())
+	assert.True(t, e.Evaluate())
+}
@@ -0,0 +1,178 @@
+/*
+Copyright 2022 Cloudical Deutschland GmbH. All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package assertions
+
+import (
+	"time"
+
+	"github.com/cloudical-io/ancientt/outputs"
+	"github.com/cloudical-io/ancientt/pkg/stats"
+)
+
+const (
+	// VerdictPass the assertion is fulfilled
+	VerdictPass = "pass"
+	// VerdictFail the assertion is violated or there were no values to check it against
+	VerdictFail = "fail"
+)
+
+// Evaluator collects the tester results of a test and evaluates the assertions against them per server / client
+// host pair
+type Evaluator struct {
+	assertions    []*Assertion
+	testStartTime time.Time
+	tester        string
+	collector     *stats.Collector
+	results       []Result
+}
+
+// Result the verdict of an Assertion for a server / client host pair
+type Result struct {
+	Assertion  *Assertion
+	ServerHost string
+	ClientHost string
+	IPFamily   string
+	// Count amount of values the Assertion has been evaluated against
+	Count   int
+	Actual  float64
+	Verdict string
+}
+
+// NewEvaluator parse the given assertion expressions and return an Evaluator for them
+func NewEvaluator(expressions []string) (*Evaluator, error) {
+	e := &Evaluator{
+		assertions: []*Assertion{},
+	}
+
+	columns := []string{}
+	for _, expression := range expressions {
+		a, err := Parse(expression)
+		if err != nil {
+			return nil, err
+		}
+		e.assertions = append(e.assertions, a)
+		columns = append(columns, a.Column)
+	}
+	e.collector = stats.NewCollector(columns...)
+
+	return e, nil
+}
+
+// Empty return true when there are no assertions to evaluate
+func (e *Evaluator) Empty() bool {
+	return len(e.assertions) == 0
+}
+
+// Add collect the values of the asserted columns from the given data, see stats.Collector.Add()
+func (e *Evaluator) Add(data outputs.Data) {
+	if data.Type != outputs.DataTypeResult && data.Type != outputs.DataTypeEnd {
+		return
+	}
+
+	e.testStartTime = data.TestStartTime
+	e.tester = data.Tester
+	e.collector.Add(data)
+}
+
+// Evaluate evaluate each assertion for each server / client host pair and IP family, returns true when all assertions passed
+func (e *Evaluator) Evaluate() bool {
+	e.results = []Result{}
+
+	pairs := e.collector.Pairs()
+	if len(pairs) == 0 {
+		// No data has been received at all, the assertions still need to fail
+		pairs = []stats.Pair{{}}
+	}
+
+	passed := true
+	for _, pair := range pairs {
+		for _, a := range e.assertions {
+			result := Result{
+				Assertion:  a,
+				ServerHost: pair.ServerHost,
+				ClientHost: pair.ClientHost,
+				IPFamily:   pair.IPFamily,
+				Verdict:    VerdictFail,
+			}
+
+			values := e.collector.Values(pair, a.Column)
+			if len(values) > 0 || a.Aggregation == AggregationCount {
+				summary := stats.Summarize(values)
+				result.Count = summary.Count
+				result.Actual = a.Aggregate(summary)
+				if a.Compare(result.Actual) {
+					result.Verdict = VerdictPass
+				}
+			}
+
+			if result.Verdict != VerdictPass {
+				passed = false
+			}
+			e.results = append(e.results, result)
+		}
+	}
+
+	return passed
+}
+
+// Results return the results of the last Evaluate() call
+func (e *Evaluator) Results() []Result {
+	return e.results
+}
+
+// Data return the results of the last Evaluate() call as outputs.Data
+func (e *Evaluator) Data() outputs.Data {
+	table := &outputs.Table{
+		Headers: []*outputs.Row{
+			{Value: "tester"},
+			{Value: "server_host"},
+			{Value: "client_host"},
+			{Value: "ip_family"},
+			{Value: "assertion"},
+			{Value: "column"},
+			{Value: "aggregation"},
+			{Value: "operator"},
+			{Value: "expected"},
+			{Value: "count"},
+			{Value: "actual"},
+			{Value: "verdict"},
+		},
+		Rows: [][]*outputs.Row{},
+	}
+
+	for _, result := range e.results {
+		table.Rows = append(table.Rows, []*outputs.Row{
+			{Value: e.tester},
+			{Value: result.ServerHost},
+			{Value: result.ClientHost},
+			{Value: result.IPFamily},
+			{Value: result.Assertion.Expression},
+			{Value: result.Assertion.Column},
+			{Value: string(result.Assertion.Aggregation)},
+			{Value: string(result.Assertion.Operator)},
+			{Value: result.Assertion.Value},
+			{Value: result.Count},
+			{Value: result.Actual},
+			{Value: result.Verdict},
+		})
+	}
+
+	return outputs.Data{
+		TestStartTime: e.testStartTime,
+		TestTime:      time.Now(),
+		Type:          outputs.DataTypeAssertions,
+		Tester:        e.tester,
+		Data:          table,
+	}
+}
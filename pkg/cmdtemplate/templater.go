@@ -15,8 +15,10 @@ package cmdtemplate
 
 import (
 	"bytes"
+	"fmt"
 	"text/template"
 
+	"github.com/cloudical-io/ancientt/pkg/config"
 	"github.com/cloudical-io/ancientt/testers"
 )
 
@@ -27,8 +29,20 @@ type Variables struct {
 	ServerPort      int32
 }
 
-// Template template a given cmd and args with the given host information struct
+// Template template a given cmd and args with the given host information struct, an error is returned when the server
+// has no address for the IP family of the task
 func Template(task *testers.Task, variables Variables) error {
+	switch task.IPFamily {
+	case config.IPFamilyV4:
+		if variables.ServerAddressV4 == "" {
+			return fmt.Errorf("server has no IPv4 address")
+		}
+	case config.IPFamilyV6:
+		if variables.ServerAddressV6 == "" {
+			return fmt.Errorf("server has no IPv6 address")
+		}
+	}
+
 	templatedArgs := []string{}
 
 	var err error
@@ -16,6 +16,7 @@ package cmdtemplate
 import (
 	"testing"
 
+	"github.com/cloudical-io/ancientt/pkg/config"
 	"github.com/cloudical-io/ancientt/testers"
 	"github.com/stretchr/testify/assert"
 )
@@ -26,3 +27,28 @@ func TestTemplate(t *testing.T) {
 	err := Template(task, variables)
 	assert.Nil(t, err)
 }
+
+func TestTemplateIPFamily(t *testing.T) {
+	variables := Variables{
+		ServerAddressV4: "192.0.2.1",
+		ServerPort:      5601,
+	}
+
+	task := &testers.Task{
+		Args:     []string{"--client={{ .ServerAddressV4 }}", "--port={{ .ServerPort }}"},
+		IPFamily: config.IPFamilyV4,
+	}
+	assert.Nil(t, Template(task, variables))
+	assert.Equal(t, []string{"--client=192.0.2.1", "--port=5601"}, task.Args)
+
+	// The server has no IPv6 address
+	task = &testers.Task{
+		Args:     []string{"--client={{ .ServerAddressV6 }}"},
+		IPFamily: config.IPFamilyV6,
+	}
+	assert.NotNil(t, Template(task, variables))
+
+	variables.ServerAddressV6 = "2001:db8::1"
+	assert.Nil(t, Template(task, variables))
+	assert.Equal(t, []string{"--client=2001:db8::1"}, task.Args)
+}
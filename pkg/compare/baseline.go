@@ -0,0 +1,168 @@
+/*
+Copyright 2022 Cloudical Deutschland GmbH. All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package compare
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+	"strconv"
+	"unicode/utf8"
+
+	"github.com/cloudical-io/ancientt/pkg/config"
+	"github.com/cloudical-io/ancientt/pkg/stats"
+	"github.com/cloudical-io/ancientt/pkg/util"
+	"github.com/jmoiron/sqlx"
+
+	// Include sqlite driver for reading sqlite baselines
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// loadBaseline load the given columns from the baseline file into a stats.Collector
+func loadBaseline(cfg config.CompareBaseline, columns []string) (*stats.Collector, error) {
+	switch cfg.Format {
+	case config.CompareBaselineFormatCSV:
+		return loadCSVBaseline(cfg, columns)
+	case config.CompareBaselineFormatSQLite:
+		return loadSQLiteBaseline(cfg, columns)
+	}
+
+	return nil, fmt.Errorf("unknown baseline format %s", cfg.Format)
+}
+
+func loadCSVBaseline(cfg config.CompareBaseline, columns []string) (*stats.Collector, error) {
+	file, err := os.Open(cfg.FilePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open csv baseline. %+v", err)
+	}
+	defer file.Close()
+
+	reader := csv.NewReader(file)
+	separator, _ := utf8.DecodeRuneInString(cfg.Separator)
+	reader.Comma = separator
+	// A name pattern without the data type mixes the tester result rows with the rows of the other tables, the rows
+	// not matching the headers of the file are skipped when collecting the baseline
+	reader.FieldsPerRecord = -1
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read csv baseline. %+v", err)
+	}
+	if len(records) == 0 {
+		return nil, fmt.Errorf("csv baseline %s is empty", cfg.FilePath)
+	}
+
+	rows := [][]interface{}{}
+	for _, record := range records[1:] {
+		row := []interface{}{}
+		for _, cell := range record {
+			row = append(row, cell)
+		}
+		rows = append(rows, row)
+	}
+
+	return collectBaseline(records[0], rows, columns)
+}
+
+func loadSQLiteBaseline(cfg config.CompareBaseline, columns []string) (*stats.Collector, error) {
+	if cfg.TableName == "" {
+		return nil, fmt.Errorf("no table name given for sqlite baseline")
+	}
+	if _, err := os.Stat(cfg.FilePath); err != nil {
+		return nil, fmt.Errorf("failed to open sqlite baseline. %+v", err)
+	}
+
+	db, err := sqlx.Connect("sqlite3", cfg.FilePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open sqlite baseline. %+v", err)
+	}
+	defer db.Close()
+
+	result, err := db.Queryx(fmt.Sprintf("SELECT * FROM `%s`", cfg.TableName))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query sqlite baseline table %s. %+v", cfg.TableName, err)
+	}
+	defer result.Close()
+
+	headers, err := result.Columns()
+	if err != nil {
+		return nil, err
+	}
+
+	rows := [][]interface{}{}
+	for result.Next() {
+		row, err := result.SliceScan()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read row of sqlite baseline table %s. %+v", cfg.TableName, err)
+		}
+		rows = append(rows, row)
+	}
+	if err := result.Err(); err != nil {
+		return nil, err
+	}
+
+	return collectBaseline(headers, rows, columns)
+}
+
+// collectBaseline add the values of the columns per server / client host pair and IP family to a new stats.Collector
+func collectBaseline(headers []string, rows [][]interface{}, columns []string) (*stats.Collector, error) {
+	indexes := map[string]int{}
+	for i, header := range headers {
+		indexes[header] = i
+	}
+	for _, column := range append([]string{"server_host", "client_host"}, columns...) {
+		if _, ok := indexes[column]; !ok {
+			return nil, fmt.Errorf("column %s not found in baseline", column)
+		}
+	}
+
+	collector := stats.NewCollector(columns...)
+	for _, row := range rows {
+		if len(row) != len(headers) {
+			continue
+		}
+		pair := stats.Pair{
+			ServerHost: baselineString(row[indexes["server_host"]]),
+			ClientHost: baselineString(row[indexes["client_host"]]),
+			// Baselines without the `ip_family` column have only been run with IPv4
+			IPFamily: string(config.IPFamilyV4),
+		}
+		if index, ok := indexes["ip_family"]; ok && baselineString(row[index]) != "" {
+			pair.IPFamily = baselineString(row[index])
+		}
+		for _, column := range columns {
+			value, err := baselineFloat64(row[indexes[column]])
+			if err != nil {
+				continue
+			}
+			collector.AddValues(pair, column, value)
+		}
+	}
+
+	return collector, nil
+}
+
+func baselineString(in interface{}) string {
+	if b, ok := in.([]byte); ok {
+		return string(b)
+	}
+	return util.CastToString(in)
+}
+
+func baselineFloat64(in interface{}) (float64, error) {
+	if value, err := util.CastNumberToFloat64(in); err == nil {
+		return value, nil
+	}
+	return strconv.ParseFloat(baselineString(in), 64)
+}
@@ -0,0 +1,256 @@
+/*
+Copyright 2022 Cloudical Deutschland GmbH. All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package compare
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/cloudical-io/ancientt/outputs"
+	"github.com/cloudical-io/ancientt/pkg/config"
+	"github.com/cloudical-io/ancientt/pkg/stats"
+)
+
+const (
+	// StatusOK the metric of the pair is within the allowed change
+	StatusOK = "ok"
+	// StatusRegression the metric of the pair changed beyond the allowed change
+	StatusRegression = "regression"
+	// StatusNoBaseline the pair has no values in the baseline
+	StatusNoBaseline = "no_baseline"
+	// StatusMissing the pair has values in the baseline but not in the current run
+	StatusMissing = "missing"
+)
+
+// DefaultMetrics return the metrics compared by default for the given tester
+func DefaultMetrics(tester string) []*config.CompareMetric {
+	switch strings.ToLower(tester) {
+	case "iperf3":
+		return []*config.CompareMetric{
+			{Column: "bits_per_second", Type: config.CompareMetricTypeThroughput, Aggregation: "avg"},
+		}
+	case "pingparsing":
+		return []*config.CompareMetric{
+			{Column: "rtt_avg", Type: config.CompareMetricTypeLatency, Aggregation: "avg"},
+		}
+	}
+
+	return []*config.CompareMetric{}
+}
+
+// Comparer collects the tester results of a test and compares them per server / client host pair against a baseline
+type Comparer struct {
+	config        *config.Compare
+	metrics       []*config.CompareMetric
+	testStartTime time.Time
+	tester        string
+	baseline      *stats.Collector
+	current       *stats.Collector
+	results       []Result
+}
+
+// Result comparison result of a metric for a server / client host pair
+type Result struct {
+	Metric           *config.CompareMetric
+	ServerHost       string
+	ClientHost       string
+	IPFamily         string
+	Baseline         float64
+	Current          float64
+	ChangePercent    float64
+	MaxChangePercent float64
+	Status           string
+}
+
+// New return a new Comparer for the test with the baseline loaded
+func New(test *config.Test) (*Comparer, error) {
+	if test.Compare == nil {
+		return nil, fmt.Errorf("no compare options given for test %s", test.Name)
+	}
+
+	metrics := test.Compare.Metrics
+	if len(metrics) == 0 {
+		metrics = DefaultMetrics(test.Type)
+	}
+	if len(metrics) == 0 {
+		return nil, fmt.Errorf("no compare metrics given for test %s and no default metrics for tester %s", test.Name, test.Type)
+	}
+
+	columns := []string{}
+	for _, metric := range metrics {
+		if metric.Type != config.CompareMetricTypeThroughput && metric.Type != config.CompareMetricTypeLatency {
+			return nil, fmt.Errorf("unknown compare metric type %q for column %s of test %s", metric.Type, metric.Column, test.Name)
+		}
+		columns = append(columns, metric.Column)
+	}
+
+	baseline, err := loadBaseline(test.Compare.Baseline, columns)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load baseline for test %s. %+v", test.Name, err)
+	}
+
+	return &Comparer{
+		config:   test.Compare,
+		metrics:  metrics,
+		baseline: baseline,
+		current:  stats.NewCollector(columns...),
+	}, nil
+}
+
+// Add collect the values of the compared metrics from the given data, see stats.Collector.Add()
+func (c *Comparer) Add(data outputs.Data) {
+	if data.Type != outputs.DataTypeResult && data.Type != outputs.DataTypeEnd {
+		return
+	}
+
+	c.testStartTime = data.TestStartTime
+	c.tester = data.Tester
+	c.current.Add(data)
+}
+
+// Compare compare each metric for each server / client host pair against the baseline, returns true when no
+// regression has been found
+func (c *Comparer) Compare() bool {
+	c.results = []Result{}
+
+	pairs := c.current.Pairs()
+	seen := map[stats.Pair]bool{}
+	for _, pair := range pairs {
+		seen[pair] = true
+	}
+	for _, pair := range c.baseline.Pairs() {
+		if !seen[pair] {
+			pairs = append(pairs, pair)
+		}
+	}
+
+	passed := true
+	for _, pair := range pairs {
+		for _, metric := range c.metrics {
+			result := c.compareMetric(pair, metric)
+			if result.Status == StatusRegression {
+				passed = false
+			}
+			c.results = append(c.results, result)
+		}
+	}
+
+	return passed
+}
+
+func (c *Comparer) compareMetric(pair stats.Pair, metric *config.CompareMetric) Result {
+	result := Result{
+		Metric:           metric,
+		ServerHost:       pair.ServerHost,
+		ClientHost:       pair.ClientHost,
+		IPFamily:         pair.IPFamily,
+		MaxChangePercent: *c.config.MaxChangePercent,
+	}
+	if metric.MaxChangePercent != nil {
+		result.MaxChangePercent = *metric.MaxChangePercent
+	}
+
+	baselineValues := c.baseline.Values(pair, metric.Column)
+	currentValues := c.current.Values(pair, metric.Column)
+	if len(baselineValues) == 0 {
+		result.Status = StatusNoBaseline
+	} else if len(currentValues) == 0 {
+		result.Status = StatusMissing
+	}
+	if result.Status != "" {
+		return result
+	}
+
+	result.Baseline, _ = stats.Summarize(baselineValues).Get(metric.Aggregation)
+	result.Current, _ = stats.Summarize(currentValues).Get(metric.Aggregation)
+	result.ChangePercent = changePercent(result.Baseline, result.Current)
+
+	result.Status = StatusOK
+	switch metric.Type {
+	case config.CompareMetricTypeThroughput:
+		if result.ChangePercent < -result.MaxChangePercent {
+			result.Status = StatusRegression
+		}
+	case config.CompareMetricTypeLatency:
+		if result.ChangePercent > result.MaxChangePercent {
+			result.Status = StatusRegression
+		}
+	}
+
+	return result
+}
+
+// changePercent return the change from baseline to current in percent of the baseline
+func changePercent(baseline float64, current float64) float64 {
+	if baseline == current {
+		return 0
+	}
+	if baseline == 0 {
+		return math.Copysign(math.Inf(1), current)
+	}
+	return (current - baseline) / math.Abs(baseline) * 100
+}
+
+// Results return the results of the last Compare() call
+func (c *Comparer) Results() []Result {
+	return c.results
+}
+
+// Data return the results of the last Compare() call as regression report outputs.Data
+func (c *Comparer) Data() outputs.Data {
+	table := &outputs.Table{
+		Headers: []*outputs.Row{
+			{Value: "tester"},
+			{Value: "server_host"},
+			{Value: "client_host"},
+			{Value: "ip_family"},
+			{Value: "column"},
+			{Value: "type"},
+			{Value: "aggregation"},
+			{Value: "baseline"},
+			{Value: "current"},
+			{Value: "change_percent"},
+			{Value: "max_change_percent"},
+			{Value: "status"},
+		},
+		Rows: [][]*outputs.Row{},
+	}
+
+	for _, result := range c.results {
+		table.Rows = append(table.Rows, []*outputs.Row{
+			{Value: c.tester},
+			{Value: result.ServerHost},
+			{Value: result.ClientHost},
+			{Value: result.IPFamily},
+			{Value: result.Metric.Column},
+			{Value: string(result.Metric.Type)},
+			{Value: result.Metric.Aggregation},
+			{Value: result.Baseline},
+			{Value: result.Current},
+			{Value: result.ChangePercent},
+			{Value: result.MaxChangePercent},
+			{Value: result.Status},
+		})
+	}
+
+	return outputs.Data{
+		TestStartTime: c.testStartTime,
+		TestTime:      time.Now(),
+		Type:          outputs.DataTypeRegressions,
+		Tester:        c.tester,
+		Data:          table,
+	}
+}
@@ -0,0 +1,193 @@
+/*
+Copyright 2022 Cloudical Deutschland GmbH. All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package compare
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/cloudical-io/ancientt/outputs"
+	"github.com/cloudical-io/ancientt/pkg/config"
+	"github.com/cloudical-io/ancientt/pkg/util"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testCSVBaseline = `test_time;round;tester;server_host;client_host;bits_per_second
+2022-01-01 00:00:00;0;iperf3;server1;client1;1000.000000
+2022-01-01 00:00:00;0;iperf3;server1;client1;1000.000000
+2022-01-01 00:00:00;0;iperf3;server1;client2;1000.000000
+2022-01-01 00:00:00;0;iperf3;server1;client3;1000.000000
+`
+
+func testData(client string, values ...float64) outputs.Data {
+	table := &outputs.Table{
+		Headers: []*outputs.Row{{Value: "bits_per_second"}},
+		Rows:    [][]*outputs.Row{},
+	}
+	for _, value := range values {
+		table.Rows = append(table.Rows, []*outputs.Row{{Value: value}})
+	}
+
+	return outputs.Data{
+		Tester:     "iperf3",
+		ServerHost: "server1",
+		ClientHost: client,
+		IPFamily:   "v4",
+		Data:       table,
+	}
+}
+
+func testCompare(baseline config.CompareBaseline) *config.Test {
+	return &config.Test{
+		Name: "test",
+		Type: "iperf3",
+		Compare: &config.Compare{
+			Baseline:         baseline,
+			MaxChangePercent: util.FloatPointer(10),
+		},
+	}
+}
+
+func TestCompareCSV(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "baseline.csv")
+	require.Nil(t, ioutil.WriteFile(path, []byte(testCSVBaseline), 0644))
+
+	c, err := New(testCompare(config.CompareBaseline{
+		Format:    config.CompareBaselineFormatCSV,
+		FilePath:  path,
+		Separator: ";",
+	}))
+	require.Nil(t, err)
+
+	c.Add(testData("client1", 950, 950))
+	c.Add(testData("client2", 800, 850))
+	c.Add(testData("client4", 1000))
+	// The baseline has only been run with IPv4
+	v6 := testData("client1", 500)
+	v6.IPFamily = "v6"
+	c.Add(v6)
+
+	assert.False(t, c.Compare())
+	results := c.Results()
+	require.Equal(t, 5, len(results))
+
+	assert.Equal(t, "client1", results[0].ClientHost)
+	assert.Equal(t, float64(1000), results[0].Baseline)
+	assert.Equal(t, float64(950), results[0].Current)
+	assert.Equal(t, float64(-5), results[0].ChangePercent)
+	assert.Equal(t, StatusOK, results[0].Status)
+	assert.Equal(t, "client2", results[1].ClientHost)
+	assert.Equal(t, StatusRegression, results[1].Status)
+	assert.Equal(t, "client4", results[2].ClientHost)
+	assert.Equal(t, StatusNoBaseline, results[2].Status)
+	assert.Equal(t, "client1", results[3].ClientHost)
+	assert.Equal(t, "v6", results[3].IPFamily)
+	assert.Equal(t, StatusNoBaseline, results[3].Status)
+	assert.Equal(t, "client3", results[4].ClientHost)
+	assert.Equal(t, StatusMissing, results[4].Status)
+
+	data := c.Data()
+	assert.Equal(t, outputs.DataTypeRegressions, data.Type)
+	table, ok := data.Data.(*outputs.Table)
+	require.True(t, ok)
+	assert.Equal(t, 5, len(table.Rows))
+}
+
+func TestCompareSQLite(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "baseline.sqlite3")
+	db, err := sqlx.Connect("sqlite3", path)
+	require.Nil(t, err)
+	db.MustExec("CREATE TABLE `results` (`server_host` TEXT, `client_host` TEXT, `ip_family` TEXT, `rtt_avg` FLOAT)")
+	db.MustExec("INSERT INTO `results` VALUES ('server1', 'client1', 'v4', 1.0), ('server1', 'client1', 'v4', 3.0), ('server1', 'client1', 'v6', 20.0)")
+	require.Nil(t, db.Close())
+
+	test := testCompare(config.CompareBaseline{
+		Format:    config.CompareBaselineFormatSQLite,
+		FilePath:  path,
+		TableName: "results",
+	})
+	test.Type = "pingparsing"
+	c, err := New(test)
+	require.Nil(t, err)
+
+	data := testData("client1", 2.1)
+	data.Data.(*outputs.Table).Headers[0].Value = "rtt_avg"
+	c.Add(data)
+
+	assert.True(t, c.Compare())
+	require.Equal(t, 2, len(c.Results()))
+	assert.Equal(t, float64(2), c.Results()[0].Baseline)
+	assert.InDelta(t, 5, c.Results()[0].ChangePercent, 0.0001)
+
+	// Latency rising beyond the allowed change is a regression
+	c.Add(testData("client1"))
+	c.Add(data)
+	c.Add(data)
+	data.Data.(*outputs.Table).Rows[0][0].Value = 9.0
+	c.Add(data)
+	assert.False(t, c.Compare())
+	assert.Equal(t, StatusRegression, c.Results()[0].Status)
+	assert.Equal(t, "v6", c.Results()[1].IPFamily)
+	assert.Equal(t, StatusMissing, c.Results()[1].Status)
+}
+
+func TestCompareCSVMixedRows(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "baseline.csv")
+	baseline := testCSVBaseline + "2022-01-01 00:00:00;0;iperf3;server1;client1;sender;1000.000000;0\n"
+	require.Nil(t, ioutil.WriteFile(path, []byte(baseline), 0644))
+
+	c, err := New(testCompare(config.CompareBaseline{
+		Format:    config.CompareBaselineFormatCSV,
+		FilePath:  path,
+		Separator: ";",
+	}))
+	require.Nil(t, err)
+
+	// The row with more fields than the headers doesn't belong to the baseline
+	c.Add(testData("client1", 1000))
+	c.Compare()
+	results := c.Results()
+	require.NotEqual(t, 0, len(results))
+	assert.Equal(t, "client1", results[0].ClientHost)
+	assert.Equal(t, float64(1000), results[0].Baseline)
+	assert.Equal(t, StatusOK, results[0].Status)
+}
+
+func TestNewErrors(t *testing.T) {
+	_, err := New(&config.Test{Name: "test", Type: "iperf3"})
+	assert.NotNil(t, err)
+
+	path := filepath.Join(t.TempDir(), "baseline.csv")
+	require.Nil(t, ioutil.WriteFile(path, []byte("server_host;client_host;rtt\n"), 0644))
+	_, err = New(testCompare(config.CompareBaseline{
+		Format:    config.CompareBaselineFormatCSV,
+		FilePath:  path,
+		Separator: ";",
+	}))
+	assert.NotNil(t, err)
+
+	// Unknown metric types would never be flagged as a regression
+	require.Nil(t, ioutil.WriteFile(path, []byte(testCSVBaseline), 0644))
+	test := testCompare(config.CompareBaseline{
+		Format:    config.CompareBaselineFormatCSV,
+		FilePath:  path,
+		Separator: ";",
+	})
+	test.Compare.Metrics = []*config.CompareMetric{{Column: "bits_per_second", Type: "bandwidth"}}
+	_, err = New(test)
+	assert.NotNil(t, err)
+}
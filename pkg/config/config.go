@@ -26,7 +26,7 @@ type Config struct {
 	// Runner Runner configuration to use.
 	Runner Runner `yaml:"runner"`
 	// Tests List of `Test`s to run.
-	Tests []*Test `yaml:"tests" validate:"required,min=1"`
+	Tests []*Test `yaml:"tests" validate:"required,min=1,dive"`
 }
 
 // New return a new Config object with the `Version` set by default
@@ -48,11 +48,25 @@ type Hosts struct {
 	Random *bool `yaml:"random,omitempty"`
 	// Must be used with `Random`, will cause `Count` times Nodes to be randomly selected from all applicable hosts.
 	Count int `yaml:"count"`
+	// Seed for the `Random` selection of hosts, the same seed selects the same hosts from the same hosts list. When not
+	// set a new seed is used for each run, the seed used is shown in the plan and the outputs to re-run a selection.
+	Seed *int64 `yaml:"seed,omitempty"`
 	// Static list of hosts (this list is not checked for accuracy)
 	Hosts []string `yaml:"hosts"`
 	// "Label" selector for the dynamically generated hosts list, e.g., Kubernetes label selector
 	HostSelector map[string]string `yaml:"hostSelector"`
-	// AntiAffinity **not implemented yet**
+	// LabelSelector Kubernetes label selector syntax for the labels of the hosts, e.g.,
+	// `topology.kubernetes.io/zone in (zone-a, zone-b), !node-role.kubernetes.io/control-plane, rack != 3`.
+	// Supported are `=`, `==`, `!=`, `in`, `notin`, `KEY` (exists) and `!KEY` (does not exist).
+	LabelSelector string `yaml:"labelSelector,omitempty"`
+	// MatchExpressions label selector requirements which all must match, like in Kubernetes label selectors
+	MatchExpressions []*LabelSelectorRequirement `yaml:"matchExpressions,omitempty" validate:"dive"`
+	// Exclude host names or patterns (e.g., `master-*`, see Golang `path.Match()`) of hosts not to select, applies
+	// to the static list of hosts as well
+	Exclude []string `yaml:"exclude,omitempty"`
+	// AntiAffinity labels of which no two selected hosts may have the same value, e.g., to spread the hosts over zones
+	// or racks. Hosts without all of the labels are not selected. With `Random`, `Count` hosts are selected randomly
+	// and an error is returned when there are not enough hosts with distinct label values.
 	AntiAffinity []string `yaml:"antiAffinity,omitempty"`
 }
 
@@ -66,12 +80,26 @@ type Output struct {
 	GoChart *GoChart `yaml:"goChart"`
 	// Dump output options
 	Dump *Dump `yaml:"dump"`
+	// JSON output options
+	JSON *JSON `yaml:"json"`
 	// Excelize output options
 	Excelize *Excelize `yaml:"excelize"`
+	// HTML output options
+	HTML *HTML `yaml:"html"`
+	// Markdown output options
+	Markdown *Markdown `yaml:"markdown"`
 	// SQLite output options
 	SQLite *SQLite `yaml:"sqlite"`
 	// MySQL output options
 	MySQL *MySQL `yaml:"mysql"`
+	// Postgres output options
+	Postgres *Postgres `yaml:"postgres"`
+	// Prometheus output options
+	Prometheus *Prometheus `yaml:"prometheus"`
+	// InfluxDB output options
+	InfluxDB *InfluxDB `yaml:"influxdb"`
+	// JUnit output options
+	JUnit *JUnit `yaml:"junit"`
 	// Transformations transformations to be applied to the output data for the chosen output
 	Transformations []*Transformation `yaml:"transformations,omitempty"`
 }
@@ -80,7 +108,10 @@ type Output struct {
 type FilePath struct {
 	// File base path for output
 	FilePath string `yaml:"filePath" validate:"required,min=1"`
-	// File name pattern templated from various availables during output generation
+	// File name pattern templated from various availables during output generation.
+	// Testers can return more than one type of data (e.g., iperf3 interval and end results), use `{{ .Data.Type }}` in
+	// the pattern to not mix them up in the same file. The table outputs (CSV, Excelize, SQLite, MySQL and PostgreSQL)
+	// only write the tester results when their name patterns don't contain the data type.
 	NamePattern string `yaml:"namePattern" validate:"required,min=1"`
 }
 
@@ -176,6 +207,87 @@ type Dump struct {
 	FilePath `yaml:",inline"`
 }
 
+// HTML HTML Output config options, the report is written when the test is done and contains the plan, hosts, the
+// status of the hosts, the summary, end results and charts per server and client host pair
+type HTML struct {
+	// FilePath struct fields which are inherited by this struct.
+	// The fields of the FilePath struct must be written directly to this struct.
+	FilePath `yaml:",inline"`
+	// Title of the report (default: `ancientt report`)
+	Title string `yaml:"title,omitempty"`
+	// Charts to embed per server and client host pair, charts for columns which don't exist in the data are skipped (default: `bits_per_second` over `start` and `time` over `icmp_seq`)
+	Charts []*HTMLChart `yaml:"charts,omitempty"`
+}
+
+// HTMLChart columns of a chart in the HTML report
+type HTMLChart struct {
+	// TimeColumn column with the time / interval to use for the X-axis
+	TimeColumn string `yaml:"timeColumn" validate:"required"`
+	// Column name of the column to use for the Y-axis
+	Column string `yaml:"column" validate:"required"`
+}
+
+// JUnit JUnit XML output options
+type JUnit struct {
+	// FilePath struct fields which are inherited by this struct.
+	// The fields of the FilePath struct must be written directly to this struct.
+	FilePath `yaml:",inline"`
+	// SuiteName name of the test suite (default: name of the test)
+	SuiteName string `yaml:"suiteName,omitempty"`
+}
+
+// Markdown Markdown output options
+type Markdown struct {
+	// FilePath struct fields which are inherited by this struct.
+	// The fields of the FilePath struct must be written directly to this struct.
+	FilePath `yaml:",inline"`
+	// Title of the summary (default: `ancientt summary`)
+	Title string `yaml:"title,omitempty"`
+	// Columns to aggregate per test and per server and client host pair, columns which don't exist in the data are
+	// skipped (default: `bits_per_second` in `Gbit/s`, `retransmits` and `time` in `ms`)
+	Columns []*MarkdownColumn `yaml:"columns,omitempty" validate:"dive"`
+	// Aggregations to show for each column, available are `count`, `min`, `avg`, `max`, `p50`, `p95` and `p99`
+	// (default: `min`, `avg`, `max` and `p95`)
+	Aggregations []string `yaml:"aggregations,omitempty" validate:"dive,oneof=count min avg max p50 p95 p99"`
+	// OutputFiles list the files of the other outputs of the test (default: `true`)
+	OutputFiles *bool `yaml:"outputFiles,omitempty"`
+}
+
+// MarkdownColumn column to aggregate and how to format its values
+type MarkdownColumn struct {
+	// Name of the column
+	Name string `yaml:"name" validate:"required"`
+	// Title to show instead of the column name
+	Title string `yaml:"title,omitempty"`
+	// Unit to show next to the title, for bit/s (e.g., `Mbit/s`, `Gbit/s`) and byte units (e.g., `MB`, `GiB`) the values
+	// are converted automatically when no divisor is set
+	Unit string `yaml:"unit,omitempty"`
+	// Divisor the values are divided by before formatting (e.g., `1e9` to get from bits_per_second to Gbit/s)
+	Divisor float64 `yaml:"divisor,omitempty"`
+	// Precision number of decimal places (default: `2`)
+	Precision *int `yaml:"precision,omitempty" validate:"omitempty,min=0"`
+}
+
+// JSONFormat format of the JSON output files
+type JSONFormat string
+
+const (
+	// JSONFormatArray one JSON array with all records per file
+	JSONFormatArray JSONFormat = "array"
+	// JSONFormatNDJSON newline delimited JSON, one record per line
+	JSONFormatNDJSON JSONFormat = "ndjson"
+)
+
+// JSON JSON Output config options, each row of the data is written as a record with the test metadata and the row's
+// values keyed by the header names
+type JSON struct {
+	// FilePath struct fields which are inherited by this struct.
+	// The fields of the FilePath struct must be written directly to this struct.
+	FilePath `yaml:",inline"`
+	// Format of the files, `array` for one JSON array per file or `ndjson` for one record per line (default: `array`)
+	Format JSONFormat `yaml:"format,omitempty" validate:"omitempty,oneof=array ndjson"`
+}
+
 // Excelize Excelize Output config options. TODO implement
 type Excelize struct {
 	// FilePath struct fields which are inherited by this struct.
@@ -204,6 +316,96 @@ type MySQL struct {
 	AutoCreateTables *bool `yaml:"autoCreateTables,omitempty"`
 }
 
+// Postgres PostgreSQL Output config options
+type Postgres struct {
+	// PostgreSQL DSN, format `postgres://[username[:password]@]host[:port]/dbname[?param1=value1&...&paramN=valueN]`, for more information see [pkg.go.dev lib/pq - Connection String Parameters](https://pkg.go.dev/github.com/lib/pq#hdr-Connection_String_Parameters)
+	DSN string `yaml:"dsn"`
+	// Pattern used for templating the name of the table used in the PostgreSQL database, the tables are created automatically when Postgres.AutoCreateTables is set to `true` (default: `ancientt_{{ .Data.Tester }}{{ with .Data.Type }}_{{ . }}{{ end }}`)
+	TableNamePattern string `yaml:"tableNamePattern"`
+	// Automatically create tables in the PostgreSQL database (default: `true`)
+	AutoCreateTables *bool `yaml:"autoCreateTables,omitempty"`
+	// Column types to use when creating tables, mapping the column name to the PostgreSQL type. The types of the other columns are detected from the first row of data
+	ColumnTypes map[string]string `yaml:"columnTypes,omitempty"`
+	// Column with the time of the test, its values are stored as `TIMESTAMPTZ` (default: `test_time`)
+	TimeColumn string `yaml:"timeColumn,omitempty"`
+	// TimescaleDB options
+	TimescaleDB *PostgresTimescaleDB `yaml:"timescaleDB,omitempty"`
+}
+
+// PostgresTimescaleDB TimescaleDB options for the PostgreSQL output
+type PostgresTimescaleDB struct {
+	// Create the tables as TimescaleDB hypertables partitioned by the Postgres.TimeColumn, the `timescaledb` extension must exist in the database. Tables without the time column (e.g., assertions) are created as normal tables (default: `false`)
+	Enabled *bool `yaml:"enabled,omitempty"`
+	// Time interval covered by each chunk of the hypertables, e.g., `1 day`, uses the TimescaleDB default when empty
+	ChunkTimeInterval string `yaml:"chunkTimeInterval,omitempty"`
+}
+
+// Prometheus Prometheus Output config options, the numeric columns of the data are converted into gauges with the
+// labels `tester`, `server_host`, `client_host`, `ip_family` and `round`. The host and IP family labels are taken from
+// the columns of the rows when the data has them (e.g., summary data), otherwise from the data. When multiple rows result
+// in the same labels, the value of the last row is used (e.g., the last interval of the iperf3 interval results).
+type Prometheus struct {
+	// Write the metrics to a file for the node_exporter textfile collector, the name pattern should end with `.prom` (default name pattern: `ancientt-{{ .Data.Tester }}.prom`)
+	TextFile *FilePath `yaml:"textFile,omitempty"`
+	// Push the metrics to a Prometheus Pushgateway
+	Pushgateway *PrometheusPushgateway `yaml:"pushgateway,omitempty"`
+	// Prefix for the metric names, the metric name is built from the prefix, the data type (e.g., `end`, `summary`) and the column / metric name (default: `ancientt`)
+	MetricPrefix string `yaml:"metricPrefix,omitempty"`
+	// Columns to convert into gauges, mapping the column name to the metric name. When no columns are given, all numeric columns are converted using the column name as the metric name
+	Columns map[string]string `yaml:"columns,omitempty"`
+	// Labels additional labels to add to the gauges, mapping the label name to the column name to take the value from (default: `metric: metric`, for the metric of the summary data)
+	Labels map[string]string `yaml:"labels,omitempty"`
+}
+
+// PrometheusPushgateway Prometheus Pushgateway options
+type PrometheusPushgateway struct {
+	// URL of the Pushgateway, e.g., `http://pushgateway.example.com:9091`
+	URL string `yaml:"url" validate:"required,url"`
+	// Job name the metrics are pushed for (default: `ancientt`)
+	Job string `yaml:"job,omitempty"`
+	// Grouping additional grouping labels for the pushed metrics
+	Grouping map[string]string `yaml:"grouping,omitempty"`
+	// Timeout for pushing the metrics (default: `10s`)
+	Timeout time.Duration `yaml:"timeout,omitempty"`
+}
+
+// InfluxDB InfluxDB Output config options, each row of the data is converted into a point in the InfluxDB line
+// protocol. The tag columns become tags, all other numeric and boolean columns become fields.
+type InfluxDB struct {
+	// Write the line protocol to a file (default name pattern: `ancientt-{{ .TestStartTime }}-{{ .Data.Tester }}.lp`)
+	File *FilePath `yaml:"file,omitempty"`
+	// Write the points to the InfluxDB v2 HTTP API
+	HTTP *InfluxDBHTTP `yaml:"http,omitempty"`
+	// Pattern used for templating the measurement name (default: `ancientt_{{ .Data.Tester }}{{ with .Data.Type }}_{{ . }}{{ end }}`)
+	MeasurementPattern string `yaml:"measurementPattern,omitempty"`
+	// Columns which are used as tags, columns which don't exist in the data are ignored (default: `tester`, `server_host`, `client_host`, `round`, `socket`, `metric`)
+	Tags []string `yaml:"tags,omitempty"`
+	// Column with the time of the test used as the timestamp, the test time of the data is used when the column doesn't exist (default: `test_time`)
+	TimeColumn string `yaml:"timeColumn,omitempty"`
+	// Column with the start of the interval in seconds, which is added to the timestamp so each interval gets its own timestamp (default: `start`)
+	IntervalColumn string `yaml:"intervalColumn,omitempty"`
+}
+
+// InfluxDBHTTP InfluxDB v2 HTTP API write options
+type InfluxDBHTTP struct {
+	// URL of the InfluxDB, e.g., `http://influxdb.example.com:8086`, the points are written to the `/api/v2/write` endpoint
+	URL string `yaml:"url" validate:"required,url"`
+	// Organization the bucket belongs to
+	Org string `yaml:"org" validate:"required"`
+	// Bucket to write the points to
+	Bucket string `yaml:"bucket" validate:"required"`
+	// API token used for authentication
+	Token string `yaml:"token,omitempty"`
+	// Maximum amount of points written per request (default: `5000`)
+	BatchSize int `yaml:"batchSize,omitempty" validate:"omitempty,min=1"`
+	// Retries for failed write requests, requests are retried on connection errors, `429` and `5xx` responses (default: `3`)
+	Retries *int `yaml:"retries,omitempty" validate:"omitempty,min=0"`
+	// Interval to wait between retries, a `Retry-After` header of the response takes precedence (default: `1s`)
+	RetryInterval time.Duration `yaml:"retryInterval,omitempty"`
+	// Timeout for each write request (default: `10s`)
+	Timeout time.Duration `yaml:"timeout,omitempty"`
+}
+
 // Runner structure with all available runners config options
 type Runner struct {
 	// Name of the runner
@@ -212,6 +414,10 @@ type Runner struct {
 	Kubernetes *RunnerKubernetes `yaml:"kubernetes"`
 	// Ansible runner options
 	Ansible *RunnerAnsible `yaml:"ansible"`
+	// SSH runner options
+	SSH *RunnerSSH `yaml:"ssh"`
+	// Local runner options
+	Local *RunnerLocal `yaml:"local"`
 	// Mock runner options (userd for testing purposes)
 	Mock *RunnerMock `yaml:"mock"`
 }
@@ -222,10 +428,19 @@ type RunnerKubernetes struct {
 	InClusterConfig bool `yaml:"inClusterConfig"`
 	// Path to your kubeconfig file, if not set the following order will be tried out, `KUBECONFIG` and `$HOME/.kube/config`
 	Kubeconfig string `yaml:"kubeconfig,omitempty"`
+	// Context of the kubeconfig to use (default: current context of the kubeconfig)
+	Context string `yaml:"context,omitempty"`
+	// Clusters additional clusters to select server and client hosts from, e.g., to test the paths between clusters
+	// connected through Submariner or Cilium ClusterMesh. The hosts of the clusters are named `CLUSTER/NODE` and have
+	// the `ancientt/cluster` label with the name of their cluster.
+	Clusters []*KubernetesCluster `yaml:"clusters,omitempty" validate:"dive"`
 	// The image used for the spawned Pods for the tests (default: `quay.io/galexrt/container-toolbox:v20210915-101121-713`)
 	Image string `yaml:"image,omitempty"`
 	// Namespace to execute the tests in
 	Namespace string `yaml:"namespace" validate:"max=63"`
+	// Mode how the tasks are run, `pods` (default) creates a server and client Pod per task, `daemonset` deploys an
+	// agent DaemonSet on the hosts of the test once and runs the tasks in the agent Pods through `pods/exec`
+	Mode KubernetesMode `yaml:"mode,omitempty" validate:"omitempty,oneof=pods daemonset"`
 	// If `hostNetwork` mode should be used for the test Pods
 	HostNetwork *bool `yaml:"hostNetwork,omitempty"`
 	// Timeout settings for operations against the Kubernetes API
@@ -236,6 +451,39 @@ type RunnerKubernetes struct {
 	Hosts *KubernetesHosts `yaml:"hosts,omitempty"`
 	// ServiceAccounst to use server and client Pods
 	ServiceAccounts *KubernetesServiceAccounts `yaml:"serviceaccounts,omitempty"`
+	// Service create a Service per server Pod for the clients to connect through, e.g., to test the kube-proxy / CNI
+	// Service path. When not set, the clients connect to the server Pod IP.
+	Service *KubernetesService `yaml:"service,omitempty"`
+	// PodTemplate Pod templates strategic merged on top of the generated server and client Pods, e.g., for resources,
+	// securityContext, priorityClassName, imagePullSecrets, labels, runtimeClassName and dnsPolicy
+	PodTemplate *KubernetesPodTemplate `yaml:"podTemplate,omitempty"`
+	// Multus attach secondary networks to the test Pods and connect the clients to the server on one of them, e.g., to
+	// test SR-IOV / macvlan networks. Can't be used together with `service`.
+	Multus *KubernetesMultus `yaml:"multus,omitempty"`
+}
+
+// KubernetesMode how the Kubernetes runner runs the tasks
+type KubernetesMode string
+
+const (
+	// KubernetesModePods create a server and client Pod per task
+	KubernetesModePods KubernetesMode = "pods"
+	// KubernetesModeDaemonSet run the tasks in the Pods of an agent DaemonSet
+	KubernetesModeDaemonSet KubernetesMode = "daemonset"
+)
+
+// KubernetesCluster additional Kubernetes cluster to run the test Pods in
+type KubernetesCluster struct {
+	// Name of the cluster, used as prefix of the host names of the cluster
+	Name string `yaml:"name" validate:"required,hostname_rfc1123"`
+	// If the Kubernetes client should use the in-cluster config for the cluster communication
+	InClusterConfig bool `yaml:"inClusterConfig,omitempty"`
+	// Path to the kubeconfig file of the cluster (default: kubeconfig of the runner)
+	Kubeconfig string `yaml:"kubeconfig,omitempty"`
+	// Context of the kubeconfig to use for the cluster
+	Context string `yaml:"context,omitempty"`
+	// Namespace to execute the tests in (default: namespace of the runner)
+	Namespace string `yaml:"namespace,omitempty" validate:"max=63"`
 }
 
 // KubernetesTimeouts timeouts for operations with the Kubernetess API (in secconds)
@@ -264,6 +512,52 @@ type KubernetesServiceAccounts struct {
 	Clients string `yaml:"clients,omitempty"`
 }
 
+// KubernetesServiceType type of Service created for the server Pods
+type KubernetesServiceType string
+
+const (
+	// KubernetesServiceTypeClusterIP clients connect to the ClusterIP of the Service
+	KubernetesServiceTypeClusterIP KubernetesServiceType = "ClusterIP"
+	// KubernetesServiceTypeNodePort clients connect to the NodePort on the Node of the server Pod
+	KubernetesServiceTypeNodePort KubernetesServiceType = "NodePort"
+	// KubernetesServiceTypeHeadless clients connect to the DNS name of the headless Service
+	KubernetesServiceTypeHeadless KubernetesServiceType = "Headless"
+	// KubernetesServiceTypeLoadBalancer clients connect to the LoadBalancer IP of the Service
+	KubernetesServiceTypeLoadBalancer KubernetesServiceType = "LoadBalancer"
+	// KubernetesServiceTypeExported clients connect to the clusterset DNS name of the Service exported through the
+	// Multi-Cluster Services API (`ServiceExport`)
+	KubernetesServiceTypeExported KubernetesServiceType = "Exported"
+)
+
+// KubernetesService Service options for the server Pods
+type KubernetesService struct {
+	// Type of Service, `ClusterIP` (Service IP), `NodePort` (Node IP of the server Pod and the NodePort), `Headless`
+	// (DNS name of the Service, `SERVICE.NAMESPACE.svc`), `LoadBalancer` (LoadBalancer IP of the Service) or `Exported`
+	// (Service exported with a `ServiceExport`, `SERVICE.NAMESPACE.svc.clusterset.local`)
+	Type KubernetesServiceType `yaml:"type" validate:"required,oneof=ClusterIP NodePort Headless LoadBalancer Exported"`
+	// Annotations to put on the Services
+	Annotations map[string]string `yaml:"annotations,omitempty"`
+}
+
+// KubernetesPodTemplate server and client Pod templates. Each template is a (partial) Pod object with `metadata` and
+// `spec`, using the Kubernetes field names. The test container is named `ancientt`.
+type KubernetesPodTemplate struct {
+	// Server Pod template for the server Pods
+	Server map[string]interface{} `yaml:"server,omitempty"`
+	// Clients Pod template for the client Pods
+	Clients map[string]interface{} `yaml:"clients,omitempty"`
+}
+
+// KubernetesMultus Multus secondary networks options for the test Pods
+type KubernetesMultus struct {
+	// Networks NetworkAttachmentDefinitions to attach to the test Pods (`k8s.v1.cni.cncf.io/networks` annotation),
+	// `NAME`, `NAMESPACE/NAME` and optionally suffixed with `@INTERFACE`
+	Networks []string `yaml:"networks" validate:"required,min=1"`
+	// Network the clients connect to the server on, the server address is taken from the
+	// `k8s.v1.cni.cncf.io/network-status` annotation of the server Pod (default: first of the `networks`)
+	Network string `yaml:"network,omitempty"`
+}
+
 // RunnerAnsible Ansible Runner config options
 type RunnerAnsible struct {
 	// InventoryFilePath Path to inventory file to use
@@ -298,6 +592,103 @@ type AnsibleTimeouts struct {
 	TaskCommandTimeout time.Duration `yaml:"taskCommandTimeout,omitempty"`
 }
 
+// RunnerSSH SSH Runner config options
+type RunnerSSH struct {
+	// Static list of hosts to run the tests on
+	Hosts []*SSHHost `yaml:"hosts,omitempty"`
+	// Path to an ssh_config file, each `Host` entry without wildcards is added to the hosts list (the `HostName`,
+	// `Port`, `User` and `IdentityFile` options of the entries are used, the tokens, e.g., `%d`, `%h` and `%u`, in the
+	// `IdentityFile`s are expanded)
+	SSHConfigFile string `yaml:"sshConfigFile,omitempty"`
+	// Default user to connect as (default: current user)
+	User string `yaml:"user,omitempty"`
+	// Default SSH port (default: `22`)
+	Port int `yaml:"port,omitempty"`
+	// List of private key files to use for authentication (default: `~/.ssh/id_rsa`, `~/.ssh/id_ecdsa` and
+	// `~/.ssh/id_ed25519` when they exist)
+	PrivateKeyFiles []string `yaml:"privateKeyFiles,omitempty"`
+	// If the SSH agent (`SSH_AUTH_SOCK`) should be used for authentication (default: `true`)
+	UseAgent *bool `yaml:"useAgent,omitempty"`
+	// Path to the known_hosts file used for verifying the host keys (default: `~/.ssh/known_hosts`)
+	KnownHostsFile string `yaml:"knownHostsFile,omitempty"`
+	// Disable host key verification, **insecure** (default: `false`)
+	InsecureIgnoreHostKey *bool `yaml:"insecureIgnoreHostKey,omitempty"`
+	// Timeout settings for SSH connections and commands
+	Timeouts *SSHTimeouts `yaml:"timeouts,omitempty"`
+}
+
+// SSHHost host of the SSH runner
+type SSHHost struct {
+	// Name of the host
+	Name string `yaml:"name" validate:"required,min=1"`
+	// Address to connect to (default: `Name`)
+	Address string `yaml:"address,omitempty"`
+	// SSH port (default: runner `port`)
+	Port int `yaml:"port,omitempty"`
+	// User to connect as (default: runner `user`)
+	User string `yaml:"user,omitempty"`
+	// List of private key files to use for authentication with the host (default: runner `privateKeyFiles`), for hosts
+	// from the ssh config file the `IdentityFile`s of the host
+	PrivateKeyFiles []string `yaml:"privateKeyFiles,omitempty"`
+	// Labels of the host, used by the `hostSelector`, `labelSelector`, `matchExpressions` and `antiAffinity` of the tests
+	Labels map[string]string `yaml:"labels,omitempty"`
+	// IPv4 addresses of the host to use for the tests (default: the addresses `Address` resolves to)
+	IPv4 []string `yaml:"ipv4,omitempty"`
+	// IPv6 addresses of the host to use for the tests (default: the addresses `Address` resolves to)
+	IPv6 []string `yaml:"ipv6,omitempty"`
+}
+
+// SSHTimeouts timeouts for SSH connections and commands
+type SSHTimeouts struct {
+	// Timeout duration for establishing SSH connections (default: `10s`)
+	ConnectTimeout time.Duration `yaml:"connectTimeout,omitempty"`
+	// Timeout duration for task command runs (default: `45s`)
+	TaskCommandTimeout time.Duration `yaml:"taskCommandTimeout,omitempty"`
+	// Time to wait for a server (main) task to exit after it has been sent the TERM signal, before it is killed (default: `5s`)
+	StopTimeout time.Duration `yaml:"stopTimeout,omitempty"`
+}
+
+// RunnerLocal Local Runner config options
+type RunnerLocal struct {
+	// Local "hosts" for the tests, without network namespaces all hosts are the local machine (default: `local-0` and `local-1`)
+	Hosts []*LocalHost `yaml:"hosts,omitempty"`
+	// Network namespaces options, to put each host in its own Linux network namespace
+	NetworkNamespaces *LocalNetworkNamespaces `yaml:"networkNamespaces,omitempty"`
+	// Path to the `ip` command, used for the network namespaces (if empty will be searched for in `PATH`; default: `ip`)
+	IPCommand string `yaml:"ipCommand,omitempty"`
+	// Timeout settings for local command runs
+	Timeouts *LocalTimeouts `yaml:"timeouts,omitempty"`
+}
+
+// LocalHost host of the Local runner
+type LocalHost struct {
+	// Name of the host
+	Name string `yaml:"name" validate:"required,min=1"`
+	// Labels of the host, used by the `hostSelector`, `labelSelector`, `matchExpressions` and `antiAffinity` of the tests
+	Labels map[string]string `yaml:"labels,omitempty"`
+}
+
+// LocalNetworkNamespaces network namespaces options for the Local runner. Each host gets its own network namespace
+// which is connected through a veth pair to a bridge. Requires root (`CAP_NET_ADMIN` and `CAP_SYS_ADMIN`) permissions.
+type LocalNetworkNamespaces struct {
+	// If the hosts should be put in network namespaces (default: `false`)
+	Enabled *bool `yaml:"enabled,omitempty"`
+	// IPv4 subnet in CIDR notation from which the addresses of the hosts are taken (default: `10.213.0.0/24`)
+	Subnet string `yaml:"subnet,omitempty" validate:"omitempty,cidrv4"`
+	// IPv6 subnet in CIDR notation from which the IPv6 addresses of the hosts are taken (default: `fd00:213::/64`)
+	SubnetV6 string `yaml:"subnetV6,omitempty" validate:"omitempty,cidrv6"`
+	// Prefix for the names of the network namespaces, veth interfaces and bridge, max. 8 characters (default: `ancientt`)
+	Prefix string `yaml:"prefix,omitempty" validate:"omitempty,max=8"`
+}
+
+// LocalTimeouts timeouts for local command runs
+type LocalTimeouts struct {
+	// Timeout duration for `ip` command calls (default: `20s`)
+	CommandTimeout time.Duration `yaml:"commandTimeout,omitempty"`
+	// Timeout duration for task command runs (default: `45s`)
+	TaskCommandTimeout time.Duration `yaml:"taskCommandTimeout,omitempty"`
+}
+
 // RunnerMock Mock Runner config options (here for good measure)
 type RunnerMock struct {
 }
@@ -316,12 +707,123 @@ type Test struct {
 	Transformations []*Transformation `yaml:"transformations,omitempty"`
 	// Hosts selection for client and server
 	Hosts TestHosts `yaml:"hosts"`
+	// Pairing how the server and client hosts are paired for the test
+	Pairing Pairing `yaml:"pairing,omitempty"`
+	// IPFamily the clients connect to the server with, `v4`, `v6` or `both` (default: `v4`). With `both` each client
+	// runs the test once per family, the family is recorded in the `ip_family` column of the results.
+	IPFamily IPFamily `yaml:"ipFamily,omitempty" validate:"omitempty,oneof=v4 v6 both"`
+	// Assertions to check the tester results against after the test, in the format `COLUMN AGGREGATION OPERATOR VALUE`
+	// (e.g., `bits_per_second p95 >= 9e9`). They are evaluated per server / client host pair, available aggregations are
+	// `count`, `min`, `avg`, `max`, `p50`, `p95` and `p99`, operators are `==`, `!=`, `<`, `<=`, `>` and `>=`.
+	// ancientt exits with a non-zero exit code when an assertion is violated. For columns the tester results and end
+	// results both contain (e.g., `seconds`), the end results are used.
+	Assertions []string `yaml:"assertions,omitempty"`
+	// Baseline comparison options, used by the `ancientt compare` command
+	Compare *Compare `yaml:"compare,omitempty"`
 	// IPerf3 tester options
 	IPerf3 *IPerf3 `yaml:"iperf3"`
 	// PingParsing tester options
 	PingParsing *PingParsing `yaml:"pingParsing"`
 }
 
+// CompareBaselineFormat format of a baseline file
+type CompareBaselineFormat string
+
+const (
+	// CompareBaselineFormatCSV baseline produced by the CSV output
+	CompareBaselineFormatCSV CompareBaselineFormat = "csv"
+	// CompareBaselineFormatSQLite baseline produced by the SQLite output
+	CompareBaselineFormatSQLite CompareBaselineFormat = "sqlite"
+)
+
+// CompareMetricType type of a compared metric, used to know in which direction a change is a regression
+type CompareMetricType string
+
+const (
+	// CompareMetricTypeThroughput a drop of the metric is a regression (e.g., `bits_per_second`)
+	CompareMetricTypeThroughput CompareMetricType = "throughput"
+	// CompareMetricTypeLatency a rise of the metric is a regression (e.g., `rtt_avg`)
+	CompareMetricTypeLatency CompareMetricType = "latency"
+)
+
+// Compare options for comparing the results of a test against a baseline of a previous run
+type Compare struct {
+	// Baseline produced by the CSV or SQLite output of a previous run of the test
+	Baseline CompareBaseline `yaml:"baseline"`
+	// Change in percent of a metric in the "wrong" direction before a server / client host pair is flagged as a
+	// regression (default: `10`)
+	MaxChangePercent *float64 `yaml:"maxChangePercent,omitempty"`
+	// Metrics to compare, when empty `bits_per_second` (`throughput`) is used for `iperf3` and `rtt_avg` (`latency`) for
+	// `pingparsing` tests
+	Metrics []*CompareMetric `yaml:"metrics,omitempty" validate:"dive"`
+}
+
+// CompareBaseline baseline file options
+type CompareBaseline struct {
+	// Format of the baseline, `csv` or `sqlite` (default: `csv`)
+	Format CompareBaselineFormat `yaml:"format,omitempty" validate:"omitempty,oneof=csv sqlite"`
+	// Path to the baseline file, for the CSV output use the tester results file (not the `end` or `summary` files)
+	FilePath string `yaml:"filePath" validate:"required,min=1"`
+	// Name of the table with the tester results in the SQLite database (required when the format is `sqlite`)
+	TableName string `yaml:"tableName,omitempty"`
+	// Separator used in the CSV file (default: `;`)
+	Separator string `yaml:"separator,omitempty"`
+}
+
+// CompareMetric metric (column) to compare per server / client host pair
+type CompareMetric struct {
+	// Column name in the tester results
+	Column string `yaml:"column" validate:"required,min=1"`
+	// Type of the metric, `throughput` or `latency`
+	Type CompareMetricType `yaml:"type" validate:"required,oneof=throughput latency"`
+	// Aggregation of the values per server / client host pair, one of `min`, `avg`, `max`, `p50`, `p95` and `p99`
+	// (default: `avg`)
+	Aggregation string `yaml:"aggregation,omitempty" validate:"omitempty,oneof=min avg max p50 p95 p99"`
+	// Override of the `maxChangePercent` for this metric
+	MaxChangePercent *float64 `yaml:"maxChangePercent,omitempty"`
+}
+
+// PairingStrategy custom pairing strategy const type
+type PairingStrategy string
+
+const (
+	// PairingStrategyAll every server host is tested by every client host
+	PairingStrategyAll PairingStrategy = "all"
+	// PairingStrategyMesh every host tests every other host in both directions, server and client hosts are merged
+	PairingStrategyMesh PairingStrategy = "mesh"
+	// PairingStrategyRing every host tests its neighbour, server and client hosts are merged and sorted by name
+	PairingStrategyRing PairingStrategy = "ring"
+	// PairingStrategyOneToOne server and client hosts sorted by name are zipped, additional hosts are not tested. The
+	// clients are rotated so that no host is paired with itself, hosts which can't be paired otherwise are not tested
+	PairingStrategyOneToOne PairingStrategy = "oneToOne"
+	// PairingStrategySameZone every server host is tested by every client host with the same topology label value
+	PairingStrategySameZone PairingStrategy = "sameZone"
+	// PairingStrategyCrossZone every server host is tested by every client host with a different topology label value
+	PairingStrategyCrossZone PairingStrategy = "crossZone"
+)
+
+// IPFamily IP address family the clients connect to the server with
+type IPFamily string
+
+const (
+	// IPFamilyV4 connect to the IPv4 address of the server
+	IPFamilyV4 IPFamily = "v4"
+	// IPFamilyV6 connect to the IPv6 address of the server
+	IPFamilyV6 IPFamily = "v6"
+	// IPFamilyBoth run the test once for each, IPv4 and IPv6
+	IPFamilyBoth IPFamily = "both"
+)
+
+// Pairing options for pairing the server and client hosts
+type Pairing struct {
+	// Strategy can be `all`, `mesh`, `ring`, `oneToOne`, `sameZone` or `crossZone` (see `PairingStrategy`, default: `all`).
+	// Except for `all`, a host is never paired with itself.
+	Strategy PairingStrategy `yaml:"strategy,omitempty" validate:"omitempty,oneof=all mesh ring oneToOne sameZone crossZone"`
+	// TopologyLabel label of the hosts used by `sameZone` and `crossZone`, hosts without the label are not tested
+	// (default: `topology.kubernetes.io/zone`)
+	TopologyLabel string `yaml:"topologyLabel,omitempty"`
+}
+
 // RunMode custom run mode const type for
 type RunMode string
 
@@ -342,8 +844,36 @@ type RunOptions struct {
 	Interval time.Duration `yaml:"interval,omitempty"`
 	// Run mode can be `parallel` or `sequential` (see `RunMode`, default: is `sequential`)
 	Mode RunMode `yaml:"mode,omitempty"`
-	// **NOT IMPLEMENTED YET** amount of test tasks to run when using `RunModeParallel` (value: `parallel`).
-	ParallelCount int `yaml:"parallelCount,omitempty"`
+	// Amount of client tasks of a server task to run at the same time when using `RunModeParallel` (value: `parallel`)
+	// (default: `0`, no limit).
+	ParallelCount int `yaml:"parallelCount,omitempty" validate:"min=0"`
+	// Amount of server tasks of a round to run at the same time when using `RunModeParallel` (value: `parallel`), the
+	// interval is waited for after each batch of server tasks instead of after each server task (default: `1`).
+	ParallelServers int `yaml:"parallelServers,omitempty" validate:"min=0"`
+}
+
+// LabelSelectorOperator operator of a label selector requirement
+type LabelSelectorOperator string
+
+const (
+	// LabelSelectorOpIn the label value must be one of the values
+	LabelSelectorOpIn LabelSelectorOperator = "In"
+	// LabelSelectorOpNotIn the label value must not be one of the values, hosts without the label match
+	LabelSelectorOpNotIn LabelSelectorOperator = "NotIn"
+	// LabelSelectorOpExists the label must exist
+	LabelSelectorOpExists LabelSelectorOperator = "Exists"
+	// LabelSelectorOpDoesNotExist the label must not exist
+	LabelSelectorOpDoesNotExist LabelSelectorOperator = "DoesNotExist"
+)
+
+// LabelSelectorRequirement label selector requirement
+type LabelSelectorRequirement struct {
+	// Key label key the requirement applies to
+	Key string `yaml:"key" validate:"required"`
+	// Operator can be `In`, `NotIn`, `Exists` and `DoesNotExist` (see `LabelSelectorOperator`)
+	Operator LabelSelectorOperator `yaml:"operator" validate:"required,oneof=In NotIn Exists DoesNotExist"`
+	// Values for `In` and `NotIn`, must be empty for `Exists` and `DoesNotExist`
+	Values []string `yaml:"values,omitempty"`
 }
 
 // TestHosts list of clients and servers hosts for use in the test(s)
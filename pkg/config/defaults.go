@@ -40,6 +40,10 @@ func (c *RunnerKubernetes) SetDefaults() {
 	if c.Namespace == "" {
 		c.Namespace = "ancientt"
 	}
+
+	if c.Mode == "" {
+		c.Mode = KubernetesModePods
+	}
 }
 
 // SetDefaults set defaults on config part
@@ -104,6 +108,71 @@ func (c *RunnerAnsible) SetDefaults() {
 	}
 }
 
+// SetDefaults set defaults on config part
+func (c *RunnerSSH) SetDefaults() {
+	if c.Port == 0 {
+		c.Port = 22
+	}
+	if c.UseAgent == nil {
+		c.UseAgent = util.BoolTruePointer()
+	}
+	if c.InsecureIgnoreHostKey == nil {
+		c.InsecureIgnoreHostKey = util.BoolFalsePointer()
+	}
+
+	if c.Timeouts == nil {
+		c.Timeouts = &SSHTimeouts{}
+	}
+	if c.Timeouts.ConnectTimeout == 0 {
+		c.Timeouts.ConnectTimeout = 10 * time.Second
+	}
+	if c.Timeouts.TaskCommandTimeout == 0 {
+		c.Timeouts.TaskCommandTimeout = 45 * time.Second
+	}
+	if c.Timeouts.StopTimeout == 0 {
+		c.Timeouts.StopTimeout = 5 * time.Second
+	}
+}
+
+// SetDefaults set defaults on config part
+func (c *RunnerLocal) SetDefaults() {
+	if len(c.Hosts) == 0 {
+		c.Hosts = []*LocalHost{
+			{Name: "local-0"},
+			{Name: "local-1"},
+		}
+	}
+	if c.IPCommand == "" {
+		c.IPCommand = "ip"
+	}
+
+	if c.NetworkNamespaces == nil {
+		c.NetworkNamespaces = &LocalNetworkNamespaces{}
+	}
+	if c.NetworkNamespaces.Enabled == nil {
+		c.NetworkNamespaces.Enabled = util.BoolFalsePointer()
+	}
+	if c.NetworkNamespaces.Subnet == "" {
+		c.NetworkNamespaces.Subnet = "10.213.0.0/24"
+	}
+	if c.NetworkNamespaces.SubnetV6 == "" {
+		c.NetworkNamespaces.SubnetV6 = "fd00:213::/64"
+	}
+	if c.NetworkNamespaces.Prefix == "" {
+		c.NetworkNamespaces.Prefix = "ancientt"
+	}
+
+	if c.Timeouts == nil {
+		c.Timeouts = &LocalTimeouts{}
+	}
+	if c.Timeouts.CommandTimeout == 0 {
+		c.Timeouts.CommandTimeout = 20 * time.Second
+	}
+	if c.Timeouts.TaskCommandTimeout == 0 {
+		c.Timeouts.TaskCommandTimeout = 45 * time.Second
+	}
+}
+
 // SetDefaults set defaults on config part
 func (c *RunOptions) SetDefaults() {
 	if c.ContinueOnError == nil {
@@ -121,6 +190,21 @@ func (c *RunOptions) SetDefaults() {
 	if c.Mode == "" {
 		c.Mode = RunModeSequential
 	}
+
+	if c.ParallelServers == 0 {
+		c.ParallelServers = 1
+	}
+}
+
+// SetDefaults set defaults on config part
+func (c *Pairing) SetDefaults() {
+	if c.Strategy == "" {
+		c.Strategy = PairingStrategyAll
+	}
+
+	if c.TopologyLabel == "" {
+		c.TopologyLabel = "topology.kubernetes.io/zone"
+	}
 }
 
 // SetDefaults set defaults on config part
@@ -168,6 +252,56 @@ func (c *AdditionalFlags) SetDefaults() {
 	}
 }
 
+// SetDefaults set defaults on config part
+func (c *HTML) SetDefaults() {
+	if c.Title == "" {
+		c.Title = "ancientt report"
+	}
+	if c.Charts == nil {
+		c.Charts = []*HTMLChart{
+			{TimeColumn: "start", Column: "bits_per_second"},
+			{TimeColumn: "icmp_seq", Column: "time"},
+		}
+	}
+}
+
+// SetDefaults set defaults on config part
+func (c *Markdown) SetDefaults() {
+	if c.Title == "" {
+		c.Title = "ancientt summary"
+	}
+	if c.Columns == nil {
+		c.Columns = []*MarkdownColumn{
+			{Name: "bits_per_second", Title: "Throughput", Unit: "Gbit/s"},
+			{Name: "retransmits", Title: "Retransmits", Precision: util.IntPointer(0)},
+			{Name: "time", Title: "RTT", Unit: "ms"},
+		}
+	}
+	for _, column := range c.Columns {
+		column.SetDefaults()
+	}
+	if c.Aggregations == nil {
+		c.Aggregations = []string{"min", "avg", "max", "p95"}
+	}
+	if c.OutputFiles == nil {
+		c.OutputFiles = util.BoolTruePointer()
+	}
+}
+
+// SetDefaults set defaults on config part
+func (c *MarkdownColumn) SetDefaults() {
+	if c.Precision == nil {
+		c.Precision = util.IntPointer(2)
+	}
+}
+
+// SetDefaults set defaults on config part
+func (c *JSON) SetDefaults() {
+	if c.Format == "" {
+		c.Format = JSONFormatArray
+	}
+}
+
 // SetDefaults set defaults on config part
 func (c *Excelize) SetDefaults() {
 	if c.SaveAfterRows == 0 {
@@ -182,6 +316,81 @@ func (c *MySQL) SetDefaults() {
 	}
 }
 
+// SetDefaults set defaults on config part
+func (c *Prometheus) SetDefaults() {
+	if c.MetricPrefix == "" {
+		c.MetricPrefix = "ancientt"
+	}
+	if c.Labels == nil {
+		c.Labels = map[string]string{
+			"metric": "metric",
+		}
+	}
+}
+
+// SetDefaults set defaults on config part
+func (c *PrometheusPushgateway) SetDefaults() {
+	if c.Job == "" {
+		c.Job = "ancientt"
+	}
+	if c.Timeout == 0 {
+		c.Timeout = 10 * time.Second
+	}
+}
+
+// SetDefaults set defaults on config part
+func (c *InfluxDB) SetDefaults() {
+	if c.MeasurementPattern == "" {
+		c.MeasurementPattern = "ancientt_{{ .Data.Tester }}{{ with .Data.Type }}_{{ . }}{{ end }}"
+	}
+	if c.Tags == nil {
+		c.Tags = []string{"tester", "server_host", "client_host", "round", "socket", "metric"}
+	}
+	if c.TimeColumn == "" {
+		c.TimeColumn = "test_time"
+	}
+	if c.IntervalColumn == "" {
+		c.IntervalColumn = "start"
+	}
+}
+
+// SetDefaults set defaults on config part
+func (c *InfluxDBHTTP) SetDefaults() {
+	if c.BatchSize == 0 {
+		c.BatchSize = 5000
+	}
+	if c.Retries == nil {
+		retries := 3
+		c.Retries = &retries
+	}
+	if c.RetryInterval == 0 {
+		c.RetryInterval = 1 * time.Second
+	}
+	if c.Timeout == 0 {
+		c.Timeout = 10 * time.Second
+	}
+}
+
+// SetDefaults set defaults on config part
+func (c *Postgres) SetDefaults() {
+	if c.AutoCreateTables == nil {
+		c.AutoCreateTables = util.BoolTruePointer()
+	}
+	if c.TimeColumn == "" {
+		c.TimeColumn = "test_time"
+	}
+	if c.TimescaleDB == nil {
+		c.TimescaleDB = &PostgresTimescaleDB{}
+	}
+}
+
+// SetDefaults set defaults on config part
+func (c *PostgresTimescaleDB) SetDefaults() {
+	if c.Enabled == nil {
+		c.Enabled = util.BoolFalsePointer()
+	}
+}
+
 // SetDefaults set defaults on config part
 func (c *CSV) SetDefaults() {
 	if c.Separator == nil {
@@ -199,3 +408,27 @@ func (c *GoChartGraph) SetDefaults() {
 		c.WithSimpleMovingAverage = util.BoolTruePointer()
 	}
 }
+
+// SetDefaults set defaults on config part
+func (c *Compare) SetDefaults() {
+	if c.MaxChangePercent == nil {
+		c.MaxChangePercent = util.FloatPointer(10)
+	}
+}
+
+// SetDefaults set defaults on config part
+func (c *CompareBaseline) SetDefaults() {
+	if c.Format == "" {
+		c.Format = CompareBaselineFormatCSV
+	}
+	if c.Separator == "" {
+		c.Separator = ";"
+	}
+}
+
+// SetDefaults set defaults on config part
+func (c *CompareMetric) SetDefaults() {
+	if c.Aggregation == "" {
+		c.Aggregation = "avg"
+	}
+}
@@ -15,6 +15,7 @@ limitations under the License.
 package executor
 
 import (
+	"bytes"
 	"context"
 	"os"
 	"os/exec"
@@ -29,6 +30,7 @@ type Executor interface {
 	ExecuteCommand(ctx context.Context, actionName string, command string, arg ...string) error
 	ExecuteCommandWithOutput(ctx context.Context, actionName string, command string, arg ...string) (string, error)
 	ExecuteCommandWithOutputByte(ctx context.Context, actionName string, command string, arg ...string) ([]byte, error)
+	ExecuteCommandWithSeparateOutput(ctx context.Context, actionName string, command string, arg ...string) ([]byte, []byte, error)
 	SetEnv([]string)
 }
 
@@ -99,6 +101,33 @@ func (ce CommandExecutor) ExecuteCommandWithOutputByte(ctx context.Context, acti
 	return out, nil
 }
 
+// ExecuteCommandWithSeparateOutput execute a given command with its arguments and return the stdout and stderr output
+// separately, e.g., to parse the stdout output without warnings the command prints to stderr
+func (ce CommandExecutor) ExecuteCommandWithSeparateOutput(ctx context.Context, actionName string, command string, arg ...string) ([]byte, []byte, error) {
+	cmd := exec.CommandContext(ctx, command, arg...)
+	cmd.Env = os.Environ()
+	cmd.SysProcAttr = &syscall.SysProcAttr{
+		Pdeathsig: syscall.SIGTERM,
+		Setpgid:   true,
+	}
+
+	ce.logger.WithFields(logrus.Fields{
+		"command": command,
+		"args":    arg,
+	}).Info("executing command")
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	err := cmd.Run()
+	ce.logger.WithField("action", actionName).Debug(stdout.String())
+	if stderr.Len() > 0 {
+		ce.logger.WithFields(logrus.Fields{"action": actionName, "stream": "stderr"}).Debug(stderr.String())
+	}
+
+	return stdout.Bytes(), stderr.Bytes(), err
+}
+
 // SetEnv set env for command execution
 func (ce CommandExecutor) SetEnv(e []string) {
 	ce.env = e
@@ -15,6 +15,7 @@ limitations under the License.
 package test
 
 import (
+	"bytes"
 	"context"
 	"os/exec"
 
@@ -29,7 +30,9 @@ type MockExecutor struct {
 	MockExecuteCommand               func(ctx context.Context, actionName string, command string, arg ...string) error
 	MockExecuteCommandWithOutput     func(ctx context.Context, actionName string, command string, arg ...string) (string, error)
 	MockExecuteCommandWithOutputByte func(ctx context.Context, actionName string, command string, arg ...string) ([]byte, error)
-	MockSetEnv                       func(e []string)
+	// MockExecuteCommandWithSeparateOutput falls back to MockExecuteCommandWithOutputByte (returned as stdout) when not set
+	MockExecuteCommandWithSeparateOutput func(ctx context.Context, actionName string, command string, arg ...string) ([]byte, []byte, error)
+	MockSetEnv                           func(e []string)
 
 	env []string
 }
@@ -80,6 +83,29 @@ func (ce MockExecutor) ExecuteCommandWithOutputByte(ctx context.Context, actionN
 	return out, nil
 }
 
+// ExecuteCommandWithSeparateOutput execute a given command with its arguments and return the stdout and stderr output
+// separately
+func (ce MockExecutor) ExecuteCommandWithSeparateOutput(ctx context.Context, actionName string, command string, arg ...string) ([]byte, []byte, error) {
+	if ce.MockExecuteCommandWithSeparateOutput != nil {
+		return ce.MockExecuteCommandWithSeparateOutput(ctx, actionName, command, arg...)
+	}
+	if ce.MockExecuteCommandWithOutputByte != nil {
+		out, err := ce.MockExecuteCommandWithOutputByte(ctx, actionName, command, arg...)
+		return out, nil, err
+	}
+
+	cmd := exec.CommandContext(ctx, command, arg...)
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	err := cmd.Run()
+
+	log.WithField("action", actionName).Debug(stdout.String())
+
+	return stdout.Bytes(), stderr.Bytes(), err
+}
+
 // SetEnv set env for command execution
 func (ce MockExecutor) SetEnv(e []string) {
 	log.WithField("action", "setEnv()").Debugf("%+v", ce.env)
@@ -14,31 +14,46 @@ limitations under the License.
 package hostsfilter
 
 import (
+	"fmt"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
+	"github.com/cloudical-io/ancientt/pkg/config"
+	"github.com/cloudical-io/ancientt/pkg/util"
 	"github.com/cloudical-io/ancientt/testers"
 )
 
+func hostNames(hosts []*testers.Host) []string {
+	names := []string{}
+	for _, host := range hosts {
+		names = append(names, host.Name)
+	}
+	return names
+}
+
 func TestAntiAffinity(t *testing.T) {
 	hosts := []*testers.Host{
 		{
 			Name: "host-a",
 			Labels: map[string]string{
-				"foo": "bar",
+				"foo":  "bar",
+				"rack": "1",
 			},
 		},
 		{
 			Name: "host-b",
 			Labels: map[string]string{
-				"foo": "bar",
+				"foo":  "bar",
+				"rack": "2",
 			},
 		},
 		{
 			Name: "host-c",
 			Labels: map[string]string{
-				"foo": "notbar",
+				"foo":  "notbar",
+				"rack": "1",
 			},
 		},
 		{
@@ -47,16 +62,303 @@ func TestAntiAffinity(t *testing.T) {
 				"bar": "foo",
 			},
 		},
+		{
+			Name: "host-e",
+			Labels: map[string]string{
+				"foo":  "baz",
+				"rack": "2",
+			},
+		},
 	}
-	antiAffinityFilter := []string{
-		"foo",
+
+	tests := []struct {
+		name     string
+		labels   []string
+		expected []string
+	}{
+		{
+			name:     "no labels",
+			labels:   []string{},
+			expected: []string{"host-a", "host-b", "host-c", "host-d", "host-e"},
+		},
+		{
+			// host-b will not be in the list because `host-a` has the same anti affinity label as `host-b`, host-d
+			// doesn't have the label
+			name:     "one label",
+			labels:   []string{"foo"},
+			expected: []string{"host-a", "host-c", "host-e"},
+		},
+		{
+			// host-c has a free `foo` value but its rack is taken by host-a, the rejected host-b must not take rack 2
+			name:     "two labels",
+			labels:   []string{"foo", "rack"},
+			expected: []string{"host-a", "host-e"},
+		},
+		{
+			name:     "label no host has",
+			labels:   []string{"zone"},
+			expected: []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, hostNames(checkAntiAffinity(hosts, tt.labels)))
+		})
 	}
+}
+
+func TestFilterHostsListAntiAffinity(t *testing.T) {
+	hosts := []*testers.Host{}
+	for _, host := range []struct {
+		name string
+		zone string
+	}{
+		{"host-a1", "a"},
+		{"host-a2", "a"},
+		{"host-b1", "b"},
+		{"host-b2", "b"},
+		{"host-c1", "c"},
+		{"host-x", ""},
+	} {
+		labels := map[string]string{"role": "worker"}
+		if host.zone != "" {
+			labels["zone"] = host.zone
+		}
+		hosts = append(hosts, &testers.Host{Name: host.name, Labels: labels})
+	}
+
+	tests := []struct {
+		name   string
+		filter config.Hosts
+		// count amount of expected hosts, each with a distinct zone
+		count int
+		err   string
+	}{
+		{
+			name:   "all one host per zone",
+			filter: config.Hosts{All: util.BoolTruePointer(), AntiAffinity: []string{"zone"}},
+			count:  3,
+		},
+		{
+			name:   "random count spread over zones",
+			filter: config.Hosts{Random: util.BoolTruePointer(), Count: 2, AntiAffinity: []string{"zone"}},
+			count:  2,
+		},
+		{
+			name:   "random count equal to zones",
+			filter: config.Hosts{Random: util.BoolTruePointer(), Count: 3, AntiAffinity: []string{"zone"}, HostSelector: map[string]string{"role": "worker"}},
+			count:  3,
+		},
+		{
+			name:   "random count more than zones",
+			filter: config.Hosts{Random: util.BoolTruePointer(), Count: 4, AntiAffinity: []string{"zone"}},
+			err:    "cannot select 4 hosts with anti-affinity on labels zone, only 3 of the 6 hosts have distinct label values",
+		},
+		{
+			name:   "no host has the label",
+			filter: config.Hosts{Random: util.BoolTruePointer(), Count: 1, AntiAffinity: []string{"rack"}},
+			err:    "none of the 6 hosts have all anti-affinity labels rack",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Run multiple times as the selection is random
+			for i := 0; i < 10; i++ {
+				filtered, _, err := FilterHostsList(hosts, tt.filter)
+				if tt.err != "" {
+					require.NotNil(t, err)
+					assert.Equal(t, tt.err, err.Error())
+					return
+				}
+				require.Nil(t, err)
+				assert.Equal(t, tt.count, len(filtered))
+
+				zones := map[string]bool{}
+				for _, host := range filtered {
+					zone, ok := host.Labels["zone"]
+					require.True(t, ok)
+					assert.False(t, zones[zone], "zone %s selected twice", zone)
+					zones[zone] = true
+				}
+			}
+		})
+	}
+}
+
+func TestFilterHostsListLabelSelector(t *testing.T) {
+	hosts := []*testers.Host{
+		{
+			Name:   "node-a1",
+			Labels: map[string]string{"zone": "a", "role": "worker"},
+		},
+		{
+			Name:   "node-a2",
+			Labels: map[string]string{"zone": "a", "role": "storage"},
+		},
+		{
+			Name:   "node-b1",
+			Labels: map[string]string{"zone": "b", "role": "worker", "gpu": "true"},
+		},
+		{
+			Name:   "master-c1",
+			Labels: map[string]string{"zone": "c", "role": "master"},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		filter   config.Hosts
+		expected []string
+		err      bool
+	}{
+		{
+			name:     "in",
+			filter:   config.Hosts{All: util.BoolTruePointer(), LabelSelector: "zone in (a,c)"},
+			expected: []string{"node-a1", "node-a2", "master-c1"},
+		},
+		{
+			name:     "notin and not equals",
+			filter:   config.Hosts{All: util.BoolTruePointer(), LabelSelector: "zone notin (c),role!=storage"},
+			expected: []string{"node-a1", "node-b1"},
+		},
+		{
+			name:     "exists",
+			filter:   config.Hosts{All: util.BoolTruePointer(), LabelSelector: "gpu"},
+			expected: []string{"node-b1"},
+		},
+		{
+			name:     "does not exist",
+			filter:   config.Hosts{All: util.BoolTruePointer(), LabelSelector: "!gpu"},
+			expected: []string{"node-a1", "node-a2", "master-c1"},
+		},
+		{
+			name: "combined with host selector and match expressions",
+			filter: config.Hosts{
+				All:           util.BoolTruePointer(),
+				HostSelector:  map[string]string{"role": "worker"},
+				LabelSelector: "zone in (a,b)",
+				MatchExpressions: []*config.LabelSelectorRequirement{
+					{Key: "gpu", Operator: config.LabelSelectorOpDoesNotExist},
+				},
+			},
+			expected: []string{"node-a1"},
+		},
+		{
+			name: "match expressions",
+			filter: config.Hosts{
+				All: util.BoolTruePointer(),
+				MatchExpressions: []*config.LabelSelectorRequirement{
+					{Key: "role", Operator: config.LabelSelectorOpNotIn, Values: []string{"master"}},
+					{Key: "zone", Operator: config.LabelSelectorOpExists},
+				},
+			},
+			expected: []string{"node-a1", "node-a2", "node-b1"},
+		},
+		{
+			name: "exclude names and patterns",
+			filter: config.Hosts{
+				All:     util.BoolTruePointer(),
+				Exclude: []string{"master-*", "node-a2"},
+			},
+			expected: []string{"node-a1", "node-b1"},
+		},
+		{
+			name: "exclude from static hosts list",
+			filter: config.Hosts{
+				Hosts:   []string{"node-a1", "node-b1", "other"},
+				Exclude: []string{"node-b?"},
+			},
+			expected: []string{"node-a1", "other"},
+		},
+		{
+			name:   "invalid label selector",
+			filter: config.Hosts{All: util.BoolTruePointer(), LabelSelector: "zone in (a"},
+			err:    true,
+		},
+		{
+			name: "values for exists operator",
+			filter: config.Hosts{
+				All: util.BoolTruePointer(),
+				MatchExpressions: []*config.LabelSelectorRequirement{
+					{Key: "zone", Operator: config.LabelSelectorOpExists, Values: []string{"a"}},
+				},
+			},
+			err: true,
+		},
+		{
+			name:   "invalid exclude pattern",
+			filter: config.Hosts{All: util.BoolTruePointer(), Exclude: []string{"node-["}},
+			err:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filtered, _, err := FilterHostsList(hosts, tt.filter)
+			if tt.err {
+				assert.NotNil(t, err)
+				return
+			}
+			require.Nil(t, err)
+			assert.Equal(t, tt.expected, hostNames(filtered))
+		})
+	}
+}
+
+func TestFilterHostsListRandomSeed(t *testing.T) {
+	hosts := []*testers.Host{}
+	for i := 0; i < 10; i++ {
+		hosts = append(hosts, &testers.Host{Name: fmt.Sprintf("host-%d", i)})
+	}
+	// The same hosts listed in a different order
+	reversed := []*testers.Host{}
+	for i := len(hosts) - 1; i >= 0; i-- {
+		reversed = append(reversed, hosts[i])
+	}
+
+	filter := config.Hosts{Random: util.BoolTruePointer(), Count: 5, Seed: util.Int64Pointer(1337)}
+	filtered, seed, err := FilterHostsList(hosts, filter)
+	require.Nil(t, err)
+	require.NotNil(t, seed)
+	assert.Equal(t, int64(1337), *seed)
+	assert.Equal(t, 5, len(filtered))
+
+	// No host is selected twice
+	names := map[string]bool{}
+	for _, host := range filtered {
+		assert.False(t, names[host.Name], "host %s selected twice", host.Name)
+		names[host.Name] = true
+	}
+
+	// The same seed selects the same hosts
+	again, _, err := FilterHostsList(reversed, filter)
+	require.Nil(t, err)
+	assert.Equal(t, hostNames(filtered), hostNames(again))
+
+	// Without a seed, the returned seed reproduces the selection
+	filter.Seed = nil
+	filtered, seed, err = FilterHostsList(hosts, filter)
+	require.Nil(t, err)
+	require.NotNil(t, seed)
+	filter.Seed = seed
+	again, _, err = FilterHostsList(hosts, filter)
+	require.Nil(t, err)
+	assert.Equal(t, hostNames(filtered), hostNames(again))
+
+	// All hosts are selected at most once
+	filter.Count = 10
+	filtered, _, err = FilterHostsList(hosts, filter)
+	require.Nil(t, err)
+	assert.ElementsMatch(t, hostNames(hosts), hostNames(filtered))
 
-	filteredHosts := checkAntiAffinity(hosts, antiAffinityFilter)
-	assert.Equal(t, 3, len(filteredHosts))
+	filter.Count = 11
+	_, _, err = FilterHostsList(hosts, filter)
+	assert.NotNil(t, err)
 
-	// host-b will not be in the list because `host-a` has the same anti affinity label as `host-a`
-	assert.Equal(t, "host-a", filteredHosts[0].Name)
-	assert.Equal(t, "host-c", filteredHosts[1].Name)
-	assert.Equal(t, "host-d", filteredHosts[2].Name)
+	// No seed is returned without a random selection
+	_, seed, err = FilterHostsList(hosts, config.Hosts{All: util.BoolTruePointer()})
+	require.Nil(t, err)
+	assert.Nil(t, seed)
 }
@@ -14,111 +14,210 @@ limitations under the License.
 package hostsfilter
 
 import (
+	"fmt"
 	"math/rand"
+	"path"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/cloudical-io/ancientt/pkg/config"
+	"github.com/cloudical-io/ancientt/pkg/util"
 	"github.com/cloudical-io/ancientt/testers"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
 )
 
-// FilterHostsList filter a given host list
-func FilterHostsList(inHosts []*testers.Host, filter config.Hosts) ([]*testers.Host, error) {
-	hosts := []*testers.Host{}
-
+// FilterHostsList filter a given host list. For a `random` selection the seed used is returned, the hosts are sampled
+// without replacement and the same seed selects the same hosts from the same list of hosts.
+func FilterHostsList(inHosts []*testers.Host, filter config.Hosts) ([]*testers.Host, *int64, error) {
 	if len(filter.Hosts) > 0 {
+		hosts := []*testers.Host{}
 		for _, host := range filter.Hosts {
 			hosts = append(hosts, &testers.Host{
 				Name: host,
 			})
 		}
-		return hosts, nil
+		hosts, err := excludeHosts(hosts, filter.Exclude)
+		return hosts, nil, err
 	}
 
-	filteredHosts := filterHostsByLabels(inHosts, filter.HostSelector)
+	selector, err := labelSelector(filter)
+	if err != nil {
+		return nil, nil, err
+	}
 
-	filteredHosts = checkAntiAffinity(filteredHosts, filter.AntiAffinity)
+	filteredHosts := filterHostsByLabels(inHosts, selector)
+	if filteredHosts, err = excludeHosts(filteredHosts, filter.Exclude); err != nil {
+		return nil, nil, err
+	}
 
-	if len(filteredHosts) == 0 || (filter.All != nil && *filter.All) {
-		return filteredHosts, nil
+	random := filter.Random != nil && *filter.Random
+	all := filter.All != nil && *filter.All
+
+	var seed *int64
+	if random {
+		seed = filter.Seed
+		if seed == nil {
+			seed = util.Int64Pointer(time.Now().UnixNano())
+		}
+		filteredHosts = shuffleHosts(filteredHosts, *seed)
 	}
 
-	// Create and seed randomness source for the `random` selection of hosts
-	s := rand.NewSource(time.Now().Unix())
-	r := rand.New(s)
-	r.Seed(time.Now().UnixNano())
+	if len(filter.AntiAffinity) > 0 {
+		candidates := len(filteredHosts)
+		filteredHosts = checkAntiAffinity(filteredHosts, filter.AntiAffinity)
+		if len(filteredHosts) == 0 && candidates > 0 {
+			return nil, nil, fmt.Errorf("none of the %d hosts have all anti-affinity labels %s", candidates, strings.Join(filter.AntiAffinity, ", "))
+		}
 
-	// Get random server(s)
-	if filter.Random != nil && *filter.Random {
-		for i := 0; i < filter.Count; i++ {
-			inHost := filteredHosts[r.Intn(len(filteredHosts))]
-			hosts = append(hosts, inHost)
+		if random && !all && len(filteredHosts) < filter.Count {
+			return nil, nil, fmt.Errorf("cannot select %d hosts with anti-affinity on labels %s, only %d of the %d hosts have distinct label values",
+				filter.Count, strings.Join(filter.AntiAffinity, ", "), len(filteredHosts), candidates)
 		}
-		return hosts, nil
 	}
 
-	return filteredHosts, nil
+	if len(filteredHosts) == 0 || all || !random {
+		return filteredHosts, seed, nil
+	}
+
+	if len(filteredHosts) < filter.Count {
+		return nil, nil, fmt.Errorf("cannot select %d random hosts, only %d hosts are available", filter.Count, len(filteredHosts))
+	}
+
+	return filteredHosts[:filter.Count], seed, nil
 }
 
-// filterHostsByLabels all labels must match
-func filterHostsByLabels(hosts []*testers.Host, labels map[string]string) []*testers.Host {
-	if len(labels) == 0 {
+// shuffleHosts return a copy of the hosts sorted by name and shuffled with the seed, so that the order only depends on
+// the seed and not on the order the hosts were listed in
+func shuffleHosts(hosts []*testers.Host, seed int64) []*testers.Host {
+	shuffled := append([]*testers.Host{}, hosts...)
+	sort.Slice(shuffled, func(i, j int) bool {
+		return shuffled[i].Name < shuffled[j].Name
+	})
+
+	r := rand.New(rand.NewSource(seed))
+	r.Shuffle(len(shuffled), func(i, j int) {
+		shuffled[i], shuffled[j] = shuffled[j], shuffled[i]
+	})
+
+	return shuffled
+}
+
+// labelSelector build one label selector from the `HostSelector`, `LabelSelector` and `MatchExpressions` of the
+// filter, all of them must match
+func labelSelector(filter config.Hosts) (labels.Selector, error) {
+	selector := labels.SelectorFromSet(filter.HostSelector)
+
+	if filter.LabelSelector != "" {
+		parsed, err := labels.Parse(filter.LabelSelector)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse label selector %q of hosts %s. %+v", filter.LabelSelector, filter.Name, err)
+		}
+		requirements, _ := parsed.Requirements()
+		selector = selector.Add(requirements...)
+	}
+
+	for _, expr := range filter.MatchExpressions {
+		var op selection.Operator
+		switch expr.Operator {
+		case config.LabelSelectorOpIn:
+			op = selection.In
+		case config.LabelSelectorOpNotIn:
+			op = selection.NotIn
+		case config.LabelSelectorOpExists:
+			op = selection.Exists
+		case config.LabelSelectorOpDoesNotExist:
+			op = selection.DoesNotExist
+		default:
+			return nil, fmt.Errorf("unknown label selector operator %s for key %s of hosts %s", expr.Operator, expr.Key, filter.Name)
+		}
+		requirement, err := labels.NewRequirement(expr.Key, op, expr.Values)
+		if err != nil {
+			return nil, fmt.Errorf("invalid label selector requirement for key %s of hosts %s. %+v", expr.Key, filter.Name, err)
+		}
+		selector = selector.Add(*requirement)
+	}
+
+	return selector, nil
+}
+
+// filterHostsByLabels all requirements of the selector must match
+func filterHostsByLabels(hosts []*testers.Host, selector labels.Selector) []*testers.Host {
+	if selector.Empty() {
 		return hosts
 	}
 
 	filtered := []*testers.Host{}
 	for _, host := range hosts {
-		// Compare host and filter labels list, all labels list must match
-		match := true
-		for k, v := range labels {
-			if labelValue, ok := host.Labels[k]; ok {
-				if labelValue != v {
-					match = false
-					break
-				}
-			} else {
-				match = false
+		if selector.Matches(labels.Set(host.Labels)) {
+			filtered = append(filtered, host)
+		}
+	}
+
+	return filtered
+}
+
+// excludeHosts remove the hosts matching one of the exclude names / patterns
+func excludeHosts(hosts []*testers.Host, exclude []string) ([]*testers.Host, error) {
+	if len(exclude) == 0 {
+		return hosts, nil
+	}
+
+	filtered := []*testers.Host{}
+	for _, host := range hosts {
+		excluded := false
+		for _, pattern := range exclude {
+			match, err := path.Match(pattern, host.Name)
+			if err != nil {
+				return nil, fmt.Errorf("invalid exclude pattern %q. %+v", pattern, err)
+			}
+			if match {
+				excluded = true
+				break
 			}
 		}
-		if match {
+		if !excluded {
 			filtered = append(filtered, host)
 		}
 	}
 
-	return filtered
+	return filtered, nil
 }
 
+// checkAntiAffinity take the hosts in the given order as long as their values of the labels have not been taken by a
+// previous host, hosts without all of the labels are skipped
 func checkAntiAffinity(hosts []*testers.Host, labels []string) []*testers.Host {
 	if len(labels) == 0 {
 		return hosts
 	}
 
 	filtered := []*testers.Host{}
-	usedLabels := map[string][]string{}
+	usedLabels := map[string]map[string]bool{}
+	for _, label := range labels {
+		usedLabels[label] = map[string]bool{}
+	}
 
 	for _, host := range hosts {
-		// Compare host and filter labels list, all labels list must match
 		match := true
 		for _, label := range labels {
 			hostLabelVal, ok := host.Labels[label]
-			if !ok {
-				continue
-			}
-			// Check if label and value is in usedLabels list and if it is the host should not be added
-			if usedLabelValues, ok := usedLabels[label]; ok {
-				for _, usedLabelVal := range usedLabelValues {
-					if usedLabelVal == hostLabelVal {
-						match = false
-						break
-					}
-				}
-				usedLabels[label] = append(usedLabels[label], hostLabelVal)
-			} else {
-				usedLabels[label] = []string{hostLabelVal}
+			// Without the label it is unknown where the host is, so it can't be taken
+			if !ok || usedLabels[label][hostLabelVal] {
+				match = false
+				break
 			}
 		}
-		if match {
-			filtered = append(filtered, host)
+		if !match {
+			continue
+		}
+
+		// Only the label values of taken hosts are used
+		for _, label := range labels {
+			usedLabels[label][host.Labels[label]] = true
 		}
+		filtered = append(filtered, host)
 	}
+
 	return filtered
 }
@@ -29,39 +29,53 @@ import (
 
 // NewClient create a new Kubernetes clientset
 func NewClient(inClusterConfig bool, kubeconfig string) (kubernetes.Interface, error) {
-	var k8sconfig *rest.Config
+	k8sconfig, err := NewRESTConfig(inClusterConfig, kubeconfig, "")
+	if err != nil {
+		return nil, err
+	}
+
+	return NewClientForConfig(k8sconfig)
+}
+
+// NewClientForConfig create a new Kubernetes clientset for the REST config
+func NewClientForConfig(k8sconfig *rest.Config) (kubernetes.Interface, error) {
+	clientset, err := kubernetes.NewForConfig(k8sconfig)
+	if err != nil {
+		return nil, fmt.Errorf("kubernetes new client error. %+v", err)
+	}
+	return clientset, nil
+}
+
+// NewRESTConfig create a new Kubernetes REST config, either the in-cluster config or from the kubeconfig. When the
+// kubeconfig context is empty, the current context of the kubeconfig is used.
+func NewRESTConfig(inClusterConfig bool, kubeconfig string, kubecontext string) (*rest.Config, error) {
 	if inClusterConfig {
-		var err error
-		k8sconfig, err = rest.InClusterConfig()
+		k8sconfig, err := rest.InClusterConfig()
 		if err != nil {
 			return nil, fmt.Errorf("kubeconfig in-cluster configuration error. %+v", err)
 		}
-	} else {
-		var kubeconfig string
-		// Try to fallback to the `KUBECONFIG` env var
-		if kubeconfig == "" {
-			kubeconfig = os.Getenv("KUBECONFIG")
-		}
-		// If the `KUBECONFIG` is empty, default to home dir default kube config path
-		if kubeconfig == "" {
-			home, err := homedir.Dir()
-			if err != nil {
-				return nil, fmt.Errorf("kubeconfig unable to get home dir. %+v", err)
-			}
-			kubeconfig = filepath.Join(home, ".kube", "config")
-		}
-		var err error
-		// This will simply use the current context in the kubeconfig
-		k8sconfig, err = clientcmd.BuildConfigFromFlags("", kubeconfig)
+		return k8sconfig, nil
+	}
+
+	// Try to fallback to the `KUBECONFIG` env var
+	if kubeconfig == "" {
+		kubeconfig = os.Getenv("KUBECONFIG")
+	}
+	// If the `KUBECONFIG` is empty, default to home dir default kube config path
+	if kubeconfig == "" {
+		home, err := homedir.Dir()
 		if err != nil {
-			return nil, fmt.Errorf("kubeconfig out-of-cluster configuration (%s) error. %+v", kubeconfig, err)
+			return nil, fmt.Errorf("kubeconfig unable to get home dir. %+v", err)
 		}
+		kubeconfig = filepath.Join(home, ".kube", "config")
 	}
 
-	// Create the clientset
-	clientset, err := kubernetes.NewForConfig(k8sconfig)
+	k8sconfig, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
+		&clientcmd.ClientConfigLoadingRules{ExplicitPath: kubeconfig},
+		&clientcmd.ConfigOverrides{CurrentContext: kubecontext},
+	).ClientConfig()
 	if err != nil {
-		return nil, fmt.Errorf("kubernetes new client error. %+v", err)
+		return nil, fmt.Errorf("kubeconfig out-of-cluster configuration (%s, context %q) error. %+v", kubeconfig, kubecontext, err)
 	}
-	return clientset, nil
+	return k8sconfig, nil
 }
@@ -0,0 +1,142 @@
+/*
+Copyright 2022 Cloudical Deutschland GmbH. All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package k8sutil
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes"
+)
+
+// DaemonSetRecreate delete DaemonSet if it exists and create it again
+func DaemonSetRecreate(ctx context.Context, k8sclient kubernetes.Interface, daemonSet *appsv1.DaemonSet) error {
+	if err := DaemonSetDelete(ctx, k8sclient, daemonSet.ObjectMeta.Namespace, daemonSet.ObjectMeta.Name); err != nil {
+		return err
+	}
+
+	if _, err := k8sclient.AppsV1().DaemonSets(daemonSet.ObjectMeta.Namespace).Create(ctx, daemonSet, metav1.CreateOptions{}); err != nil {
+		return err
+	}
+	return nil
+}
+
+// DaemonSetDelete delete DaemonSet by namespace and name if it exists, its Pods are deleted in the background
+func DaemonSetDelete(ctx context.Context, k8sclient kubernetes.Interface, namespace string, name string) error {
+	propagation := metav1.DeletePropagationBackground
+	if err := k8sclient.AppsV1().DaemonSets(namespace).Delete(ctx, name, metav1.DeleteOptions{
+		PropagationPolicy: &propagation,
+	}); err != nil {
+		if errors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	return nil
+}
+
+// DaemonSetDeleteByLabels delete DaemonSets by labels, their Pods are deleted in the background
+func DaemonSetDeleteByLabels(ctx context.Context, k8sclient kubernetes.Interface, namespace string, selectorLabels map[string]string) error {
+	set := labels.Set(selectorLabels)
+
+	daemonSets, err := k8sclient.AppsV1().DaemonSets(namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: set.AsSelector().String(),
+	})
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+
+	for _, daemonSet := range daemonSets.Items {
+		if err := DaemonSetDelete(ctx, k8sclient, namespace, daemonSet.ObjectMeta.Name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WaitForPodsOnNodes wait for a running Pod matching the labels on each of the Nodes and return the Pod name per Node
+// name. An error is returned when not all Nodes have a running Pod before the timeout.
+func WaitForPodsOnNodes(ctx context.Context, k8sclient kubernetes.Interface, namespace string, selectorLabels map[string]string, nodes []string, timeout time.Duration) (map[string]string, error) {
+	selector := labels.SelectorFromSet(selectorLabels)
+
+	wanted := map[string]bool{}
+	for _, node := range nodes {
+		wanted[node] = true
+	}
+
+	// Running Pods by name with their Node name
+	running := map[string]string{}
+	podsOnNodes := func() map[string]string {
+		out := map[string]string{}
+		for podName, node := range running {
+			if wanted[node] {
+				out[node] = podName
+			}
+		}
+		return out
+	}
+
+	funcs := listWatchFuncs{
+		list: func(ctx context.Context, opts metav1.ListOptions) (runtime.Object, error) {
+			return k8sclient.CoreV1().Pods(namespace).List(ctx, opts)
+		},
+		watch: func(ctx context.Context, opts metav1.ListOptions) (watch.Interface, error) {
+			return k8sclient.CoreV1().Pods(namespace).Watch(ctx, opts)
+		},
+	}
+
+	err := watchListUntil(ctx, timeout, funcs, metav1.ListOptions{LabelSelector: selector.String()}, &corev1.Pod{}, nil,
+		func(event watch.Event) (bool, error) {
+			pod, ok := event.Object.(*corev1.Pod)
+			if !ok || !selector.Matches(labels.Set(pod.ObjectMeta.Labels)) {
+				return false, nil
+			}
+
+			delete(running, pod.ObjectMeta.Name)
+			if event.Type != watch.Deleted && pod.ObjectMeta.DeletionTimestamp == nil && pod.Status.Phase == corev1.PodRunning {
+				running[pod.ObjectMeta.Name] = pod.Spec.NodeName
+			}
+
+			return len(podsOnNodes()) == len(wanted), nil
+		})
+	if err == errWatchTimeout {
+		pods := podsOnNodes()
+		missing := []string{}
+		for node := range wanted {
+			if _, ok := pods[node]; !ok {
+				missing = append(missing, node)
+			}
+		}
+		sort.Strings(missing)
+		return nil, fmt.Errorf("no running pods with labels %s on nodes %s after %s", selector.String(), strings.Join(missing, ", "), timeout)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return podsOnNodes(), nil
+}
@@ -0,0 +1,82 @@
+/*
+Copyright 2022 Cloudical Deutschland GmbH. All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package k8sutil
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/remotecommand"
+)
+
+// Executor run commands in containers of Pods
+type Executor interface {
+	// Exec run the command in the container of the Pod, stdout and stderr of the command are written to the writers
+	Exec(ctx context.Context, namespace string, podName string, container string, command []string, stdout io.Writer, stderr io.Writer) error
+}
+
+// podExecutor Executor using the `pods/exec` subresource
+type podExecutor struct {
+	restConfig *rest.Config
+	k8sclient  kubernetes.Interface
+}
+
+// NewExecutor return an Executor running the commands through the `pods/exec` subresource
+func NewExecutor(restConfig *rest.Config, k8sclient kubernetes.Interface) Executor {
+	return &podExecutor{
+		restConfig: restConfig,
+		k8sclient:  k8sclient,
+	}
+}
+
+// Exec run the command in the container of the Pod. When the context is done before the command exited, the context's
+// error is returned. The command itself is not stopped by that, it has to be stopped from inside the container.
+func (e *podExecutor) Exec(ctx context.Context, namespace string, podName string, container string, command []string, stdout io.Writer, stderr io.Writer) error {
+	req := e.k8sclient.CoreV1().RESTClient().Post().
+		Resource("pods").
+		Namespace(namespace).
+		Name(podName).
+		SubResource("exec").
+		VersionedParams(&corev1.PodExecOptions{
+			Container: container,
+			Command:   command,
+			Stdout:    stdout != nil,
+			Stderr:    stderr != nil,
+		}, scheme.ParameterCodec)
+
+	executor, err := remotecommand.NewSPDYExecutor(e.restConfig, "POST", req.URL())
+	if err != nil {
+		return fmt.Errorf("failed to create executor for pod %s/%s. %+v", namespace, podName, err)
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- executor.Stream(remotecommand.StreamOptions{
+			Stdout: stdout,
+			Stderr: stderr,
+		})
+	}()
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
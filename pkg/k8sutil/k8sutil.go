@@ -16,6 +16,7 @@ package k8sutil
 import (
 	"context"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/cloudical-io/ancientt/testers"
@@ -23,18 +24,20 @@ import (
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
 )
 
 // PodRecreate delete Pod if it exists and create it again. If the Pod does not exist, create it.
-func PodRecreate(k8sclient kubernetes.Interface, pod *corev1.Pod, delTimeout int) error {
+func PodRecreate(ctx context.Context, k8sclient kubernetes.Interface, pod *corev1.Pod, delTimeout time.Duration) error {
 	// Delete Pod if it exists
-	if err := PodDelete(k8sclient, pod, delTimeout); err != nil {
+	if err := PodDelete(ctx, k8sclient, pod, delTimeout); err != nil {
 		return err
 	}
 
 	// Create Pod again
-	ctx := context.TODO()
 	if _, err := k8sclient.CoreV1().Pods(pod.ObjectMeta.Namespace).Create(ctx, pod, metav1.CreateOptions{}); err != nil {
 		if !errors.IsAlreadyExists(err) {
 			return err
@@ -44,13 +47,12 @@ func PodRecreate(k8sclient kubernetes.Interface, pod *corev1.Pod, delTimeout int
 	return nil
 }
 
-// PodDelete delete Pod if it exists, wait for it till it has been for custom amount deleted
-func PodDelete(k8sclient kubernetes.Interface, pod *corev1.Pod, timeout int) error {
+// PodDelete delete Pod if it exists and wait for it to be gone till the timeout
+func PodDelete(ctx context.Context, k8sclient kubernetes.Interface, pod *corev1.Pod, timeout time.Duration) error {
 	namespace := pod.ObjectMeta.Namespace
 	podName := pod.ObjectMeta.Name
 
 	// Delete Pod
-	ctx := context.TODO()
 	if err := k8sclient.CoreV1().Pods(namespace).Delete(ctx, podName, metav1.DeleteOptions{}); err != nil {
 		if errors.IsNotFound(err) {
 			return nil
@@ -58,25 +60,24 @@ func PodDelete(k8sclient kubernetes.Interface, pod *corev1.Pod, timeout int) err
 		return err
 	}
 
-	for i := 0; i < timeout; i++ {
-		// Check if Pod still exists
-		ctx := context.TODO()
-		if _, err := k8sclient.CoreV1().Pods(namespace).Get(ctx, podName, metav1.GetOptions{}); err != nil {
-			if errors.IsNotFound(err) {
-				return nil
-			}
-			return err
-		}
-
-		time.Sleep(1 * time.Second)
+	err := watchUntil(ctx, timeout, podListWatchFuncs(k8sclient, namespace), podName, &corev1.Pod{},
+		func(store cache.Store) (bool, error) {
+			// The Pod might be gone already
+			_, exists, err := store.GetByKey(namespace + "/" + podName)
+			return !exists, err
+		},
+		func(event watch.Event) (bool, error) {
+			return event.Type == watch.Deleted && objectName(event) == podName, nil
+		})
+	if err == errWatchTimeout {
+		return fmt.Errorf("pod %s/%s not deleted after %s", namespace, podName, timeout)
 	}
-
-	return fmt.Errorf("pod %s/%s not deleted after 30s", namespace, podName)
+	return err
 }
 
 // PodDeleteByName delete Pod by namespace and name if it exists
-func PodDeleteByName(k8sclient kubernetes.Interface, namespace string, podName string, timeout int) error {
-	return PodDelete(k8sclient, &corev1.Pod{
+func PodDeleteByName(ctx context.Context, k8sclient kubernetes.Interface, namespace string, podName string, timeout time.Duration) error {
+	return PodDelete(ctx, k8sclient, &corev1.Pod{
 		ObjectMeta: metav1.ObjectMeta{
 			Namespace: namespace,
 			Name:      podName,
@@ -85,91 +86,89 @@ func PodDeleteByName(k8sclient kubernetes.Interface, namespace string, podName s
 }
 
 // PodDeleteByLabels delete Pods by labels
-func PodDeleteByLabels(k8sclient kubernetes.Interface, namespace string, selectorLabels map[string]string) error {
+func PodDeleteByLabels(ctx context.Context, k8sclient kubernetes.Interface, namespace string, selectorLabels map[string]string) error {
 	set := labels.Set(selectorLabels)
 
-	ctx := context.TODO()
-	pods, err := k8sclient.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{
+	if err := k8sclient.CoreV1().Pods(namespace).DeleteCollection(ctx, metav1.DeleteOptions{}, metav1.ListOptions{
 		LabelSelector: set.AsSelector().String(),
-	})
-	if err != nil {
+	}); err != nil {
 		if errors.IsNotFound(err) {
 			return nil
 		}
 		return err
 	}
-
-	if pods.Items == nil || len(pods.Items) == 0 {
-		return nil
-	}
-
-	for _, pod := range pods.Items {
-		// Delete Pods by labels
-		ctx := context.TODO()
-		if err := k8sclient.CoreV1().Pods(namespace).Delete(ctx, pod.ObjectMeta.Name, metav1.DeleteOptions{}); err != nil {
-			if errors.IsNotFound(err) {
-				return nil
-			}
-			return err
-		}
-	}
 	return nil
 }
 
-// WaitForPodToRun wait for a Pod to be in phase Running. In case of phase Running, return true and no error
-func WaitForPodToRun(k8sclient kubernetes.Interface, namespace string, podName string, timeout int) (bool, error) {
-	for i := 0; i < timeout; i++ {
-		ctx := context.TODO()
-		pod, err := k8sclient.CoreV1().Pods(namespace).Get(ctx, podName, metav1.GetOptions{})
-		if err != nil {
-			if !errors.IsAlreadyExists(err) {
-				return false, err
-			}
-		}
-		if pod.Status.Phase == corev1.PodRunning {
-			return true, nil
-		}
+// WaitForPodToRun wait for a Pod to be in phase Running, an error is returned when the Pod has not been running before
+// the timeout or has terminated
+func WaitForPodToRun(ctx context.Context, k8sclient kubernetes.Interface, namespace string, podName string, timeout time.Duration) error {
+	return waitForPodPhase(ctx, k8sclient, namespace, podName, timeout, corev1.PodRunning)
+}
 
-		time.Sleep(1 * time.Second)
-	}
+// WaitForPodToSucceed wait for a Pod to be in phase Succeeded, an error is returned when the Pod has not succeeded
+// before the timeout or has failed
+func WaitForPodToSucceed(ctx context.Context, k8sclient kubernetes.Interface, namespace string, podName string, timeout time.Duration) error {
+	return waitForPodPhase(ctx, k8sclient, namespace, podName, timeout, corev1.PodSucceeded)
+}
 
-	return false, nil
+// WaitForPodToRunOrSucceed wait for a Pod to be in phase Running or Succeeded, an error is returned when the Pod has
+// not been in one of the phases before the timeout or has failed
+func WaitForPodToRunOrSucceed(ctx context.Context, k8sclient kubernetes.Interface, namespace string, podName string, timeout time.Duration) error {
+	return waitForPodPhase(ctx, k8sclient, namespace, podName, timeout, corev1.PodRunning, corev1.PodSucceeded)
 }
 
-// WaitForPodToSucceed wait for a Pod to be in phase Succeeded. In case of phase Succeeded, return true and no error
-func WaitForPodToSucceed(k8sclient kubernetes.Interface, namespace string, podName string, timeout int) (bool, error) {
-	for i := 0; i < timeout; i++ {
-		ctx := context.TODO()
-		pod, err := k8sclient.CoreV1().Pods(namespace).Get(ctx, podName, metav1.GetOptions{})
-		if err != nil {
-			return false, err
-		}
-		if pod.Status.Phase == corev1.PodSucceeded {
-			return true, nil
-		}
+// waitForPodPhase watch the Pod till it is in one of the phases, a Pod that has terminated in another phase or has
+// been deleted is an error
+func waitForPodPhase(ctx context.Context, k8sclient kubernetes.Interface, namespace string, podName string, timeout time.Duration, phases ...corev1.PodPhase) error {
+	var phase corev1.PodPhase
+	err := watchUntil(ctx, timeout, podListWatchFuncs(k8sclient, namespace), podName, &corev1.Pod{}, nil,
+		func(event watch.Event) (bool, error) {
+			pod, ok := event.Object.(*corev1.Pod)
+			if !ok || pod.ObjectMeta.Name != podName {
+				return false, nil
+			}
+			if event.Type == watch.Deleted {
+				return false, fmt.Errorf("pod %s/%s has been deleted while waiting for phase %s", namespace, podName, joinPhases(phases))
+			}
 
-		time.Sleep(1 * time.Second)
+			phase = pod.Status.Phase
+			for _, p := range phases {
+				if phase == p {
+					return true, nil
+				}
+			}
+			if phase == corev1.PodSucceeded || phase == corev1.PodFailed {
+				return false, fmt.Errorf("pod %s/%s terminated with phase %s while waiting for phase %s", namespace, podName, phase, joinPhases(phases))
+			}
+			return false, nil
+		})
+	if err == errWatchTimeout {
+		if phase == "" {
+			return fmt.Errorf("pod %s/%s not found in phase %s after %s", namespace, podName, joinPhases(phases), timeout)
+		}
+		return fmt.Errorf("pod %s/%s not in phase %s after %s, phase is %s", namespace, podName, joinPhases(phases), timeout, phase)
 	}
-
-	return false, nil
+	return err
 }
 
-// WaitForPodToRunOrSucceed wait for a Pod to be in phase Running or Succeeded. In case of one of the phases, return true and no error
-func WaitForPodToRunOrSucceed(k8sclient kubernetes.Interface, namespace string, podName string, timeout int) (bool, error) {
-	for i := 0; i < timeout; i++ {
-		ctx := context.TODO()
-		pod, err := k8sclient.CoreV1().Pods(namespace).Get(ctx, podName, metav1.GetOptions{})
-		if err != nil {
-			return false, err
-		}
-		if pod.Status.Phase == corev1.PodRunning || pod.Status.Phase == corev1.PodSucceeded {
-			return true, nil
-		}
-
-		time.Sleep(1 * time.Second)
+func podListWatchFuncs(k8sclient kubernetes.Interface, namespace string) listWatchFuncs {
+	return listWatchFuncs{
+		list: func(ctx context.Context, opts metav1.ListOptions) (runtime.Object, error) {
+			return k8sclient.CoreV1().Pods(namespace).List(ctx, opts)
+		},
+		watch: func(ctx context.Context, opts metav1.ListOptions) (watch.Interface, error) {
+			return k8sclient.CoreV1().Pods(namespace).Watch(ctx, opts)
+		},
 	}
+}
 
-	return false, nil
+func joinPhases(phases []corev1.PodPhase) string {
+	names := []string{}
+	for _, phase := range phases {
+		names = append(names, string(phase))
+	}
+	return strings.Join(names, " or ")
 }
 
 // PortsListToPorts PortList testers.Port to Kubernetes []corev1.ContainerPort conversion (for TCP and UDP)
@@ -0,0 +1,159 @@
+/*
+Copyright 2022 Cloudical Deutschland GmbH. All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package k8sutil
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/cloudical-io/ancientt/tests/k8s"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+func createPod(t *testing.T, k8sclient kubernetes.Interface, name string, phase corev1.PodPhase) *corev1.Pod {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "ancientt",
+			Name:      name,
+		},
+		Status: corev1.PodStatus{
+			Phase: phase,
+		},
+	}
+	pod, err := k8sclient.CoreV1().Pods(pod.ObjectMeta.Namespace).Create(context.Background(), pod, metav1.CreateOptions{})
+	require.Nil(t, err)
+	return pod
+}
+
+// setPodPhase update the phase of the Pod till done is closed, as the watch might not be established on the first update
+func setPodPhase(k8sclient kubernetes.Interface, pod *corev1.Pod, phase corev1.PodPhase, done <-chan struct{}) {
+	pod = pod.DeepCopy()
+	pod.Status.Phase = phase
+	for {
+		select {
+		case <-done:
+			return
+		case <-time.After(10 * time.Millisecond):
+			k8sclient.CoreV1().Pods(pod.ObjectMeta.Namespace).Update(context.Background(), pod, metav1.UpdateOptions{})
+		}
+	}
+}
+
+func TestWaitForPodToRun(t *testing.T) {
+	clientset, err := k8s.NewClient(0)
+	require.Nil(t, err)
+
+	createPod(t, clientset, "other", corev1.PodRunning)
+	pod := createPod(t, clientset, "server", corev1.PodPending)
+
+	done := make(chan struct{})
+	go setPodPhase(clientset, pod, corev1.PodRunning, done)
+
+	err = WaitForPodToRun(context.Background(), clientset, "ancientt", "server", 5*time.Second)
+	close(done)
+	assert.Nil(t, err)
+}
+
+func TestWaitForPodToRunTimeout(t *testing.T) {
+	clientset, err := k8s.NewClient(0)
+	require.Nil(t, err)
+
+	createPod(t, clientset, "server", corev1.PodPending)
+
+	err = WaitForPodToRun(context.Background(), clientset, "ancientt", "server", 100*time.Millisecond)
+	require.NotNil(t, err)
+	assert.Equal(t, "pod ancientt/server not in phase Running after 100ms, phase is Pending", err.Error())
+
+	err = WaitForPodToRun(context.Background(), clientset, "ancientt", "missing", 100*time.Millisecond)
+	require.NotNil(t, err)
+	assert.Equal(t, "pod ancientt/missing not found in phase Running after 100ms", err.Error())
+}
+
+func TestWaitForPodToSucceedFailed(t *testing.T) {
+	clientset, err := k8s.NewClient(0)
+	require.Nil(t, err)
+
+	createPod(t, clientset, "client", corev1.PodFailed)
+
+	err = WaitForPodToSucceed(context.Background(), clientset, "ancientt", "client", 5*time.Second)
+	require.NotNil(t, err)
+	assert.Equal(t, "pod ancientt/client terminated with phase Failed while waiting for phase Succeeded", err.Error())
+
+	createPod(t, clientset, "succeeded", corev1.PodSucceeded)
+	assert.Nil(t, WaitForPodToRunOrSucceed(context.Background(), clientset, "ancientt", "succeeded", 5*time.Second))
+}
+
+func TestWaitForPodToRunCancelled(t *testing.T) {
+	clientset, err := k8s.NewClient(0)
+	require.Nil(t, err)
+
+	createPod(t, clientset, "server", corev1.PodPending)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		cancel()
+	}()
+
+	err = WaitForPodToRun(ctx, clientset, "ancientt", "server", 5*time.Second)
+	assert.Equal(t, context.Canceled, err)
+}
+
+func TestPodDelete(t *testing.T) {
+	clientset, err := k8s.NewClient(0)
+	require.Nil(t, err)
+
+	pod := createPod(t, clientset, "server", corev1.PodRunning)
+
+	require.Nil(t, PodDelete(context.Background(), clientset, pod, 5*time.Second))
+	_, err = clientset.CoreV1().Pods("ancientt").Get(context.Background(), "server", metav1.GetOptions{})
+	assert.NotNil(t, err)
+
+	// Deleting a Pod that does not exist is no error
+	assert.Nil(t, PodDeleteByName(context.Background(), clientset, "ancientt", "server", 5*time.Second))
+
+	require.Nil(t, PodRecreate(context.Background(), clientset, pod, 5*time.Second))
+	require.Nil(t, PodRecreate(context.Background(), clientset, pod, 5*time.Second))
+	_, err = clientset.CoreV1().Pods("ancientt").Get(context.Background(), "server", metav1.GetOptions{})
+	assert.Nil(t, err)
+}
+
+func TestWaitForPodsOnNodes(t *testing.T) {
+	clientset, err := k8s.NewClient(0)
+	require.Nil(t, err)
+
+	selectorLabels := map[string]string{TaskIDLabel: "agents"}
+	for i, phase := range []corev1.PodPhase{corev1.PodRunning, corev1.PodPending} {
+		_, err := clientset.CoreV1().Pods("ancientt").Create(context.Background(), &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "ancientt", Name: fmt.Sprintf("agent-%d", i), Labels: selectorLabels},
+			Spec:       corev1.PodSpec{NodeName: fmt.Sprintf("node-%d", i)},
+			Status:     corev1.PodStatus{Phase: phase},
+		}, metav1.CreateOptions{})
+		require.Nil(t, err)
+	}
+
+	pods, err := WaitForPodsOnNodes(context.Background(), clientset, "ancientt", selectorLabels, []string{"node-0"}, 5*time.Second)
+	require.Nil(t, err)
+	assert.Equal(t, map[string]string{"node-0": "agent-0"}, pods)
+
+	_, err = WaitForPodsOnNodes(context.Background(), clientset, "ancientt", selectorLabels, []string{"node-0", "node-1", "node-2"}, 100*time.Millisecond)
+	require.NotNil(t, err)
+	assert.Equal(t, "no running pods with labels ancientt/task-id=agents on nodes node-1, node-2 after 100ms", err.Error())
+}
@@ -16,6 +16,8 @@ package k8sutil
 const (
 	// TaskIDLabel label for the task-id
 	TaskIDLabel = "ancientt/task-id"
+	// ClusterLabel label with the name of the cluster of a host
+	ClusterLabel = "ancientt/cluster"
 )
 
 // GetLabels return a default set of labels for "any" object ancientt is going to create.
@@ -0,0 +1,89 @@
+/*
+Copyright 2022 Cloudical Deutschland GmbH. All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package k8sutil
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	// MultusNetworksAnnotation annotation with the NetworkAttachmentDefinitions to attach to a Pod
+	MultusNetworksAnnotation = "k8s.v1.cni.cncf.io/networks"
+	// MultusNetworkStatusAnnotation annotation with the status of the networks attached to a Pod
+	MultusNetworkStatusAnnotation = "k8s.v1.cni.cncf.io/network-status"
+	// multusNetworksStatusAnnotation deprecated network status annotation set by older Multus versions
+	multusNetworksStatusAnnotation = "k8s.v1.cni.cncf.io/networks-status"
+)
+
+// NetworkStatus status of a network attached to a Pod from the Multus network status annotation
+type NetworkStatus struct {
+	Name      string   `json:"name"`
+	Interface string   `json:"interface,omitempty"`
+	IPs       []string `json:"ips,omitempty"`
+	Default   bool     `json:"default,omitempty"`
+}
+
+// PodNetworkStatus return the network status of the networks attached to the Pod by Multus
+func PodNetworkStatus(pod *corev1.Pod) ([]NetworkStatus, error) {
+	annotation, ok := pod.ObjectMeta.Annotations[MultusNetworkStatusAnnotation]
+	if !ok {
+		annotation, ok = pod.ObjectMeta.Annotations[multusNetworksStatusAnnotation]
+	}
+	if !ok {
+		return nil, fmt.Errorf("pod %s/%s has no %s annotation", pod.ObjectMeta.Namespace, pod.ObjectMeta.Name, MultusNetworkStatusAnnotation)
+	}
+
+	status := []NetworkStatus{}
+	if err := json.Unmarshal([]byte(annotation), &status); err != nil {
+		return nil, fmt.Errorf("failed to parse network status of pod %s/%s. %+v", pod.ObjectMeta.Namespace, pod.ObjectMeta.Name, err)
+	}
+	return status, nil
+}
+
+// PodNetworkIPs return the IPs of the Pod on the network. The network is `NAME` (in the namespace of the Pod),
+// `NAMESPACE/NAME` and optionally suffixed with `@INTERFACE` to select the interface in the Pod.
+func PodNetworkIPs(pod *corev1.Pod, network string) ([]string, error) {
+	status, err := PodNetworkStatus(pod)
+	if err != nil {
+		return nil, err
+	}
+
+	name, iface := network, ""
+	if i := strings.Index(network, "@"); i >= 0 {
+		name, iface = network[:i], network[i+1:]
+	}
+	if !strings.Contains(name, "/") {
+		name = pod.ObjectMeta.Namespace + "/" + name
+	}
+
+	for _, s := range status {
+		statusName := s.Name
+		if !strings.Contains(statusName, "/") {
+			statusName = pod.ObjectMeta.Namespace + "/" + statusName
+		}
+		if statusName != name || (iface != "" && s.Interface != iface) {
+			continue
+		}
+		if len(s.IPs) == 0 {
+			return nil, fmt.Errorf("network %s of pod %s/%s has no IPs", network, pod.ObjectMeta.Namespace, pod.ObjectMeta.Name)
+		}
+		return s.IPs, nil
+	}
+
+	return nil, fmt.Errorf("network %s not found in network status of pod %s/%s", network, pod.ObjectMeta.Namespace, pod.ObjectMeta.Name)
+}
@@ -0,0 +1,75 @@
+/*
+Copyright 2022 Cloudical Deutschland GmbH. All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package k8sutil
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestPodNetworkIPs(t *testing.T) {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "server",
+			Namespace: "ancientt",
+			Annotations: map[string]string{
+				MultusNetworkStatusAnnotation: `[
+  {"name": "cbr0", "interface": "eth0", "ips": ["10.244.1.10"], "default": true},
+  {"name": "ancientt/sriov", "interface": "net1", "ips": ["192.168.100.10"]},
+  {"name": "ancientt/sriov", "interface": "net2", "ips": ["192.168.101.10"]},
+  {"name": "storage/macvlan", "interface": "net3", "ips": ["192.168.200.10", "fd00:200::a"]}
+]`,
+			},
+		},
+	}
+
+	tests := []struct {
+		network  string
+		expected []string
+	}{
+		{"sriov", []string{"192.168.100.10"}},
+		{"ancientt/sriov@net2", []string{"192.168.101.10"}},
+		{"storage/macvlan", []string{"192.168.200.10", "fd00:200::a"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.network, func(t *testing.T) {
+			ips, err := PodNetworkIPs(pod, tt.network)
+			require.Nil(t, err)
+			assert.Equal(t, tt.expected, ips)
+		})
+	}
+
+	// Network of another namespace and interface that does not exist
+	_, err := PodNetworkIPs(pod, "macvlan")
+	assert.NotNil(t, err)
+	_, err = PodNetworkIPs(pod, "sriov@net5")
+	assert.NotNil(t, err)
+
+	// Deprecated annotation of older Multus versions
+	old := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+		Namespace:   "ancientt",
+		Annotations: map[string]string{"k8s.v1.cni.cncf.io/networks-status": `[{"name": "sriov", "ips": ["192.168.100.11"]}]`},
+	}}
+	ips, err := PodNetworkIPs(old, "sriov")
+	require.Nil(t, err)
+	assert.Equal(t, []string{"192.168.100.11"}, ips)
+
+	_, err = PodNetworkIPs(&corev1.Pod{}, "sriov")
+	assert.NotNil(t, err)
+}
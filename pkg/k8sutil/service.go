@@ -0,0 +1,153 @@
+/*
+Copyright 2022 Cloudical Deutschland GmbH. All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package k8sutil
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/cloudical-io/ancientt/testers"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes"
+)
+
+// ServiceRecreate delete Service if it exists and create it again. The created Service is returned to have the
+// ClusterIP and NodePorts allocated by Kubernetes.
+func ServiceRecreate(ctx context.Context, k8sclient kubernetes.Interface, service *corev1.Service) (*corev1.Service, error) {
+	if err := ServiceDelete(ctx, k8sclient, service.ObjectMeta.Namespace, service.ObjectMeta.Name); err != nil {
+		return nil, err
+	}
+
+	return k8sclient.CoreV1().Services(service.ObjectMeta.Namespace).Create(ctx, service, metav1.CreateOptions{})
+}
+
+// ServiceDelete delete Service by namespace and name if it exists
+func ServiceDelete(ctx context.Context, k8sclient kubernetes.Interface, namespace string, name string) error {
+	if err := k8sclient.CoreV1().Services(namespace).Delete(ctx, name, metav1.DeleteOptions{}); err != nil {
+		if errors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	return nil
+}
+
+// ServiceDeleteByLabels delete Services by labels
+func ServiceDeleteByLabels(ctx context.Context, k8sclient kubernetes.Interface, namespace string, selectorLabels map[string]string) error {
+	set := labels.Set(selectorLabels)
+
+	services, err := k8sclient.CoreV1().Services(namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: set.AsSelector().String(),
+	})
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+
+	for _, service := range services.Items {
+		if err := ServiceDelete(ctx, k8sclient, namespace, service.ObjectMeta.Name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WaitForServiceEndpoints wait for the Endpoints of a Service to have a ready address, an error is returned when there
+// is no ready address before the timeout
+func WaitForServiceEndpoints(ctx context.Context, k8sclient kubernetes.Interface, namespace string, name string, timeout time.Duration) error {
+	funcs := listWatchFuncs{
+		list: func(ctx context.Context, opts metav1.ListOptions) (runtime.Object, error) {
+			return k8sclient.CoreV1().Endpoints(namespace).List(ctx, opts)
+		},
+		watch: func(ctx context.Context, opts metav1.ListOptions) (watch.Interface, error) {
+			return k8sclient.CoreV1().Endpoints(namespace).Watch(ctx, opts)
+		},
+	}
+
+	err := watchUntil(ctx, timeout, funcs, name, &corev1.Endpoints{}, nil,
+		func(event watch.Event) (bool, error) {
+			endpoints, ok := event.Object.(*corev1.Endpoints)
+			if !ok || endpoints.ObjectMeta.Name != name || event.Type == watch.Deleted {
+				return false, nil
+			}
+			for _, subset := range endpoints.Subsets {
+				if len(subset.Addresses) > 0 {
+					return true, nil
+				}
+			}
+			return false, nil
+		})
+	if err == errWatchTimeout {
+		return fmt.Errorf("service %s/%s has no ready endpoints after %s", namespace, name, timeout)
+	}
+	return err
+}
+
+// WaitForServiceLoadBalancer wait for the LoadBalancer of a Service to have an ingress IP or hostname and return the
+// Service, an error is returned when the LoadBalancer has no ingress before the timeout
+func WaitForServiceLoadBalancer(ctx context.Context, k8sclient kubernetes.Interface, namespace string, name string, timeout time.Duration) (*corev1.Service, error) {
+	funcs := listWatchFuncs{
+		list: func(ctx context.Context, opts metav1.ListOptions) (runtime.Object, error) {
+			return k8sclient.CoreV1().Services(namespace).List(ctx, opts)
+		},
+		watch: func(ctx context.Context, opts metav1.ListOptions) (watch.Interface, error) {
+			return k8sclient.CoreV1().Services(namespace).Watch(ctx, opts)
+		},
+	}
+
+	var service *corev1.Service
+	err := watchUntil(ctx, timeout, funcs, name, &corev1.Service{}, nil,
+		func(event watch.Event) (bool, error) {
+			svc, ok := event.Object.(*corev1.Service)
+			if !ok || svc.ObjectMeta.Name != name || event.Type == watch.Deleted {
+				return false, nil
+			}
+			if len(svc.Status.LoadBalancer.Ingress) == 0 {
+				return false, nil
+			}
+			service = svc
+			return true, nil
+		})
+	if err == errWatchTimeout {
+		return nil, fmt.Errorf("service %s/%s has no loadbalancer ingress after %s", namespace, name, timeout)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return service, nil
+}
+
+// PortsListToServicePorts PortList testers.Port to Kubernetes []corev1.ServicePort conversion (for TCP and UDP)
+func PortsListToServicePorts(list testers.Ports) []corev1.ServicePort {
+	ports := []corev1.ServicePort{}
+	for _, containerPort := range PortsListToPorts(list) {
+		ports = append(ports, corev1.ServicePort{
+			Name:       fmt.Sprintf("%s-%d", strings.ToLower(string(containerPort.Protocol)), containerPort.ContainerPort),
+			Port:       containerPort.ContainerPort,
+			Protocol:   containerPort.Protocol,
+			TargetPort: intstr.FromInt(int(containerPort.ContainerPort)),
+		})
+	}
+	return ports
+}
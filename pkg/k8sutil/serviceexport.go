@@ -0,0 +1,87 @@
+/*
+Copyright 2022 Cloudical Deutschland GmbH. All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package k8sutil
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+)
+
+// ServiceExportResource Multi-Cluster Services API `ServiceExport` resource, e.g., implemented by Submariner
+var ServiceExportResource = schema.GroupVersionResource{
+	Group:    "multicluster.x-k8s.io",
+	Version:  "v1alpha1",
+	Resource: "serviceexports",
+}
+
+// ServiceExportRecreate delete the ServiceExport of the Service if it exists and create it again
+func ServiceExportRecreate(ctx context.Context, dynclient dynamic.Interface, namespace string, name string, objLabels map[string]string) error {
+	if err := ServiceExportDelete(ctx, dynclient, namespace, name); err != nil {
+		return err
+	}
+
+	export := &unstructured.Unstructured{}
+	export.SetAPIVersion(ServiceExportResource.GroupVersion().String())
+	export.SetKind("ServiceExport")
+	export.SetNamespace(namespace)
+	export.SetName(name)
+	export.SetLabels(objLabels)
+
+	if _, err := dynclient.Resource(ServiceExportResource).Namespace(namespace).Create(ctx, export, metav1.CreateOptions{}); err != nil {
+		return fmt.Errorf("failed to create serviceexport %s/%s. %+v", namespace, name, err)
+	}
+	return nil
+}
+
+// ServiceExportDelete delete ServiceExport by namespace and name if it exists
+func ServiceExportDelete(ctx context.Context, dynclient dynamic.Interface, namespace string, name string) error {
+	if err := dynclient.Resource(ServiceExportResource).Namespace(namespace).Delete(ctx, name, metav1.DeleteOptions{}); err != nil {
+		if errors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	return nil
+}
+
+// ServiceExportDeleteByLabels delete ServiceExports by labels, nothing is done when the ServiceExport resource does not
+// exist in the cluster
+func ServiceExportDeleteByLabels(ctx context.Context, dynclient dynamic.Interface, namespace string, selectorLabels map[string]string) error {
+	set := labels.Set(selectorLabels)
+
+	exports, err := dynclient.Resource(ServiceExportResource).Namespace(namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: set.AsSelector().String(),
+	})
+	if err != nil {
+		if errors.IsNotFound(err) || meta.IsNoMatchError(err) {
+			return nil
+		}
+		return err
+	}
+
+	for _, export := range exports.Items {
+		if err := ServiceExportDelete(ctx, dynclient, namespace, export.GetName()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+/*
+Copyright 2022 Cloudical Deutschland GmbH. All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package k8sutil
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"gopkg.in/yaml.v2"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/strategicpatch"
+	sigsyaml "sigs.k8s.io/yaml"
+)
+
+// PodApplyTemplate strategic merge the Pod template on top of the Pod and return the resulting Pod. Lists are merged
+// like `kubectl patch` does it, e.g., containers by their name. Unknown fields in the template are an error.
+func PodApplyTemplate(pod *corev1.Pod, template map[string]interface{}) (*corev1.Pod, error) {
+	if len(template) == 0 {
+		return pod, nil
+	}
+
+	// The template has been parsed as YAML, so it is converted to JSON through YAML again
+	raw, err := yaml.Marshal(template)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal pod template. %+v", err)
+	}
+	patch, err := sigsyaml.YAMLToJSON(raw)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert pod template to json. %+v", err)
+	}
+
+	original, err := json.Marshal(pod)
+	if err != nil {
+		return nil, err
+	}
+
+	merged, err := strategicpatch.StrategicMergePatch(original, patch, corev1.Pod{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to merge pod template. %+v", err)
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(merged))
+	decoder.DisallowUnknownFields()
+	out := &corev1.Pod{}
+	if err := decoder.Decode(out); err != nil {
+		return nil, fmt.Errorf("invalid pod template. %+v", err)
+	}
+
+	return out, nil
+}
@@ -0,0 +1,88 @@
+/*
+Copyright 2022 Cloudical Deutschland GmbH. All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package k8sutil
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/tools/cache"
+	watchtools "k8s.io/client-go/tools/watch"
+)
+
+// errWatchTimeout the timeout has been reached before the watch condition was met
+var errWatchTimeout = errors.New("watch timed out")
+
+// listWatchFuncs list and watch funcs of a typed client, e.g., `Pods(namespace).List` and `Pods(namespace).Watch`
+type listWatchFuncs struct {
+	list  func(ctx context.Context, opts metav1.ListOptions) (runtime.Object, error)
+	watch func(ctx context.Context, opts metav1.ListOptions) (watch.Interface, error)
+}
+
+// watchUntil watch the object with the name until the condition returns true. When the precondition returns true on
+// the synced cache, the condition is not waited for. errWatchTimeout is returned when the timeout is reached, the error
+// of the context when it has been cancelled.
+func watchUntil(ctx context.Context, timeout time.Duration, funcs listWatchFuncs, name string, objType runtime.Object, precondition watchtools.PreconditionFunc, condition watchtools.ConditionFunc) error {
+	// Only list and watch the object with the name
+	selectors := metav1.ListOptions{
+		FieldSelector: fields.OneTermEqualSelector("metadata.name", name).String(),
+	}
+	return watchListUntil(ctx, timeout, funcs, selectors, objType, precondition, condition)
+}
+
+// watchListUntil watch the objects matching the field and label selectors until the condition returns true, see
+// watchUntil
+func watchListUntil(ctx context.Context, timeout time.Duration, funcs listWatchFuncs, selectors metav1.ListOptions, objType runtime.Object, precondition watchtools.PreconditionFunc, condition watchtools.ConditionFunc) error {
+	watchCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	lw := &cache.ListWatch{
+		ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+			options.FieldSelector = selectors.FieldSelector
+			options.LabelSelector = selectors.LabelSelector
+			return funcs.list(watchCtx, options)
+		},
+		WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+			options.FieldSelector = selectors.FieldSelector
+			options.LabelSelector = selectors.LabelSelector
+			return funcs.watch(watchCtx, options)
+		},
+	}
+
+	_, err := watchtools.UntilWithSync(watchCtx, lw, objType, precondition, condition)
+	if err == nil {
+		return nil
+	}
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	if watchCtx.Err() != nil {
+		return errWatchTimeout
+	}
+	return err
+}
+
+// objectName return the name of the object of the watch event
+func objectName(event watch.Event) string {
+	obj, ok := event.Object.(metav1.Object)
+	if !ok {
+		return ""
+	}
+	return obj.GetName()
+}
@@ -105,6 +105,7 @@ type Sum struct {
 // End
 type End struct {
 	Streams               []EndStream           `json:"streams"`
+	Sum                   EndSum                `json:"sum"`
 	SumSent               SumSent               `json:"sum_sent"`
 	SumReceived           SumReceived           `json:"sum_received"`
 	CPUUtilizationPercent CPUUtilizationPercent `json:"cpu_utilization_percent"`
@@ -143,6 +144,19 @@ type Receiver struct {
 	BitsPerSecond float64 `json:"bits_per_second"`
 }
 
+// EndSum end sum, only returned for UDP tests
+type EndSum struct {
+	Start         float64 `json:"start"`
+	End           float64 `json:"end"`
+	Seconds       float64 `json:"seconds"`
+	Bytes         int64   `json:"bytes"`
+	BitsPerSecond float64 `json:"bits_per_second"`
+	JitterMS      float64 `json:"jitter_ms"`
+	LostPackets   int64   `json:"lost_packets"`
+	Packets       int64   `json:"packets"`
+	LostPercent   float64 `json:"lost_percent"`
+}
+
 // SumSent
 type SumSent struct {
 	Start         float64 `json:"start"`
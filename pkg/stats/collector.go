@@ -0,0 +1,119 @@
+/*
+Copyright 2022 Cloudical Deutschland GmbH. All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package stats
+
+import (
+	"github.com/cloudical-io/ancientt/outputs"
+	"github.com/cloudical-io/ancientt/pkg/util"
+)
+
+// Pair server / client host pair and the IP family the test has been run with
+type Pair struct {
+	ServerHost string
+	ClientHost string
+	IPFamily   string
+}
+
+// Collector collects the values of columns per server / client host pair from tester results
+type Collector struct {
+	columns     []string
+	pairs       []Pair
+	values      map[Pair]map[string][]float64
+	columnTypes map[string]outputs.DataType
+}
+
+// NewCollector return a new Collector for the given columns
+func NewCollector(columns ...string) *Collector {
+	return &Collector{
+		columns:     columns,
+		pairs:       []Pair{},
+		values:      map[Pair]map[string][]float64{},
+		columnTypes: map[string]outputs.DataType{},
+	}
+}
+
+// Add collect the values of the columns from the given data. Only tester results and end results are taken into
+// account, when both contain a column with the same name (e.g., `seconds`), only the end results are used for that
+// column independent of the order the data arrives in.
+func (c *Collector) Add(data outputs.Data) {
+	if data.Type != outputs.DataTypeResult && data.Type != outputs.DataTypeEnd {
+		return
+	}
+	table, ok := data.Data.(*outputs.Table)
+	if !ok {
+		return
+	}
+
+	pair := Pair{
+		ServerHost: data.ServerHost,
+		ClientHost: data.ClientHost,
+		IPFamily:   data.IPFamily,
+	}
+	c.addPair(pair)
+
+	for _, column := range c.columns {
+		index, err := table.GetHeaderIndexByName(column)
+		if err != nil || index == -1 {
+			continue
+		}
+		if dataType, ok := c.columnTypes[column]; ok && dataType != data.Type {
+			if data.Type != outputs.DataTypeEnd {
+				continue
+			}
+			// The end results replace the tester results values collected so far
+			c.resetColumn(column)
+		}
+		c.columnTypes[column] = data.Type
+
+		for _, row := range table.Rows {
+			if len(row) <= index || row[index] == nil {
+				continue
+			}
+			value, err := util.CastNumberToFloat64(row[index].Value)
+			if err != nil {
+				continue
+			}
+			c.AddValues(pair, column, value)
+		}
+	}
+}
+
+// AddValues add values of a column for the given pair
+func (c *Collector) AddValues(pair Pair, column string, values ...float64) {
+	c.addPair(pair)
+	c.values[pair][column] = append(c.values[pair][column], values...)
+}
+
+func (c *Collector) resetColumn(column string) {
+	for _, values := range c.values {
+		delete(values, column)
+	}
+}
+
+func (c *Collector) addPair(pair Pair) {
+	if _, ok := c.values[pair]; !ok {
+		c.pairs = append(c.pairs, pair)
+		c.values[pair] = map[string][]float64{}
+	}
+}
+
+// Pairs return the pairs in the order they have been added
+func (c *Collector) Pairs() []Pair {
+	return c.pairs
+}
+
+// Values return the collected values of a column for the given pair
+func (c *Collector) Values(pair Pair, column string) []float64 {
+	return c.values[pair][column]
+}
@@ -0,0 +1,63 @@
+/*
+Copyright 2022 Cloudical Deutschland GmbH. All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package stats
+
+import (
+	"testing"
+
+	"github.com/cloudical-io/ancientt/outputs"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestData(dataType outputs.DataType, clientHost string, seconds float64, bitsPerSecond float64) outputs.Data {
+	return outputs.Data{
+		Type:       dataType,
+		ServerHost: "server1",
+		ClientHost: clientHost,
+		IPFamily:   "v4",
+		Data: &outputs.Table{
+			Headers: []*outputs.Row{{Value: "seconds"}, {Value: "bits_per_second"}},
+			Rows:    [][]*outputs.Row{{{Value: seconds}, {Value: bitsPerSecond}}},
+		},
+	}
+}
+
+func TestCollectorPrefersEndResults(t *testing.T) {
+	pair1 := Pair{ServerHost: "server1", ClientHost: "client1", IPFamily: "v4"}
+	pair2 := Pair{ServerHost: "server1", ClientHost: "client2", IPFamily: "v4"}
+
+	// The result and end tables both carry the seconds column, the end results are used in either order
+	for _, order := range [][]outputs.DataType{
+		{outputs.DataTypeResult, outputs.DataTypeEnd},
+		{outputs.DataTypeEnd, outputs.DataTypeResult},
+	} {
+		c := NewCollector("seconds", "bits_per_second")
+		for _, clientHost := range []string{"client1", "client2"} {
+			for _, dataType := range order {
+				if dataType == outputs.DataTypeEnd {
+					c.Add(newTestData(dataType, clientHost, 10, 2e9))
+				} else {
+					c.Add(newTestData(dataType, clientHost, 1, 1e9))
+				}
+			}
+		}
+		// Other data types are ignored
+		c.Add(newTestData(outputs.DataTypeSummary, "client1", 100, 3e9))
+
+		assert.Equal(t, []Pair{pair1, pair2}, c.Pairs())
+		assert.Equal(t, []float64{10}, c.Values(pair1, "seconds"))
+		assert.Equal(t, []float64{10}, c.Values(pair2, "seconds"))
+		assert.Equal(t, []float64{2e9}, c.Values(pair1, "bits_per_second"))
+	}
+}
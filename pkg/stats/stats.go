@@ -0,0 +1,102 @@
+/*
+Copyright 2022 Cloudical Deutschland GmbH. All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package stats
+
+import (
+	"math"
+	"sort"
+)
+
+// Summary min, avg, max and percentiles of a list of values
+type Summary struct {
+	Count int
+	Min   float64
+	Avg   float64
+	Max   float64
+	P50   float64
+	P95   float64
+	P99   float64
+}
+
+// Summarize calculate the Summary for the given values
+func Summarize(values []float64) Summary {
+	if len(values) == 0 {
+		return Summary{}
+	}
+
+	sorted := make([]float64, len(values))
+	copy(sorted, values)
+	sort.Float64s(sorted)
+
+	var sum float64
+	for _, v := range sorted {
+		sum += v
+	}
+
+	return Summary{
+		Count: len(sorted),
+		Min:   sorted[0],
+		Avg:   sum / float64(len(sorted)),
+		Max:   sorted[len(sorted)-1],
+		P50:   Percentile(sorted, 50),
+		P95:   Percentile(sorted, 95),
+		P99:   Percentile(sorted, 99),
+	}
+}
+
+// Get return the value of the given aggregation (`count`, `min`, `avg`, `max`, `p50`, `p95` or `p99`), false is
+// returned when the aggregation is unknown
+func (s Summary) Get(aggregation string) (float64, bool) {
+	switch aggregation {
+	case "count":
+		return float64(s.Count), true
+	case "min":
+		return s.Min, true
+	case "avg":
+		return s.Avg, true
+	case "max":
+		return s.Max, true
+	case "p50":
+		return s.P50, true
+	case "p95":
+		return s.P95, true
+	case "p99":
+		return s.P99, true
+	}
+
+	return 0, false
+}
+
+// Percentile return the p-th percentile (0-100) of the given sorted values, interpolating linearly between the
+// closest ranks
+func Percentile(sorted []float64, p float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	if p <= 0 {
+		return sorted[0]
+	}
+	if p >= 100 {
+		return sorted[len(sorted)-1]
+	}
+
+	rank := p / 100 * float64(len(sorted)-1)
+	lower := int(math.Floor(rank))
+	upper := int(math.Ceil(rank))
+	if lower == upper {
+		return sorted[lower]
+	}
+
+	return sorted[lower] + (sorted[upper]-sorted[lower])*(rank-float64(lower))
+}
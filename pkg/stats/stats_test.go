@@ -0,0 +1,42 @@
+/*
+Copyright 2022 Cloudical Deutschland GmbH. All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package stats
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSummarize(t *testing.T) {
+	s := Summarize([]float64{})
+	assert.Equal(t, 0, s.Count)
+
+	s = Summarize([]float64{5, 1, 4, 2, 3})
+	assert.Equal(t, 5, s.Count)
+	assert.Equal(t, float64(1), s.Min)
+	assert.Equal(t, float64(3), s.Avg)
+	assert.Equal(t, float64(5), s.Max)
+	assert.Equal(t, float64(3), s.P50)
+	assert.InDelta(t, 4.8, s.P95, 0.0001)
+	assert.InDelta(t, 4.96, s.P99, 0.0001)
+}
+
+func TestPercentile(t *testing.T) {
+	sorted := []float64{10, 20, 30, 40}
+	assert.Equal(t, float64(10), Percentile(sorted, 0))
+	assert.Equal(t, float64(25), Percentile(sorted, 50))
+	assert.Equal(t, float64(40), Percentile(sorted, 100))
+	assert.Equal(t, float64(0), Percentile([]float64{}, 50))
+}
@@ -43,7 +43,7 @@ func CastNumberToFloat64(in interface{}) (float64, error) {
 	case float64:
 		return in.(float64), nil
 	case float32:
-		return in.(float64), nil
+		return float64(in.(float32)), nil
 	case int:
 		return float64(in.(int)), nil
 	case int8:
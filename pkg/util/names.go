@@ -14,6 +14,7 @@ limitations under the License.
 package util
 
 import (
+	"crypto/sha256"
 	"fmt"
 	"time"
 )
@@ -29,9 +30,15 @@ const (
 )
 
 // GetPNameFromTask get a "persistent" name for a task
-// This is done by calculating the checksums of the used names.
-func GetPNameFromTask(round int, hostname string, command string, role PNameRole, testStartTime time.Time) string {
-	return fmt.Sprintf("ancientt-%s-%s-%d", role, command, testStartTime.UnixNano())
+// This is done by calculating the checksums of the used names. The index is the index of the server task in the round
+// and the subIndex the index of the client task of the server task, so the names of concurrently running tasks don't
+// collide. The IP family of a client task is added to the name, the server tasks have no IP family.
+func GetPNameFromTask(round int, index int, subIndex int, hostname string, command string, ipFamily string, role PNameRole, testStartTime time.Time) string {
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%d-%d-%d-%s-%s-%d", round, index, subIndex, hostname, ipFamily, testStartTime.UnixNano())))
+	if ipFamily == "" {
+		return fmt.Sprintf("ancientt-%s-%s-%x", role, command, sum[:6])
+	}
+	return fmt.Sprintf("ancientt-%s-%s-%s-%x", role, command, ipFamily, sum[:6])
 }
 
 // GetTaskName get a task name
@@ -0,0 +1,44 @@
+/*
+Copyright 2022 Cloudical Deutschland GmbH. All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetPNameFromTask(t *testing.T) {
+	start := time.Now()
+
+	name := GetPNameFromTask(0, 1, 2, "node-1", "iperf3", "v4", PNameRoleClient, start)
+	assert.Equal(t, name, GetPNameFromTask(0, 1, 2, "node-1", "iperf3", "v4", PNameRoleClient, start))
+	assert.Regexp(t, "^ancientt-client-iperf3-v4-[0-9a-f]{12}$", name)
+	assert.Regexp(t, "^ancientt-server-iperf3-[0-9a-f]{12}$", GetPNameFromTask(0, 1, 0, "node-1", "iperf3", "", PNameRoleServer, start))
+	// The names are used as service names and label values, which are limited to 63 characters
+	assert.LessOrEqual(t, len(GetPNameFromTask(0, 0, 0, "node-1", "pingparsing", "v6", PNameRoleClient, start)), 63)
+
+	names := map[string]bool{name: true}
+	for _, other := range []string{
+		GetPNameFromTask(1, 1, 2, "node-1", "iperf3", "v4", PNameRoleClient, start),
+		GetPNameFromTask(0, 0, 2, "node-1", "iperf3", "v4", PNameRoleClient, start),
+		GetPNameFromTask(0, 1, 0, "node-1", "iperf3", "v4", PNameRoleClient, start),
+		GetPNameFromTask(0, 1, 2, "node-2", "iperf3", "v4", PNameRoleClient, start),
+		GetPNameFromTask(0, 1, 2, "node-1", "iperf3", "v6", PNameRoleClient, start),
+	} {
+		assert.False(t, names[other], "name %s is not unique", other)
+		names[other] = true
+	}
+}
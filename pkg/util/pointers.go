@@ -34,3 +34,8 @@ func FloatPointer(in float64) *float64 {
 func Int64Pointer(in int64) *int64 {
 	return &in
 }
+
+// IntPointer return a pointer to a given int
+func IntPointer(in int) *int {
+	return &in
+}
@@ -13,7 +13,29 @@ limitations under the License.
 
 package util
 
+import "strings"
+
 // IntToChar This function is converting integer to a rune character
 func IntToChar(i int) string {
 	return string(rune('A' - 1 + i))
 }
+
+// ShellQuote quote the given arguments for usage in a POSIX shell command line
+func ShellQuote(args ...string) string {
+	quoted := []string{}
+	for _, arg := range args {
+		if arg == "" {
+			quoted = append(quoted, "''")
+			continue
+		}
+		if strings.IndexFunc(arg, func(r rune) bool {
+			return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || strings.ContainsRune("-_./:=,@%+", r))
+		}) == -1 {
+			quoted = append(quoted, arg)
+			continue
+		}
+		quoted = append(quoted, "'"+strings.ReplaceAll(arg, "'", `'\''`)+"'")
+	}
+
+	return strings.Join(quoted, " ")
+}
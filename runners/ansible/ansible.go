@@ -32,6 +32,7 @@ import (
 	"github.com/cloudical-io/ancientt/pkg/cmdtemplate"
 	"github.com/cloudical-io/ancientt/pkg/config"
 	"github.com/cloudical-io/ancientt/pkg/executor"
+	"github.com/cloudical-io/ancientt/pkg/hostsfilter"
 	"github.com/cloudical-io/ancientt/pkg/util"
 	"github.com/cloudical-io/ancientt/runners"
 	"github.com/cloudical-io/ancientt/testers"
@@ -92,8 +93,16 @@ func (a *Ansible) GetHostsForTest(test *config.Test) (*testers.Hosts, error) {
 		return nil, err
 	}
 
-	servers := inv.GetHostsForGroup(a.config.Groups.Server)
-	clients := inv.GetHostsForGroup(a.config.Groups.Clients)
+	result := &testers.Hosts{}
+
+	servers, err := filterHosts(inv, a.config.Groups.Server, "servers", test.Hosts.Servers, result)
+	if err != nil {
+		return nil, err
+	}
+	clients, err := filterHosts(inv, a.config.Groups.Clients, "clients", test.Hosts.Clients, result)
+	if err != nil {
+		return nil, err
+	}
 
 	hosts := map[string]*testers.Host{}
 
@@ -121,7 +130,7 @@ func (a *Ansible) GetHostsForTest(test *config.Test) (*testers.Hosts, error) {
 				lock.Lock()
 				hosts[host] = &testers.Host{
 					Name:      host,
-					Labels:    map[string]string{},
+					Labels:    inv.GetHostLabels(host),
 					Addresses: addresses,
 				}
 				lock.Unlock()
@@ -164,10 +173,48 @@ func (a *Ansible) GetHostsForTest(test *config.Test) (*testers.Hosts, error) {
 		return nil, err
 	}
 
-	return &testers.Hosts{
-		Servers: sHosts,
-		Clients: cHosts,
-	}, nil
+	result.Servers = sHosts
+	result.Clients = cHosts
+
+	return result, nil
+}
+
+// filterHosts return the hosts of the inventory group which match the hosts filters, the host vars are used as the
+// labels of the hosts. Without any hosts filters all hosts of the group are returned. The seeds of random selections
+// are added to the hosts.
+func filterHosts(inv *ansible.InventoryList, group string, role string, filters []config.Hosts, hosts *testers.Hosts) ([]string, error) {
+	names := inv.GetHostsForGroup(group)
+	if len(filters) == 0 {
+		return names, nil
+	}
+
+	available := []*testers.Host{}
+	inGroup := map[string]struct{}{}
+	for _, name := range names {
+		available = append(available, &testers.Host{
+			Name:   name,
+			Labels: inv.GetHostLabels(name),
+		})
+		inGroup[name] = struct{}{}
+	}
+
+	selected := []string{}
+	for _, filter := range filters {
+		filtered, seed, err := hostsfilter.FilterHostsList(available, filter)
+		if err != nil {
+			return nil, err
+		}
+		hosts.AddSeed(role, filter.Name, seed)
+		for _, host := range filtered {
+			// Static hosts lists of a test can contain any host name
+			if _, ok := inGroup[host.Name]; !ok {
+				return nil, fmt.Errorf("host %q not found in ansible inventory group %s", host.Name, group)
+			}
+			selected = append(selected, host.Name)
+		}
+	}
+
+	return util.UniqueStringSlice(selected), nil
 }
 
 func getHosts(in []string, list map[string]*testers.Host) (map[string]*testers.Host, error) {
@@ -286,26 +333,9 @@ func (a *Ansible) Prepare(runOpts config.RunOptions, plan *testers.Plan) error {
 
 // Execute run the given commands and return the logs of it and / or error
 func (a *Ansible) Execute(plan *testers.Plan, parser chan<- parsers.Input) error {
-	for round, tasks := range plan.Commands {
-		a.logger.Infof("running commands round %d of %d", round+1, len(plan.Commands))
-		for i, task := range tasks {
-			if task.Sleep != 0 {
-				a.logger.Infof("waiting %s to pass before continuing next round", task.Sleep.String())
-				time.Sleep(task.Sleep)
-				continue
-			}
-			a.logger.Infof("running task round %d of %d", i+1, len(tasks))
-
-			if err := a.runTasks(round, task, plan.TestStartTime, plan.Tester, util.GetTaskName(plan.Tester, plan.TestStartTime), parser); err != nil {
-				if !*plan.RunOptions.ContinueOnError {
-					return err
-				}
-				a.logger.Warnf("continuing after err. %+v", err)
-			}
-		}
-	}
-
-	return nil
+	return runners.ExecutePlan(a.logger, plan, a.runOptions, func(round int, _ int, task *testers.Task) error {
+		return a.runTasks(round, task, plan.TestStartTime, plan.Tester, util.GetTaskName(plan.Tester, plan.TestStartTime), parser)
+	})
 }
 
 func (a *Ansible) runTasks(round int, mainTask *testers.Task, plannedTime time.Time, tester string, taskName string, parser chan<- parsers.Input) error {
@@ -330,7 +360,6 @@ func (a *Ansible) runTasks(round int, mainTask *testers.Task, plannedTime time.T
 	}
 
 	var mainWG sync.WaitGroup
-	var wg sync.WaitGroup
 
 	mainTaskStopped := false
 	mainCtx, mainCancel := context.WithCancel(context.Background())
@@ -391,17 +420,16 @@ func (a *Ansible) runTasks(round int, mainTask *testers.Task, plannedTime time.T
 	}
 
 	if ready {
+		pool := runners.NewClientPool(a.runOptions)
 		for i, task := range mainTask.SubTasks {
 			logger.WithField("hostname", task.Host).
 				Infof("running sub task %d of %d", i+1, len(mainTask.SubTasks))
 
-			wg.Add(1)
-			go func(task *testers.Task) {
+			task := task
+			pool.Go(func() {
 				ctx, cancel := context.WithTimeout(context.Background(), a.config.Timeouts.TaskCommandTimeout)
 				defer cancel()
 
-				defer wg.Done()
-
 				// Template command and args for each task
 				if err := cmdtemplate.Template(task, templateVars); err != nil {
 					erro := fmt.Errorf("failed to template task command and / or args. %+v", err)
@@ -440,19 +468,12 @@ func (a *Ansible) runTasks(round int, mainTask *testers.Task, plannedTime time.T
 					Tester:         tester,
 					ServerHost:     mainTask.Host.Name,
 					ClientHost:     task.Host.Name,
+					IPFamily:       string(task.IPFamily),
 					AdditionalInfo: a.additionalInfo,
 				}
-			}(task)
-
-			if a.runOptions.Mode != config.RunModeParallel {
-				wg.Wait()
-			}
-		}
-
-		// When RunOptions.Mode `parallel` then we wait after all test tasks have been run
-		if a.runOptions.Mode == config.RunModeParallel {
-			wg.Wait()
+			})
 		}
+		pool.Wait()
 
 		mainTask.Status.AddSuccessfulServer(mainTask.Host)
 		mainTaskStopped = true
@@ -21,6 +21,7 @@ import (
 
 	"github.com/cloudical-io/ancientt/pkg/config"
 	exectest "github.com/cloudical-io/ancientt/pkg/executor/test"
+	"github.com/cloudical-io/ancientt/pkg/util"
 	"github.com/sirupsen/logrus"
 	log "github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
@@ -42,7 +43,14 @@ func TestGetHostsForTest(t *testing.T) {
 			case 1:
 				return []byte(`{
     "_meta": {
-        "hostvars": {}
+        "hostvars": {
+            "server1": {
+                "rack": "r1"
+            },
+            "server2": {
+                "rack": "r2"
+            }
+        }
     },
     "all": {
         "children": [
@@ -100,4 +108,42 @@ func TestGetHostsForTest(t *testing.T) {
 
 	assert.Equal(t, 2, len(hosts.Clients))
 	assert.Equal(t, 1, len(hosts.Servers))
+	assert.Equal(t, map[string]string{"rack": "r1"}, hosts.Clients["server1"].Labels)
+
+	// The host vars are used as labels to filter the hosts
+	run = 1
+	hosts, err = a.GetHostsForTest(&config.Test{
+		Hosts: config.TestHosts{
+			Clients: []config.Hosts{
+				{
+					All:           util.BoolTruePointer(),
+					LabelSelector: "rack notin (r1)",
+				},
+			},
+			Servers: []config.Hosts{
+				{
+					Hosts: []string{"server4"},
+				},
+			},
+		},
+	})
+	require.Nil(t, err)
+
+	require.Equal(t, 1, len(hosts.Clients))
+	assert.Contains(t, hosts.Clients, "server2")
+	assert.Equal(t, 1, len(hosts.Servers))
+	assert.Contains(t, hosts.Servers, "server4")
+
+	// Static hosts must be in the inventory group
+	run = 1
+	_, err = a.GetHostsForTest(&config.Test{
+		Hosts: config.TestHosts{
+			Clients: []config.Hosts{
+				{
+					Hosts: []string{"server9"},
+				},
+			},
+		},
+	})
+	assert.NotNil(t, err)
 }
@@ -0,0 +1,336 @@
+/*
+Copyright 2022 Cloudical Deutschland GmbH. All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cloudical-io/ancientt/parsers"
+	"github.com/cloudical-io/ancientt/pkg/cmdtemplate"
+	"github.com/cloudical-io/ancientt/pkg/k8sutil"
+	"github.com/cloudical-io/ancientt/pkg/util"
+	"github.com/cloudical-io/ancientt/runners"
+	"github.com/cloudical-io/ancientt/testers"
+	"github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// agentContainer name of the container in the agent Pods the tasks are run in
+const agentContainer = "ancientt"
+
+// getAgentDaemonSetName return the name of the agent DaemonSet for the plan
+func getAgentDaemonSetName(plan *testers.Plan) string {
+	return fmt.Sprintf("%s-agents", util.GetTaskName(plan.Tester, plan.TestStartTime))
+}
+
+// prepareAgents deploy the agent DaemonSet on the hosts of the plan in each of their clusters and wait for an agent Pod
+// to run on each of them
+func (k *Kubernetes) prepareAgents(ctx context.Context, plan *testers.Plan) error {
+	taskName := util.GetTaskName(plan.Tester, plan.TestStartTime)
+	dsName := getAgentDaemonSetName(plan)
+
+	// Group the Nodes of the hosts by their cluster
+	clusterNodes := map[*cluster][]string{}
+	for name := range plan.AffectedServers {
+		c, node, err := k.hostCluster(name)
+		if err != nil {
+			return err
+		}
+		clusterNodes[c] = append(clusterNodes[c], node)
+	}
+
+	agents := map[string]string{}
+	for _, c := range k.clusters {
+		nodes, ok := clusterNodes[c]
+		if !ok {
+			continue
+		}
+		logger := k.logger.WithFields(logrus.Fields{"cluster": c.name, "namespace": c.namespace, "daemonset": dsName})
+
+		daemonSet, err := k.getAgentDaemonSetSpec(dsName, taskName, c.namespace, nodes)
+		if err != nil {
+			return fmt.Errorf("failed to generate agent daemonset %s/%s. %+v", c.namespace, dsName, err)
+		}
+
+		logger.Debug("(re)creating agent daemonset")
+		if err := k8sutil.DaemonSetRecreate(ctx, c.k8sclient, daemonSet); err != nil {
+			return fmt.Errorf("failed to create agent daemonset %s/%s. %+v", c.namespace, dsName, err)
+		}
+
+		logger.WithFields(logrus.Fields{"nodes": len(nodes)}).Info("waiting for agent pods to run")
+		pods, err := k8sutil.WaitForPodsOnNodes(ctx, c.k8sclient, c.namespace, agentSelectorLabels(dsName, taskName), nodes, k.runningTimeout())
+		if err != nil {
+			return fmt.Errorf("failed to wait for agent daemonset %s/%s pods. %+v", c.namespace, dsName, err)
+		}
+		for node, pod := range pods {
+			agents[c.hostName(node)] = pod
+		}
+	}
+	k.agents = agents
+
+	return nil
+}
+
+// hostAgent return the cluster and the name of the agent Pod running on the host
+func (k *Kubernetes) hostAgent(hostName string) (*cluster, string, error) {
+	podName, ok := k.agents[hostName]
+	if !ok {
+		return nil, "", fmt.Errorf("no agent pod running on host %s", hostName)
+	}
+	c, _, err := k.hostCluster(hostName)
+	if err != nil {
+		return nil, "", err
+	}
+	return c, podName, nil
+}
+
+// runTasksInAgents run the server task and its client tasks in the agent Pods on the hosts
+func (k *Kubernetes) runTasksInAgents(ctx context.Context, round int, mainTask *testers.Task, plan *testers.Plan, parser chan<- parsers.Input) error {
+	logger := k.logger.WithFields(logrus.Fields{"round": round})
+
+	serverCluster, serverAgent, err := k.hostAgent(mainTask.Host.Name)
+	if err != nil {
+		erro := fmt.Errorf("failed to get server agent pod. %+v", err)
+		k.logger.Error(erro)
+		mainTask.Status.AddFailedServer(mainTask.Host, erro)
+		return nil
+	}
+
+	// Get server agent Pod to have the server IP for each client task
+	pod, err := serverCluster.k8sclient.CoreV1().Pods(serverCluster.namespace).Get(ctx, serverAgent, metav1.GetOptions{})
+	if err != nil {
+		erro := fmt.Errorf("failed to get server agent pod %s/%s. %+v", serverCluster.namespace, serverAgent, err)
+		k.logger.Error(erro)
+		mainTask.Status.AddFailedServer(mainTask.Host, erro)
+		return nil
+	}
+
+	endpoint, err := k.getServerEndpoint(pod, nil, nil, 5601)
+	if err != nil {
+		k.logger.Error(err)
+		mainTask.Status.AddFailedServer(mainTask.Host, err)
+		return nil
+	}
+	logger.WithFields(logrus.Fields{"addressV4": endpoint.AddressV4, "addressV6": endpoint.AddressV6, "port": endpoint.Port, "path": endpoint.Path}).Debug("clients connect to server")
+
+	templateVars := cmdtemplate.Variables{
+		ServerAddressV4: endpoint.AddressV4,
+		ServerAddressV6: endpoint.AddressV6,
+		ServerPort:      endpoint.Port,
+	}
+
+	if err := cmdtemplate.Template(mainTask, templateVars); err != nil {
+		erro := fmt.Errorf("failed to template main task command and / or args. %+v", err)
+		k.logger.Error(erro)
+		mainTask.Status.AddFailedServer(mainTask.Host, erro)
+		return nil
+	}
+
+	logger.WithFields(logrus.Fields{"pod": serverAgent}).Info("starting server in agent pod")
+	server, err := k.startAgentServer(ctx, serverCluster, serverAgent, mainTask)
+	if err != nil {
+		erro := fmt.Errorf("failed to start server in agent pod %s/%s. %+v", serverCluster.namespace, serverAgent, err)
+		k.logger.Error(erro)
+		mainTask.Status.AddFailedServer(mainTask.Host, erro)
+		return nil
+	}
+
+	pool := runners.NewClientPool(k.runOptions)
+	for i, task := range mainTask.SubTasks {
+		k.logger.Infof("running sub task %d of %d", i+1, len(mainTask.SubTasks))
+
+		task := task
+		pool.Go(func() {
+			clientCluster, clientAgent, err := k.hostAgent(task.Host.Name)
+			if err != nil {
+				erro := fmt.Errorf("failed to get client agent pod. %+v", err)
+				logger.Errorf("error during runTasksInAgents. %+v", erro)
+				mainTask.Status.AddFailedClient(task.Host, erro)
+				return
+			}
+
+			// Template command and args for each task
+			if err := cmdtemplate.Template(task, templateVars); err != nil {
+				erro := fmt.Errorf("failed to template task command and / or args. %+v", err)
+				logger.Errorf("error during runTasksInAgents. %+v", erro)
+				mainTask.Status.AddFailedClient(task.Host, erro)
+				return
+			}
+
+			logger.WithFields(logrus.Fields{"pod": clientAgent}).Debug("running client in agent pod")
+			if err := k.runAgentClient(ctx, clientCluster, clientAgent, round, mainTask, task, plan, endpoint.Path, parser); err != nil {
+				erro := fmt.Errorf("failed to run client in agent pod %s/%s. %+v", clientCluster.namespace, clientAgent, err)
+				logger.Errorf("error during runTasksInAgents. %+v", erro)
+				mainTask.Status.AddFailedClient(task.Host, erro)
+				return
+			}
+
+			mainTask.Status.AddSuccessfulClient(task.Host)
+		})
+	}
+	pool.Wait()
+
+	logger.WithFields(logrus.Fields{"pod": serverAgent}).Info("stopping server in agent pod")
+	if err := server.stop(ctx); err != nil {
+		erro := fmt.Errorf("failed to stop server in agent pod. %+v", err)
+		logger.WithFields(logrus.Fields{"pod": serverAgent}).Error(erro)
+		mainTask.Status.AddFailedServer(mainTask.Host, erro)
+		return nil
+	}
+
+	mainTask.Status.AddSuccessfulServer(mainTask.Host)
+
+	logger.Debug("done running tasks for test in kubernetes agents for plan")
+
+	return nil
+}
+
+// runAgentClient run the client task in the agent Pod of the cluster and send its output to the parser. The output is
+// only sent when the command succeeded, so a failed client task doesn't stop the parser of the test.
+func (k *Kubernetes) runAgentClient(ctx context.Context, c *cluster, podName string, round int, mainTask *testers.Task, task *testers.Task, plan *testers.Plan, path string, parser chan<- parsers.Input) error {
+	ctx, cancel := context.WithTimeout(ctx, k.succeedTimeout())
+	defer cancel()
+
+	var stdout bytes.Buffer
+	var stderr strings.Builder
+
+	testTime := time.Now()
+	if err := c.executor.Exec(ctx, c.namespace, podName, agentContainer, append([]string{task.Command}, task.Args...), &stdout, &stderr); err != nil {
+		return fmt.Errorf("sub task command failed. %+v (stderr: %s)", err, strings.TrimSpace(stderr.String()))
+	}
+
+	// The parser closes the DataStream after reading it
+	r := ioutil.NopCloser(&stdout)
+	select {
+	case parser <- parsers.Input{
+		TestStartTime:  plan.TestStartTime,
+		TestTime:       testTime,
+		Round:          round,
+		DataStream:     &r,
+		Tester:         plan.Tester,
+		ServerHost:     mainTask.Host.Name,
+		ClientHost:     task.Host.Name,
+		IPFamily:       string(task.IPFamily),
+		AdditionalInfo: fmt.Sprintf("%s path=%s", podName, path),
+	}:
+	case <-ctx.Done():
+		return fmt.Errorf("timed out sending sub task output to parser. %+v", ctx.Err())
+	}
+
+	return nil
+}
+
+// agentServer server (main) task running in an agent Pod
+type agentServer struct {
+	runner  *Kubernetes
+	cluster *cluster
+	podName string
+	pid     int
+	doneCh  chan error
+}
+
+// startAgentServer start the server (main) task in the agent Pod of the cluster in the background, the shell prints its PID before it
+// is replaced by the server command so the server can be stopped reliably
+func (k *Kubernetes) startAgentServer(ctx context.Context, c *cluster, podName string, mainTask *testers.Task) (*agentServer, error) {
+	reader, writer := io.Pipe()
+
+	srv := &agentServer{
+		runner:  k,
+		cluster: c,
+		podName: podName,
+		doneCh:  make(chan error, 1),
+	}
+
+	command := []string{"sh", "-c", fmt.Sprintf("echo $$; exec %s", util.ShellQuote(append([]string{mainTask.Command}, mainTask.Args...)...))}
+	go func() {
+		err := c.executor.Exec(context.Background(), c.namespace, podName, agentContainer, command, writer, ioutil.Discard)
+		// The error is returned through the done channel, the output of the server is only read for its PID
+		writer.Close()
+		srv.doneCh <- err
+	}()
+
+	pidCh := make(chan string, 1)
+	go func() {
+		buffered := bufio.NewReader(reader)
+		line, _ := buffered.ReadString('\n')
+		pidCh <- line
+		// Discard the server output, so the server doesn't block on the exec stream
+		io.Copy(ioutil.Discard, buffered)
+	}()
+
+	timeout := time.NewTimer(k.runningTimeout())
+	defer timeout.Stop()
+
+	select {
+	case line := <-pidCh:
+		pid, err := strconv.Atoi(strings.TrimSpace(line))
+		if err != nil {
+			return nil, fmt.Errorf("failed to get PID of main task. %+v", err)
+		}
+		srv.pid = pid
+	case <-timeout.C:
+		return nil, fmt.Errorf("timed out waiting for main task to start after %s", k.runningTimeout())
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	// Give the server a moment to start listening, then make sure it is still running
+	time.Sleep(250 * time.Millisecond)
+	select {
+	case err := <-srv.doneCh:
+		return nil, fmt.Errorf("main task exited right after start. %+v", err)
+	default:
+	}
+
+	return srv, nil
+}
+
+// stop stop the server by sending it the TERM signal and, when it hasn't exited in time, the KILL signal
+func (srv *agentServer) stop(ctx context.Context) error {
+	if err := srv.kill(ctx, "TERM"); err != nil {
+		srv.runner.logger.WithFields(logrus.Fields{"pod": srv.podName}).Warn(err)
+	}
+
+	select {
+	case <-srv.doneCh:
+		return nil
+	case <-time.After(srv.runner.deleteTimeout()):
+	}
+
+	if err := srv.kill(ctx, "KILL"); err != nil {
+		return fmt.Errorf("failed to kill main task. %+v", err)
+	}
+
+	return nil
+}
+
+func (srv *agentServer) kill(ctx context.Context, signal string) error {
+	ctx, cancel := context.WithTimeout(ctx, srv.runner.deleteTimeout())
+	defer cancel()
+
+	var stderr strings.Builder
+	command := []string{"kill", "-" + signal, strconv.Itoa(srv.pid)}
+	if err := srv.cluster.executor.Exec(ctx, srv.cluster.namespace, srv.podName, agentContainer, command, ioutil.Discard, &stderr); err != nil {
+		return fmt.Errorf("%+v (stderr: %s)", err, strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
@@ -0,0 +1,115 @@
+/*
+Copyright 2022 Cloudical Deutschland GmbH. All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/cloudical-io/ancientt/pkg/config"
+	"github.com/cloudical-io/ancientt/pkg/k8sutil"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+)
+
+// cluster Kubernetes cluster the test Pods are run in
+type cluster struct {
+	// name of the cluster, empty for the cluster of the runner's kubeconfig
+	name      string
+	namespace string
+	k8sclient kubernetes.Interface
+	dynclient dynamic.Interface
+	executor  k8sutil.Executor
+}
+
+// newCluster create the clients for the cluster
+func newCluster(name string, inClusterConfig bool, kubeconfig string, kubecontext string, namespace string) (*cluster, error) {
+	restConfig, err := k8sutil.NewRESTConfig(inClusterConfig, kubeconfig, kubecontext)
+	if err != nil {
+		return nil, err
+	}
+	clientset, err := k8sutil.NewClientForConfig(restConfig)
+	if err != nil {
+		return nil, err
+	}
+	dynclient, err := dynamic.NewForConfig(restConfig)
+	if err != nil {
+		return nil, fmt.Errorf("kubernetes new dynamic client error. %+v", err)
+	}
+
+	return &cluster{
+		name:      name,
+		namespace: namespace,
+		k8sclient: clientset,
+		dynclient: dynclient,
+		executor:  k8sutil.NewExecutor(restConfig, clientset),
+	}, nil
+}
+
+// newClusters create the clusters of the runner config, the cluster of the runner's kubeconfig is the first one
+func newClusters(conf *config.RunnerKubernetes) ([]*cluster, error) {
+	defaultCluster, err := newCluster("", conf.InClusterConfig, conf.Kubeconfig, conf.Context, conf.Namespace)
+	if err != nil {
+		return nil, err
+	}
+	clusters := []*cluster{defaultCluster}
+
+	names := map[string]bool{}
+	for _, c := range conf.Clusters {
+		if names[c.Name] {
+			return nil, fmt.Errorf("kubernetes runner cluster %s is configured more than once", c.Name)
+		}
+		names[c.Name] = true
+
+		kubeconfig := c.Kubeconfig
+		if kubeconfig == "" {
+			kubeconfig = conf.Kubeconfig
+		}
+		namespace := c.Namespace
+		if namespace == "" {
+			namespace = conf.Namespace
+		}
+
+		additional, err := newCluster(c.Name, c.InClusterConfig, kubeconfig, c.Context, namespace)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create clients for cluster %s. %+v", c.Name, err)
+		}
+		clusters = append(clusters, additional)
+	}
+
+	return clusters, nil
+}
+
+// hostName return the host name of the Node of the cluster, Nodes of additional clusters are named `CLUSTER/NODE`
+func (c *cluster) hostName(node string) string {
+	if c.name == "" {
+		return node
+	}
+	return c.name + "/" + node
+}
+
+// hostCluster return the cluster and the Node name of the host
+func (k *Kubernetes) hostCluster(hostName string) (*cluster, string, error) {
+	name, node := "", hostName
+	if i := strings.Index(hostName, "/"); i >= 0 {
+		name, node = hostName[:i], hostName[i+1:]
+	}
+
+	for _, c := range k.clusters {
+		if c.name == name {
+			return c, node, nil
+		}
+	}
+	return nil, "", fmt.Errorf("cluster %s of host %s not found in kubernetes runner clusters", name, hostName)
+}
@@ -16,7 +16,6 @@ package kubernetes
 import (
 	"context"
 	"fmt"
-	"sync"
 	"time"
 
 	"github.com/cloudical-io/ancientt/parsers"
@@ -32,7 +31,6 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/client-go/kubernetes"
 )
 
 // Name Kubernetes Runner Name
@@ -47,24 +45,45 @@ type Kubernetes struct {
 	runners.Runner
 	logger     *log.Entry
 	config     *config.RunnerKubernetes
-	k8sclient  kubernetes.Interface
 	runOptions config.RunOptions
+	// clusters the cluster of the runner's kubeconfig and the additional clusters
+	clusters []*cluster
+	// agents agent Pod name per host name in `daemonset` mode
+	agents map[string]string
 }
 
 // NewRunner return a new Kubernetes Runner
 func NewRunner(cfg *config.Config) (runners.Runner, error) {
 	conf := cfg.Runner.Kubernetes
 
-	clientset, err := k8sutil.NewClient(cfg.Runner.Kubernetes.InClusterConfig, cfg.Runner.Kubernetes.Kubeconfig)
+	clusters, err := newClusters(conf)
 	if err != nil {
 		return nil, err
 	}
 
-	return &Kubernetes{
-		logger:    log.WithFields(logrus.Fields{"runner": Name, "namespace": cfg.Runner.Kubernetes.Namespace}),
-		config:    conf,
-		k8sclient: clientset,
-	}, nil
+	k := &Kubernetes{
+		logger:   log.WithFields(logrus.Fields{"runner": Name, "namespace": cfg.Runner.Kubernetes.Namespace}),
+		config:   conf,
+		clusters: clusters,
+	}
+
+	// The Service only routes to the Pod network
+	if conf.Service != nil && conf.Multus != nil {
+		return nil, fmt.Errorf("kubernetes runner service and multus options can't be used together")
+	}
+	// The Service selects the server Pod, the agent Pods can't be told apart
+	if conf.Service != nil && conf.Mode == config.KubernetesModeDaemonSet {
+		return nil, fmt.Errorf("kubernetes runner service option can't be used with mode %s", conf.Mode)
+	}
+
+	// Check the Pod templates before any test is run
+	for _, role := range []string{serverRole, clientsRole} {
+		if _, err := k.applyPodTemplateToPod(&corev1.Pod{}, role); err != nil {
+			return nil, fmt.Errorf("failed to apply %s pod template. %+v", role, err)
+		}
+	}
+
+	return k, nil
 }
 
 // GetHostsForTest return a mocked list of hots for the given test config
@@ -81,10 +100,11 @@ func (k *Kubernetes) GetHostsForTest(test *config.Test) (*testers.Hosts, error)
 
 	// Go through Hosts Servers list to get the servers hosts
 	for _, servers := range test.Hosts.Servers {
-		filtered, err := hostsfilter.FilterHostsList(k8sNodes, servers)
+		filtered, seed, err := hostsfilter.FilterHostsList(k8sNodes, servers)
 		if err != nil {
 			return nil, err
 		}
+		hosts.AddSeed("servers", servers.Name, seed)
 		for _, host := range filtered {
 			if _, ok := hosts.Servers[host.Name]; !ok {
 				hosts.Servers[host.Name] = host
@@ -94,10 +114,11 @@ func (k *Kubernetes) GetHostsForTest(test *config.Test) (*testers.Hosts, error)
 
 	// Go through Hosts Clients list to get the clients hosts
 	for _, clients := range test.Hosts.Clients {
-		filtered, err := hostsfilter.FilterHostsList(k8sNodes, clients)
+		filtered, seed, err := hostsfilter.FilterHostsList(k8sNodes, clients)
 		if err != nil {
 			return nil, err
 		}
+		hosts.AddSeed("clients", clients.Name, seed)
 		for _, host := range filtered {
 			if _, ok := hosts.Clients[host.Name]; !ok {
 				hosts.Clients[host.Name] = host
@@ -112,8 +133,26 @@ func (k *Kubernetes) GetHostsForTest(test *config.Test) (*testers.Hosts, error)
 
 func (k *Kubernetes) k8sNodesToHosts() ([]*testers.Host, error) {
 	hosts := []*testers.Host{}
-	ctx := context.TODO()
-	nodes, err := k.k8sclient.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	for _, c := range k.clusters {
+		clusterHosts, err := k.clusterNodesToHosts(c)
+		if err != nil {
+			if c.name != "" {
+				return nil, fmt.Errorf("failed to list nodes of cluster %s. %+v", c.name, err)
+			}
+			return nil, err
+		}
+		hosts = append(hosts, clusterHosts...)
+	}
+
+	return hosts, nil
+}
+
+// clusterNodesToHosts return the Nodes of the cluster as hosts, the hosts of the additional clusters have the cluster
+// label with the name of their cluster
+func (k *Kubernetes) clusterNodesToHosts(c *cluster) ([]*testers.Host, error) {
+	hosts := []*testers.Host{}
+	ctx := context.Background()
+	nodes, err := c.k8sclient.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
 	if err != nil {
 		return nil, err
 	}
@@ -136,9 +175,18 @@ func (k *Kubernetes) k8sNodesToHosts() ([]*testers.Host, error) {
 			continue
 		}
 
+		hostLabels := node.ObjectMeta.Labels
+		if c.name != "" {
+			hostLabels = map[string]string{}
+			for key, value := range node.ObjectMeta.Labels {
+				hostLabels[key] = value
+			}
+			hostLabels[k8sutil.ClusterLabel] = c.name
+		}
+
 		hosts = append(hosts, &testers.Host{
-			Labels: node.ObjectMeta.Labels,
-			Name:   node.ObjectMeta.Name,
+			Labels: hostLabels,
+			Name:   c.hostName(node.ObjectMeta.Name),
 		})
 	}
 
@@ -149,8 +197,17 @@ func (k *Kubernetes) k8sNodesToHosts() ([]*testers.Host, error) {
 func (k *Kubernetes) Prepare(runOpts config.RunOptions, plan *testers.Plan) error {
 	k.runOptions = runOpts
 
-	if err := k.prepareKubernetes(); err != nil {
-		return err
+	ctx := context.Background()
+	for _, c := range k.clusters {
+		if err := k.prepareKubernetes(ctx, c); err != nil {
+			return err
+		}
+	}
+
+	if k.config.Mode == config.KubernetesModeDaemonSet {
+		if err := k.prepareAgents(ctx, plan); err != nil {
+			return err
+		}
 	}
 
 	return nil
@@ -158,37 +215,28 @@ func (k *Kubernetes) Prepare(runOpts config.RunOptions, plan *testers.Plan) erro
 
 // Execute run the given commands and return the logs of it and / or error
 func (k *Kubernetes) Execute(plan *testers.Plan, parser chan<- parsers.Input) error {
-	// TODO Add option to go through Service IPs instead of Pod IPs
-
-	// Iterate over given plan.Commands to then run each task
-	for round, tasks := range plan.Commands {
-		k.logger.Infof("running commands round %d of %d", round+1, len(plan.Commands))
-		for i, task := range tasks {
-			if task.Sleep != 0 {
-				k.logger.Infof("waiting %s to pass before continuing next round", task.Sleep.String())
-				time.Sleep(task.Sleep)
-				continue
-			}
-			k.logger.Infof("running task round %d of %d", i+1, len(tasks))
-
-			// Create the Pods for the server task and client tasks
-			if err := k.createPodsForTasks(round, task, plan, parser); err != nil {
-				if !*plan.RunOptions.ContinueOnError {
-					return err
-				}
-				k.logger.Warnf("continuing after err. %+v", err)
-			}
-		}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Run the server tasks in the agent Pods
+	if k.config.Mode == config.KubernetesModeDaemonSet {
+		return runners.ExecutePlan(k.logger, plan, k.runOptions, func(round int, _ int, task *testers.Task) error {
+			return k.runTasksInAgents(ctx, round, task, plan, parser)
+		})
 	}
 
-	return nil
+	// Run the server tasks, creating the Pods for the server task and its client tasks
+	return runners.ExecutePlan(k.logger, plan, k.runOptions, func(round int, index int, task *testers.Task) error {
+		return k.createPodsForTasks(ctx, round, index, task, plan, parser)
+	})
 }
 
-// prepareKubernetes prepares Kubernetes by creating the namespace if it does not exist
-func (k *Kubernetes) prepareKubernetes() error {
+// prepareKubernetes prepares the cluster by creating the namespace if it does not exist
+func (k *Kubernetes) prepareKubernetes(ctx context.Context, c *cluster) error {
+	logger := k.logger.WithFields(logrus.Fields{"cluster": c.name, "namespace": c.namespace})
+
 	// Check if namespaces exists, if not try create it
-	ctx := context.TODO()
-	if _, err := k.k8sclient.CoreV1().Namespaces().Get(ctx, k.config.Namespace, metav1.GetOptions{}); err != nil {
+	if _, err := c.k8sclient.CoreV1().Namespaces().Get(ctx, c.namespace, metav1.GetOptions{}); err != nil {
 		// If namespace not found, create it
 		if errors.IsNotFound(err) {
 			ns := &corev1.Namespace{
@@ -196,32 +244,36 @@ func (k *Kubernetes) prepareKubernetes() error {
 					Labels: map[string]string{
 						"created-by": "ancientt",
 					},
-					Name: k.config.Namespace,
+					Name: c.namespace,
 				},
 			}
-			k.logger.Info("trying to create namespace")
-			ctx := context.TODO()
-			if _, err := k.k8sclient.CoreV1().Namespaces().Create(ctx, ns, metav1.CreateOptions{}); err != nil {
-				return fmt.Errorf("failed to create namespace %s. %+v", k.config.Namespace, err)
+			logger.Info("trying to create namespace")
+			if _, err := c.k8sclient.CoreV1().Namespaces().Create(ctx, ns, metav1.CreateOptions{}); err != nil {
+				return fmt.Errorf("failed to create namespace %s. %+v", c.namespace, err)
 			}
-			k.logger.Info("created namespace")
+			logger.Info("created namespace")
 		} else {
-			return fmt.Errorf("error while getting namespace %s. %+v", k.config.Namespace, err)
+			return fmt.Errorf("error while getting namespace %s. %+v", c.namespace, err)
 		}
 	}
 	return nil
 }
 
 // createPodsForTasks create the Pods that are needed for the task(s)
-func (k *Kubernetes) createPodsForTasks(round int, mainTask *testers.Task, plan *testers.Plan, parser chan<- parsers.Input) error {
+func (k *Kubernetes) createPodsForTasks(ctx context.Context, round int, index int, mainTask *testers.Task, plan *testers.Plan, parser chan<- parsers.Input) error {
 	logger := k.logger.WithFields(logrus.Fields{"round": round})
 
-	var wg sync.WaitGroup
-
 	taskName := util.GetTaskName(plan.Tester, plan.TestStartTime)
 
+	serverCluster, serverNode, err := k.hostCluster(mainTask.Host.Name)
+	if err != nil {
+		k.logger.Error(err)
+		mainTask.Status.AddFailedServer(mainTask.Host, err)
+		return nil
+	}
+
 	// Create server Pod first
-	serverPodName := util.GetPNameFromTask(round, mainTask.Host.Name, mainTask.Command, util.PNameRoleServer, plan.TestStartTime)
+	serverPodName := util.GetPNameFromTask(round, index, 0, mainTask.Host.Name, mainTask.Command, "", util.PNameRoleServer, plan.TestStartTime)
 
 	// Create initial cmdtemplate.Variables
 	templateVars := cmdtemplate.Variables{
@@ -235,59 +287,124 @@ func (k *Kubernetes) createPodsForTasks(round int, mainTask *testers.Task, plan
 		return nil
 	}
 
-	pod := k.getPodSpec(serverPodName, taskName, mainTask)
+	pod := k.getPodSpec(serverPodName, taskName, serverCluster.namespace, serverNode, mainTask)
 	k.applyServiceAccountToPod(pod, serverRole)
-
-	logger.WithFields(logrus.Fields{"pod": serverPodName}).Debug("(re)creating server pod")
-	if err := k8sutil.PodRecreate(k.k8sclient, pod, k.config.Timeouts.DeleteTimeout); err != nil {
-		erro := fmt.Errorf("failed to create server pod %s/%s. %+v", k.config.Namespace, serverPodName, err)
+	pod, err = k.applyPodTemplateToPod(pod, serverRole)
+	if err != nil {
+		erro := fmt.Errorf("failed to apply pod template to server pod %s/%s. %+v", serverCluster.namespace, serverPodName, err)
 		k.logger.Error(erro)
 		mainTask.Status.AddFailedServer(mainTask.Host, erro)
 		return nil
 	}
 
-	logger.WithFields(logrus.Fields{"pod": serverPodName}).Info("waiting for server pod to run")
-	running, err := k8sutil.WaitForPodToRun(k.k8sclient, k.config.Namespace, serverPodName, k.config.Timeouts.RunningTimeout)
-	if err != nil {
-		erro := fmt.Errorf("failed to wait for server pod %s/%s. %+v", k.config.Namespace, serverPodName, err)
+	logger.WithFields(logrus.Fields{"pod": serverPodName}).Debug("(re)creating server pod")
+	if err := k8sutil.PodRecreate(ctx, serverCluster.k8sclient, pod, k.deleteTimeout()); err != nil {
+		erro := fmt.Errorf("failed to create server pod %s/%s. %+v", serverCluster.namespace, serverPodName, err)
 		k.logger.Error(erro)
 		mainTask.Status.AddFailedServer(mainTask.Host, erro)
 		return nil
 	}
-	if !running {
-		erro := fmt.Errorf("server pod %s/%s not running after runTimeout", k.config.Namespace, serverPodName)
+
+	logger.WithFields(logrus.Fields{"pod": serverPodName}).Info("waiting for server pod to run")
+	if err := k8sutil.WaitForPodToRun(ctx, serverCluster.k8sclient, serverCluster.namespace, serverPodName, k.runningTimeout()); err != nil {
+		erro := fmt.Errorf("failed to wait for server pod %s/%s. %+v", serverCluster.namespace, serverPodName, err)
 		k.logger.Error(erro)
 		mainTask.Status.AddFailedServer(mainTask.Host, erro)
 		return nil
 	}
 
 	// Get server Pod to have the server IP for each client task
-	ctx := context.TODO()
-	pod, err = k.k8sclient.CoreV1().Pods(k.config.Namespace).Get(ctx, serverPodName, metav1.GetOptions{})
+	pod, err = serverCluster.k8sclient.CoreV1().Pods(serverCluster.namespace).Get(ctx, serverPodName, metav1.GetOptions{})
 	if err != nil {
-		erro := fmt.Errorf("failed to get server pod %s/%s. %+v", k.config.Namespace, serverPodName, err)
+		erro := fmt.Errorf("failed to get server pod %s/%s. %+v", serverCluster.namespace, serverPodName, err)
 		k.logger.Error(erro)
 		mainTask.Status.AddFailedServer(mainTask.Host, erro)
 		return nil
 	}
-	if pod.Status.PodIP == "" {
-		mainTask.Status.AddFailedServer(mainTask.Host,
-			fmt.Errorf("failed to get server pod %s/%s IP, got '%s'", k.config.Namespace, serverPodName, pod.Status.PodIP))
+
+	// Create the Service for the clients to connect through the Service instead of the server Pod IP
+	var service *corev1.Service
+	if k.config.Service != nil {
+		logger.WithFields(logrus.Fields{"service": serverPodName}).Debug("(re)creating server service")
+		service, err = k8sutil.ServiceRecreate(ctx, serverCluster.k8sclient, k.getServiceSpec(serverPodName, taskName, serverCluster.namespace, mainTask))
+		if err != nil {
+			erro := fmt.Errorf("failed to create server service %s/%s. %+v", serverCluster.namespace, serverPodName, err)
+			k.logger.Error(erro)
+			mainTask.Status.AddFailedServer(mainTask.Host, erro)
+			return nil
+		}
+
+		logger.WithFields(logrus.Fields{"service": serverPodName}).Info("waiting for server service endpoints to be ready")
+		if err := k8sutil.WaitForServiceEndpoints(ctx, serverCluster.k8sclient, serverCluster.namespace, serverPodName, k.runningTimeout()); err != nil {
+			erro := fmt.Errorf("failed to wait for server service %s/%s endpoints. %+v", serverCluster.namespace, serverPodName, err)
+			k.logger.Error(erro)
+			mainTask.Status.AddFailedServer(mainTask.Host, erro)
+			return nil
+		}
+
+		switch k.config.Service.Type {
+		case config.KubernetesServiceTypeLoadBalancer:
+			logger.WithFields(logrus.Fields{"service": serverPodName}).Info("waiting for server service loadbalancer ingress")
+			service, err = k8sutil.WaitForServiceLoadBalancer(ctx, serverCluster.k8sclient, serverCluster.namespace, serverPodName, k.runningTimeout())
+			if err != nil {
+				erro := fmt.Errorf("failed to wait for server service %s/%s loadbalancer. %+v", serverCluster.namespace, serverPodName, err)
+				k.logger.Error(erro)
+				mainTask.Status.AddFailedServer(mainTask.Host, erro)
+				return nil
+			}
+		case config.KubernetesServiceTypeExported:
+			// The clients in the other clusters reach the Service through its clusterset DNS name
+			logger.WithFields(logrus.Fields{"service": serverPodName}).Debug("(re)creating server serviceexport")
+			if err := k8sutil.ServiceExportRecreate(ctx, serverCluster.dynclient, serverCluster.namespace, serverPodName, k8sutil.GetPodLabels(serverPodName, taskName)); err != nil {
+				erro := fmt.Errorf("failed to export server service %s/%s. %+v", serverCluster.namespace, serverPodName, err)
+				k.logger.Error(erro)
+				mainTask.Status.AddFailedServer(mainTask.Host, erro)
+				return nil
+			}
+		}
+	}
+
+	// The NodePort is reached through the IPs of the Node of the server Pod
+	nodeIPs := []string{}
+	if service != nil && k.config.Service.Type == config.KubernetesServiceTypeNodePort {
+		node, err := serverCluster.k8sclient.CoreV1().Nodes().Get(ctx, pod.Spec.NodeName, metav1.GetOptions{})
+		if err != nil {
+			erro := fmt.Errorf("failed to get node %s of server pod %s/%s. %+v", pod.Spec.NodeName, serverCluster.namespace, serverPodName, err)
+			k.logger.Error(erro)
+			mainTask.Status.AddFailedServer(mainTask.Host, erro)
+			return nil
+		}
+		nodeIPs = nodeInternalIPs(node)
+	}
+
+	endpoint, err := k.getServerEndpoint(pod, service, nodeIPs, templateVars.ServerPort)
+	if err != nil {
+		k.logger.Error(err)
+		mainTask.Status.AddFailedServer(mainTask.Host, err)
 		return nil
 	}
+	logger.WithFields(logrus.Fields{"addressV4": endpoint.AddressV4, "addressV6": endpoint.AddressV6, "port": endpoint.Port, "path": endpoint.Path}).Debug("clients connect to server")
 
-	templateVars.ServerAddressV4 = pod.Status.PodIP
+	templateVars.ServerAddressV4 = endpoint.AddressV4
+	templateVars.ServerAddressV6 = endpoint.AddressV6
+	templateVars.ServerPort = endpoint.Port
 
+	pool := runners.NewClientPool(k.runOptions)
 	for i, task := range mainTask.SubTasks {
 		k.logger.Infof("running sub task %d of %d", i+1, len(mainTask.SubTasks))
 
-		wg.Add(1)
-		go func(task *testers.Task) {
-			defer wg.Done()
-
+		i, task := i, task
+		pool.Go(func() {
 			testTime := time.Now()
 
-			pName := util.GetPNameFromTask(round, task.Host.Name, task.Command, util.PNameRoleClient, plan.TestStartTime)
+			pName := util.GetPNameFromTask(round, index, i, task.Host.Name, task.Command, string(task.IPFamily), util.PNameRoleClient, plan.TestStartTime)
+
+			clientCluster, clientNode, err := k.hostCluster(task.Host.Name)
+			if err != nil {
+				logger.Errorf("error during createPodsForTasks. %+v", err)
+				mainTask.Status.AddFailedClient(task.Host, err)
+				return
+			}
 
 			// Template command and args for each task
 			if err := cmdtemplate.Template(task, templateVars); err != nil {
@@ -297,70 +414,82 @@ func (k *Kubernetes) createPodsForTasks(round int, mainTask *testers.Task, plan
 				return
 			}
 
-			pod = k.getPodSpec(pName, taskName, task)
+			pod := k.getPodSpec(pName, taskName, clientCluster.namespace, clientNode, task)
 			k.applyServiceAccountToPod(pod, clientsRole)
-
-			logger.WithFields(logrus.Fields{"pod": pName}).Debug("(re)creating client pod")
-			if err := k8sutil.PodRecreate(k.k8sclient, pod, k.config.Timeouts.DeleteTimeout); err != nil {
-				erro := fmt.Errorf("failed to create pod %s/%s. %+v", k.config.Namespace, pName, err)
+			pod, err = k.applyPodTemplateToPod(pod, clientsRole)
+			if err != nil {
+				erro := fmt.Errorf("failed to apply pod template to pod %s/%s. %+v", clientCluster.namespace, pName, err)
 				logger.Errorf("error during createPodsForTasks. %+v", erro)
 				mainTask.Status.AddFailedClient(task.Host, erro)
 				return
 			}
 
-			logger.WithFields(logrus.Fields{"pod": pName}).Info("waiting for client pod to run or succeed")
-			running, err := k8sutil.WaitForPodToRunOrSucceed(k.k8sclient, k.config.Namespace, pName, k.config.Timeouts.RunningTimeout)
-			if err != nil {
-				erro := fmt.Errorf("failed to wait for pod %s/%s. %+v", k.config.Namespace, pName, err)
+			logger.WithFields(logrus.Fields{"pod": pName}).Debug("(re)creating client pod")
+			if err := k8sutil.PodRecreate(ctx, clientCluster.k8sclient, pod, k.deleteTimeout()); err != nil {
+				erro := fmt.Errorf("failed to create pod %s/%s. %+v", clientCluster.namespace, pName, err)
 				logger.Errorf("error during createPodsForTasks. %+v", erro)
 				mainTask.Status.AddFailedClient(task.Host, erro)
 				return
 			}
-			if !running {
-				erro := fmt.Errorf("pod %s/%s not running after runTimeout", k.config.Namespace, pName)
+
+			logger.WithFields(logrus.Fields{"pod": pName}).Info("waiting for client pod to run or succeed")
+			if err := k8sutil.WaitForPodToRunOrSucceed(ctx, clientCluster.k8sclient, clientCluster.namespace, pName, k.runningTimeout()); err != nil {
+				erro := fmt.Errorf("failed to wait for pod %s/%s. %+v", clientCluster.namespace, pName, err)
 				logger.Errorf("error during createPodsForTasks. %+v", erro)
 				mainTask.Status.AddFailedClient(task.Host, erro)
 				return
 			}
 
 			logger.WithFields(logrus.Fields{"pod": pName}).Debug("about to pushLogsToParser")
-			if err := k.pushLogsToParser(parser, plan.TestStartTime, testTime, round, plan.Tester, mainTask.Host.Name, task.Host.Name, pName); err != nil {
-				erro := fmt.Errorf("failed to push pod %s/%s logs to parser. %+v", k.config.Namespace, pName, err)
+			if err := k.pushLogsToParser(ctx, clientCluster, parser, plan.TestStartTime, testTime, round, plan.Tester, mainTask.Host.Name, task.Host.Name, task.IPFamily, pName, endpoint.Path); err != nil {
+				erro := fmt.Errorf("failed to push pod %s/%s logs to parser. %+v", clientCluster.namespace, pName, err)
 				logger.Errorf("error during createPodsForTasks. %+v", erro)
 				mainTask.Status.AddFailedClient(task.Host, erro)
 				return
 			}
 
 			logger.WithFields(logrus.Fields{"pod": pName}).Info("deleting client pod")
-			if err := k8sutil.PodDelete(k.k8sclient, pod, k.config.Timeouts.DeleteTimeout); err != nil {
-				erro := fmt.Errorf("failed to delete client pod %s/%s. %+v", k.config.Namespace, pName, err)
+			if err := k8sutil.PodDelete(ctx, clientCluster.k8sclient, pod, k.deleteTimeout()); err != nil {
+				erro := fmt.Errorf("failed to delete client pod %s/%s. %+v", clientCluster.namespace, pName, err)
 				logger.Errorf("error during createPodsForTasks. %+v", erro)
 				mainTask.Status.AddFailedClient(task.Host, erro)
 				return
 			}
 
 			mainTask.Status.AddSuccessfulClient(task.Host)
-		}(task)
-
-		if k.runOptions.Mode != config.RunModeParallel {
-			wg.Wait()
-		}
-	}
-
-	// When RunOptions.Mode `parallel` then we wait after all test tasks have been run
-	if k.runOptions.Mode == config.RunModeParallel {
-		wg.Wait()
+		})
 	}
+	pool.Wait()
 
 	// Delete server pod
 	logger.WithFields(logrus.Fields{"pod": serverPodName}).Info("deleting server pod")
-	if err := k8sutil.PodDeleteByName(k.k8sclient, k.config.Namespace, serverPodName, k.config.Timeouts.DeleteTimeout); err != nil {
+	if err := k8sutil.PodDeleteByName(ctx, serverCluster.k8sclient, serverCluster.namespace, serverPodName, k.deleteTimeout()); err != nil {
 		erro := fmt.Errorf("failed to delete server pod. %+v", err)
 		logger.WithFields(logrus.Fields{"pod": serverPodName}).Error(erro)
 		mainTask.Status.AddFailedServer(mainTask.Host, erro)
 		return nil
 	}
 
+	if service != nil && k.config.Service.Type == config.KubernetesServiceTypeExported {
+		logger.WithFields(logrus.Fields{"service": serverPodName}).Info("deleting server serviceexport")
+		if err := k8sutil.ServiceExportDelete(ctx, serverCluster.dynclient, serverCluster.namespace, serverPodName); err != nil {
+			erro := fmt.Errorf("failed to delete server serviceexport. %+v", err)
+			logger.WithFields(logrus.Fields{"service": serverPodName}).Error(erro)
+			mainTask.Status.AddFailedServer(mainTask.Host, erro)
+			return nil
+		}
+	}
+
+	if service != nil {
+		logger.WithFields(logrus.Fields{"service": serverPodName}).Info("deleting server service")
+		if err := k8sutil.ServiceDelete(ctx, serverCluster.k8sclient, serverCluster.namespace, serverPodName); err != nil {
+			erro := fmt.Errorf("failed to delete server service. %+v", err)
+			logger.WithFields(logrus.Fields{"service": serverPodName}).Error(erro)
+			mainTask.Status.AddFailedServer(mainTask.Host, erro)
+			return nil
+		}
+	}
+
 	mainTask.Status.AddSuccessfulServer(mainTask.Host)
 
 	logger.Debug("done running tasks for test in kubernetes for plan")
@@ -368,54 +497,99 @@ func (k *Kubernetes) createPodsForTasks(round int, mainTask *testers.Task, plan
 	return nil
 }
 
-func (k *Kubernetes) pushLogsToParser(parserInput chan<- parsers.Input, plannedTime time.Time, testTime time.Time, round int, tester string, serverHost string, clientHost string, podName string) error {
+func (k *Kubernetes) pushLogsToParser(ctx context.Context, c *cluster, parserInput chan<- parsers.Input, plannedTime time.Time, testTime time.Time, round int, tester string, serverHost string, clientHost string, ipFamily config.IPFamily, podName string, path string) error {
 	// Wait for the Pod to succeed because that is the "sign" that the test for that Pod is done.
-	succeeded, err := k8sutil.WaitForPodToSucceed(k.k8sclient, k.config.Namespace, podName, k.config.Timeouts.SucceedTimeout)
+	if err := k8sutil.WaitForPodToSucceed(ctx, c.k8sclient, c.namespace, podName, k.succeedTimeout()); err != nil {
+		return err
+	}
+
+	// "Generate" request for logs of Pod
+	req := c.k8sclient.CoreV1().Pods(c.namespace).GetLogs(podName, &corev1.PodLogOptions{})
+
+	// Start the log stream, the parser reads the stream after the Execute has returned, so it must not be bound to
+	// the context of the Execute
+	podLogs, err := req.Stream(context.Background())
 	if err != nil {
 		return err
 	}
+	// Don't close the `podLogs` here, that is the responsibility of the parser!
+
+	// Send the logs to the parser.InputChan
+	parserInput <- parsers.Input{
+		TestStartTime:  plannedTime,
+		TestTime:       testTime,
+		Round:          round,
+		DataStream:     &podLogs,
+		Tester:         tester,
+		ServerHost:     serverHost,
+		ClientHost:     clientHost,
+		IPFamily:       string(ipFamily),
+		AdditionalInfo: fmt.Sprintf("%s path=%s", podName, path),
+	}
+	return nil
+}
 
-	if succeeded {
-		// "Generate" request for logs of Pod
-		req := k.k8sclient.CoreV1().Pods(k.config.Namespace).GetLogs(podName, &corev1.PodLogOptions{})
+// deleteTimeout the configured delete timeout in seconds as duration
+func (k *Kubernetes) deleteTimeout() time.Duration {
+	return time.Duration(k.config.Timeouts.DeleteTimeout) * time.Second
+}
 
-		// Start the log stream
-		ctx := context.TODO()
-		podLogs, err := req.Stream(ctx)
-		if err != nil {
+// runningTimeout the configured running timeout in seconds as duration
+func (k *Kubernetes) runningTimeout() time.Duration {
+	return time.Duration(k.config.Timeouts.RunningTimeout) * time.Second
+}
+
+// succeedTimeout the configured succeed timeout in seconds as duration
+func (k *Kubernetes) succeedTimeout() time.Duration {
+	return time.Duration(k.config.Timeouts.SucceedTimeout) * time.Second
+}
+
+// Cleanup remove all (left behind) Kubernetes resources created for the given Plan in all clusters.
+func (k *Kubernetes) Cleanup(plan *testers.Plan) error {
+	selectorLabels := map[string]string{
+		k8sutil.TaskIDLabel: util.GetTaskName(plan.Tester, plan.TestStartTime),
+	}
+
+	ctx := context.Background()
+	for _, c := range k.clusters {
+		if err := k.cleanupCluster(ctx, c, selectorLabels); err != nil {
 			return err
 		}
-		// Don't close the `podLogs` here, that is the responsibility of the parser!
-
-		// Send the logs to the parser.InputChan
-		parserInput <- parsers.Input{
-			TestStartTime:  plannedTime,
-			TestTime:       testTime,
-			Round:          round,
-			DataStream:     &podLogs,
-			Tester:         tester,
-			ServerHost:     serverHost,
-			ClientHost:     clientHost,
-			AdditionalInfo: podName,
-		}
-		return nil
 	}
+	k.agents = nil
 
-	return fmt.Errorf("pod %s/%s has not succeeded", k.config.Namespace, podName)
+	return nil
 }
 
-// Cleanup remove all (left behind) Kubernetes resources created for the given Plan.
-func (k *Kubernetes) Cleanup(plan *testers.Plan) error {
-	var wg sync.WaitGroup
+// cleanupCluster remove all Kubernetes resources with the labels from the cluster
+func (k *Kubernetes) cleanupCluster(ctx context.Context, c *cluster, selectorLabels map[string]string) error {
+	logger := k.logger.WithFields(logrus.Fields{"cluster": c.name, "namespace": c.namespace})
+
+	// Delete all DaemonSets with label XYZ, e.g., the agent DaemonSet
+	if err := k8sutil.DaemonSetDeleteByLabels(ctx, c.k8sclient, c.namespace, selectorLabels); err != nil {
+		logger.Errorf("error during daemonset delete by labels in cleanup. %+v", err)
+		return err
+	}
 
 	// Delete all Pods with label XYZ
-	if err := k8sutil.PodDeleteByLabels(k.k8sclient, k.config.Namespace, map[string]string{
-		k8sutil.TaskIDLabel: util.GetTaskName(plan.Tester, plan.TestStartTime),
-	}); err != nil {
-		k.logger.Errorf("error during pod delete by labels in cleanup. %+v", err)
+	if err := k8sutil.PodDeleteByLabels(ctx, c.k8sclient, c.namespace, selectorLabels); err != nil {
+		logger.Errorf("error during pod delete by labels in cleanup. %+v", err)
+		return err
+	}
+
+	// Delete all ServiceExports with label XYZ, only when exported Services are used as the resource might not exist
+	if k.config.Service != nil && k.config.Service.Type == config.KubernetesServiceTypeExported {
+		if err := k8sutil.ServiceExportDeleteByLabels(ctx, c.dynclient, c.namespace, selectorLabels); err != nil {
+			logger.Errorf("error during serviceexport delete by labels in cleanup. %+v", err)
+			return err
+		}
+	}
+
+	// Delete all Services with label XYZ
+	if err := k8sutil.ServiceDeleteByLabels(ctx, c.k8sclient, c.namespace, selectorLabels); err != nil {
+		logger.Errorf("error during service delete by labels in cleanup. %+v", err)
 		return err
 	}
-	wg.Wait()
 
 	return nil
 }
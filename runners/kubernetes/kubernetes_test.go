@@ -14,16 +14,27 @@ limitations under the License.
 package kubernetes
 
 import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
+	"github.com/cloudical-io/ancientt/parsers"
 	"github.com/cloudical-io/ancientt/pkg/config"
+	"github.com/cloudical-io/ancientt/pkg/k8sutil"
 	"github.com/cloudical-io/ancientt/pkg/util"
+	"github.com/cloudical-io/ancientt/testers"
 	"github.com/cloudical-io/ancientt/tests/k8s"
 	"github.com/creasty/defaults"
 	"github.com/sirupsen/logrus"
 	log "github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 // TODO add tests
@@ -39,9 +50,9 @@ func TestGetHostsForTest(t *testing.T) {
 	require.Nil(t, defaults.Set(conf))
 
 	runner := &Kubernetes{
-		logger:    log.WithFields(logrus.Fields{"runner": Name, "namespace": ""}),
-		config:    conf,
-		k8sclient: clientset,
+		logger:   log.WithFields(logrus.Fields{"runner": Name, "namespace": ""}),
+		config:   conf,
+		clusters: []*cluster{{namespace: conf.Namespace, k8sclient: clientset}},
 	}
 
 	test := &config.Test{}
@@ -63,3 +74,177 @@ func TestGetHostsForTest(t *testing.T) {
 	assert.Equal(t, 1, len(hosts.Servers))
 	assert.Equal(t, 3, len(hosts.Clients))
 }
+
+func TestGetHostsForTestMultiCluster(t *testing.T) {
+	clientset, err := k8s.NewClient(2)
+	require.Nil(t, err)
+	remoteClientset, err := k8s.NewClient(2)
+	require.Nil(t, err)
+
+	conf := &config.RunnerKubernetes{}
+	require.Nil(t, defaults.Set(conf))
+
+	runner := &Kubernetes{
+		logger: log.WithFields(logrus.Fields{"runner": Name, "namespace": ""}),
+		config: conf,
+		clusters: []*cluster{
+			{namespace: "ancientt", k8sclient: clientset},
+			{name: "remote", namespace: "ancientt-remote", k8sclient: remoteClientset},
+		},
+	}
+
+	test := &config.Test{}
+	test.Hosts.Servers = append(test.Hosts.Servers, config.Hosts{All: util.BoolTruePointer()})
+	test.Hosts.Clients = append(test.Hosts.Clients, config.Hosts{HostSelector: map[string]string{k8sutil.ClusterLabel: "remote"}})
+	hosts, err := runner.GetHostsForTest(test)
+	require.Nil(t, err)
+	assert.Equal(t, 4, len(hosts.Servers))
+	require.Equal(t, 2, len(hosts.Clients))
+
+	clients := []string{}
+	for _, host := range hosts.Clients {
+		clients = append(clients, host.Name)
+	}
+	assert.ElementsMatch(t, []string{"remote/node-0", "remote/node-1"}, clients)
+
+	// The Nodes of the hosts are looked up in their cluster
+	c, node, err := runner.hostCluster("remote/node-1")
+	require.Nil(t, err)
+	assert.Equal(t, "remote", c.name)
+	assert.Equal(t, "ancientt-remote", c.namespace)
+	assert.Equal(t, "node-1", node)
+
+	c, node, err = runner.hostCluster("node-0")
+	require.Nil(t, err)
+	assert.Equal(t, "", c.name)
+	assert.Equal(t, "node-0", node)
+
+	_, _, err = runner.hostCluster("unknown/node-0")
+	assert.NotNil(t, err)
+}
+
+// fakeExecutor runs the server, kill and client commands of the daemonset mode
+type fakeExecutor struct {
+	lock     sync.Mutex
+	commands []string
+	killed   chan struct{}
+}
+
+func (e *fakeExecutor) Exec(ctx context.Context, namespace string, podName string, container string, command []string, stdout io.Writer, stderr io.Writer) error {
+	e.lock.Lock()
+	e.commands = append(e.commands, podName+": "+strings.Join(command, " "))
+	e.lock.Unlock()
+
+	switch command[0] {
+	case "sh":
+		// Server prints its PID and runs till it is killed
+		fmt.Fprintln(stdout, "42")
+		<-e.killed
+	case "kill":
+		close(e.killed)
+	default:
+		if command[len(command)-1] == "--fail" {
+			fmt.Fprint(stdout, "unable to connect")
+			return fmt.Errorf("command terminated with exit code 1")
+		}
+		fmt.Fprintf(stdout, "connected to %s", command[len(command)-1])
+	}
+	return nil
+}
+
+func TestExecuteDaemonSetMode(t *testing.T) {
+	clientset, err := k8s.NewClient(2)
+	require.Nil(t, err)
+
+	conf := &config.RunnerKubernetes{
+		Mode:     config.KubernetesModeDaemonSet,
+		Hosts:    &config.KubernetesHosts{},
+		Timeouts: &config.KubernetesTimeouts{},
+	}
+	require.Nil(t, defaults.Set(conf))
+
+	executor := &fakeExecutor{killed: make(chan struct{})}
+	runner := &Kubernetes{
+		logger:   log.WithFields(logrus.Fields{"runner": Name, "namespace": conf.Namespace}),
+		config:   conf,
+		clusters: []*cluster{{namespace: conf.Namespace, k8sclient: clientset, executor: executor}},
+	}
+
+	server := &testers.Host{Name: "node-0"}
+	client := &testers.Host{Name: "node-1"}
+	plan := &testers.Plan{
+		TestStartTime:   time.Now(),
+		Tester:          "iperf3",
+		AffectedServers: map[string]*testers.Host{server.Name: server, client.Name: client},
+		Commands: [][]*testers.Task{{
+			{
+				Host:    server,
+				Command: "iperf3",
+				Args:    []string{"--server"},
+				SubTasks: []*testers.Task{
+					{Host: client, Command: "iperf3", Args: []string{"--client", "{{ .ServerAddressV4 }}"}},
+					// A failed client task is not sent to the parser
+					{Host: server, Command: "iperf3", Args: []string{"--client", "{{ .ServerAddressV4 }}", "--fail"}},
+				},
+				Status: &testers.Status{
+					SuccessfulHosts: testers.StatusHosts{Servers: map[string]int{}, Clients: map[string]int{}},
+					FailedHosts:     testers.StatusHosts{Servers: map[string]int{}, Clients: map[string]int{}},
+					Errors:          map[string][]error{},
+				},
+			},
+		}},
+	}
+
+	// The fake client has no DaemonSet controller, so the agent Pods are created here
+	dsName := getAgentDaemonSetName(plan)
+	taskName := util.GetTaskName(plan.Tester, plan.TestStartTime)
+	for i, node := range []string{"node-0", "node-1"} {
+		_, err := clientset.CoreV1().Pods(conf.Namespace).Create(context.Background(), &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      fmt.Sprintf("%s-%d", dsName, i),
+				Namespace: conf.Namespace,
+				Labels:    k8sutil.GetPodLabels(dsName, taskName),
+			},
+			Spec:   corev1.PodSpec{NodeName: node},
+			Status: corev1.PodStatus{Phase: corev1.PodRunning, PodIP: fmt.Sprintf("10.244.%d.10", i)},
+		}, metav1.CreateOptions{})
+		require.Nil(t, err)
+	}
+
+	require.Nil(t, runner.Prepare(config.RunOptions{ContinueOnError: util.BoolFalsePointer()}, plan))
+	ds, err := clientset.AppsV1().DaemonSets(conf.Namespace).Get(context.Background(), dsName, metav1.GetOptions{})
+	require.Nil(t, err)
+	assert.Equal(t, corev1.RestartPolicyAlways, ds.Spec.Template.Spec.RestartPolicy)
+	assert.ElementsMatch(t, []string{"node-0", "node-1"},
+		ds.Spec.Template.Spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms[0].MatchExpressions[0].Values)
+	assert.Equal(t, map[string]string{"node-0": dsName + "-0", "node-1": dsName + "-1"}, runner.agents)
+
+	inCh := make(chan parsers.Input)
+	outCh := make(chan []string)
+	go func() {
+		outputs := []string{}
+		for input := range inCh {
+			out, _ := io.ReadAll(*input.DataStream)
+			outputs = append(outputs, string(out))
+		}
+		outCh <- outputs
+	}()
+	require.Nil(t, runner.Execute(plan, inCh))
+	close(inCh)
+
+	assert.Equal(t, []string{"connected to 10.244.0.10"}, <-outCh)
+	status := plan.Commands[0][0].Status
+	assert.Equal(t, 1, status.SuccessfulHosts.Servers["node-0"])
+	assert.Equal(t, 1, status.SuccessfulHosts.Clients["node-1"])
+	assert.Equal(t, 1, status.FailedHosts.Clients["node-0"])
+	assert.Equal(t, []string{
+		dsName + "-0: sh -c echo $$; exec iperf3 --server",
+		dsName + "-1: iperf3 --client 10.244.0.10",
+		dsName + "-0: iperf3 --client 10.244.0.10 --fail",
+		dsName + "-0: kill -TERM 42",
+	}, executor.commands)
+
+	require.Nil(t, runner.Cleanup(plan))
+	_, err = clientset.AppsV1().DaemonSets(conf.Namespace).Get(context.Background(), dsName, metav1.GetOptions{})
+	assert.NotNil(t, err)
+}
@@ -14,9 +14,15 @@ limitations under the License.
 package kubernetes
 
 import (
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/cloudical-io/ancientt/pkg/config"
 	"github.com/cloudical-io/ancientt/pkg/k8sutil"
 	"github.com/cloudical-io/ancientt/pkg/util"
 	"github.com/cloudical-io/ancientt/testers"
+	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
@@ -26,18 +32,38 @@ const (
 	serverRole  = "server"
 )
 
-func (k Kubernetes) getPodSpec(pName string, taskName string, task *testers.Task) *corev1.Pod {
+// Paths through which the clients connect to the server Pod
+const (
+	pathPodIP        = "podIP"
+	pathClusterIP    = "clusterIP"
+	pathNodePort     = "nodePort"
+	pathHeadless     = "headless"
+	pathMultus       = "multus"
+	pathLoadBalancer = "loadBalancer"
+	pathExported     = "exported"
+)
+
+// getPodSpec return the Pod for the task in the namespace, running on the Node
+func (k Kubernetes) getPodSpec(pName string, taskName string, namespace string, node string, task *testers.Task) *corev1.Pod {
 	hostNetwork := false
 	if k.config.HostNetwork != nil && *k.config.HostNetwork {
 		hostNetwork = true
 	}
 
+	annotations := map[string]string{}
+	for key, value := range k.config.Annotations {
+		annotations[key] = value
+	}
+	if k.config.Multus != nil {
+		annotations[k8sutil.MultusNetworksAnnotation] = strings.Join(k.config.Multus.Networks, ",")
+	}
+
 	pod := &corev1.Pod{
 		ObjectMeta: metav1.ObjectMeta{
-			Annotations: k.config.Annotations,
+			Annotations: annotations,
 			Labels:      k8sutil.GetPodLabels(pName, taskName),
 			Name:        pName,
-			Namespace:   k.config.Namespace,
+			Namespace:   namespace,
 		},
 		Spec: corev1.PodSpec{
 			Containers: []corev1.Container{
@@ -50,7 +76,7 @@ func (k Kubernetes) getPodSpec(pName string, taskName string, task *testers.Task
 				},
 			},
 			NodeSelector: map[string]string{
-				corev1.LabelHostname: task.Host.Name,
+				corev1.LabelHostname: node,
 			},
 			HostNetwork:                   hostNetwork,
 			RestartPolicy:                 corev1.RestartPolicyOnFailure,
@@ -59,6 +85,12 @@ func (k Kubernetes) getPodSpec(pName string, taskName string, task *testers.Task
 		},
 	}
 
+	// Host network Pods need to use the cluster DNS to resolve the headless and exported Service names
+	if hostNetwork && k.config.Service != nil &&
+		(k.config.Service.Type == config.KubernetesServiceTypeHeadless || k.config.Service.Type == config.KubernetesServiceTypeExported) {
+		pod.Spec.DNSPolicy = corev1.DNSClusterFirstWithHostNet
+	}
+
 	return pod
 }
 
@@ -76,3 +108,276 @@ func (k Kubernetes) applyServiceAccountToPod(p *corev1.Pod, role string) {
 		}
 	}
 }
+
+// applyPodTemplateToPod return the Pod with the Pod template of the role merged on top of it
+func (k Kubernetes) applyPodTemplateToPod(p *corev1.Pod, role string) (*corev1.Pod, error) {
+	if k.config.PodTemplate == nil {
+		return p, nil
+	}
+
+	switch role {
+	case serverRole:
+		return k8sutil.PodApplyTemplate(p, k.config.PodTemplate.Server)
+	case clientsRole:
+		return k8sutil.PodApplyTemplate(p, k.config.PodTemplate.Clients)
+	}
+	return p, nil
+}
+
+// getAgentDaemonSetSpec return the agent DaemonSet for the Nodes, the agent Pods idle till the tasks are run in them.
+// The agent Pods use the server ServiceAccount and both, the server and clients, Pod templates.
+func (k Kubernetes) getAgentDaemonSetSpec(dsName string, taskName string, namespace string, nodes []string) (*appsv1.DaemonSet, error) {
+	pod := k.getPodSpec(dsName, taskName, namespace, "", &testers.Task{
+		Host:    &testers.Host{},
+		Command: "sleep",
+		Args:    []string{"infinity"},
+	})
+	delete(pod.Spec.NodeSelector, corev1.LabelHostname)
+
+	k.applyServiceAccountToPod(pod, serverRole)
+	for _, role := range []string{serverRole, clientsRole} {
+		var err error
+		if pod, err = k.applyPodTemplateToPod(pod, role); err != nil {
+			return nil, err
+		}
+	}
+
+	// The agents only run on the Nodes of the test
+	if pod.Spec.Affinity == nil {
+		pod.Spec.Affinity = &corev1.Affinity{}
+	}
+	pod.Spec.Affinity.NodeAffinity = &corev1.NodeAffinity{
+		RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{
+			NodeSelectorTerms: []corev1.NodeSelectorTerm{
+				{
+					MatchExpressions: []corev1.NodeSelectorRequirement{
+						{
+							Key:      corev1.LabelHostname,
+							Operator: corev1.NodeSelectorOpIn,
+							Values:   nodes,
+						},
+					},
+				},
+			},
+		},
+	}
+	pod.Spec.RestartPolicy = corev1.RestartPolicyAlways
+
+	return &appsv1.DaemonSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Labels:    k8sutil.GetPodLabels(dsName, taskName),
+			Name:      dsName,
+			Namespace: namespace,
+		},
+		Spec: appsv1.DaemonSetSpec{
+			Selector: &metav1.LabelSelector{
+				MatchLabels: agentSelectorLabels(dsName, taskName),
+			},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: pod.ObjectMeta.Annotations,
+					Labels:      pod.ObjectMeta.Labels,
+				},
+				Spec: pod.Spec,
+			},
+		},
+	}, nil
+}
+
+// agentSelectorLabels return the labels selecting the agent Pods of the DaemonSet
+func agentSelectorLabels(dsName string, taskName string) map[string]string {
+	return map[string]string{
+		"app.kubernetes.io/instance": dsName,
+		k8sutil.TaskIDLabel:          taskName,
+	}
+}
+
+// getServiceSpec return the Service for the server Pod of the task, selecting the server Pod by its name
+func (k Kubernetes) getServiceSpec(pName string, taskName string, namespace string, task *testers.Task) *corev1.Service {
+	service := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Annotations: k.config.Service.Annotations,
+			Labels:      k8sutil.GetPodLabels(pName, taskName),
+			Name:        pName,
+			Namespace:   namespace,
+		},
+		Spec: corev1.ServiceSpec{
+			Selector: map[string]string{
+				"app.kubernetes.io/instance": pName,
+				k8sutil.TaskIDLabel:          taskName,
+			},
+			Ports: k8sutil.PortsListToServicePorts(task.Ports),
+			Type:  corev1.ServiceTypeClusterIP,
+		},
+	}
+
+	// Get an IPv4 and IPv6 ClusterIP in dual-stack clusters
+	ipFamilyPolicy := corev1.IPFamilyPolicyPreferDualStack
+	service.Spec.IPFamilyPolicy = &ipFamilyPolicy
+
+	switch k.config.Service.Type {
+	case config.KubernetesServiceTypeNodePort:
+		service.Spec.Type = corev1.ServiceTypeNodePort
+	case config.KubernetesServiceTypeHeadless:
+		service.Spec.ClusterIP = corev1.ClusterIPNone
+	case config.KubernetesServiceTypeLoadBalancer:
+		service.Spec.Type = corev1.ServiceTypeLoadBalancer
+	}
+
+	return service
+}
+
+// serverEndpoint addresses and port the clients connect to the server with and the path type
+type serverEndpoint struct {
+	AddressV4 string
+	AddressV6 string
+	Port      int32
+	Path      string
+}
+
+// getServerEndpoint return the IPv4 and / or IPv6 address and port the clients connect to and the path type. Without a
+// Service the server Pod IPs are used, for a NodePort Service the given node IPs of the server Pod's Node. With Multus
+// networks the server Pod IPs on the network are taken from the network status of the Pod. For a LoadBalancer Service
+// the ingress IPs (or hostname) and for an exported Service the clusterset DNS name are used.
+func (k Kubernetes) getServerEndpoint(pod *corev1.Pod, service *corev1.Service, nodeIPs []string, port int32) (*serverEndpoint, error) {
+	endpoint := &serverEndpoint{
+		Port: port,
+	}
+
+	if k.config.Multus != nil {
+		network := k.multusNetwork()
+		ips, err := k8sutil.PodNetworkIPs(pod, network)
+		if err != nil {
+			return nil, err
+		}
+		endpoint.AddressV4, endpoint.AddressV6 = splitIPFamilies(ips)
+		if endpoint.AddressV4 == "" && endpoint.AddressV6 == "" {
+			return nil, fmt.Errorf("failed to get server pod %s/%s IP on network %s, got '%s'", pod.ObjectMeta.Namespace, pod.ObjectMeta.Name, network, strings.Join(ips, ", "))
+		}
+		endpoint.Path = pathMultus
+		return endpoint, nil
+	}
+
+	if service == nil {
+		ips := []string{}
+		for _, podIP := range pod.Status.PodIPs {
+			ips = append(ips, podIP.IP)
+		}
+		if len(ips) == 0 && pod.Status.PodIP != "" {
+			ips = append(ips, pod.Status.PodIP)
+		}
+		endpoint.AddressV4, endpoint.AddressV6 = splitIPFamilies(ips)
+		if endpoint.AddressV4 == "" && endpoint.AddressV6 == "" {
+			return nil, fmt.Errorf("failed to get server pod %s/%s IP, got '%s'", pod.ObjectMeta.Namespace, pod.ObjectMeta.Name, pod.Status.PodIP)
+		}
+		endpoint.Path = pathPodIP
+		return endpoint, nil
+	}
+
+	switch k.config.Service.Type {
+	case config.KubernetesServiceTypeNodePort:
+		ips := nodeIPs
+		if len(ips) == 0 && pod.Status.HostIP != "" {
+			ips = []string{pod.Status.HostIP}
+		}
+		endpoint.AddressV4, endpoint.AddressV6 = splitIPFamilies(ips)
+		if endpoint.AddressV4 == "" && endpoint.AddressV6 == "" {
+			return nil, fmt.Errorf("failed to get server pod %s/%s host IP for nodeport, got '%s'", pod.ObjectMeta.Namespace, pod.ObjectMeta.Name, pod.Status.HostIP)
+		}
+		endpoint.Port = 0
+		for _, servicePort := range service.Spec.Ports {
+			if servicePort.Port == port && servicePort.NodePort != 0 {
+				endpoint.Port = servicePort.NodePort
+				break
+			}
+		}
+		if endpoint.Port == 0 {
+			return nil, fmt.Errorf("no nodeport allocated for port %d of service %s/%s", port, service.ObjectMeta.Namespace, service.ObjectMeta.Name)
+		}
+		endpoint.Path = pathNodePort
+	case config.KubernetesServiceTypeHeadless:
+		// The address family is chosen by the clients when resolving the name
+		name := fmt.Sprintf("%s.%s.svc", service.ObjectMeta.Name, service.ObjectMeta.Namespace)
+		endpoint.AddressV4 = name
+		endpoint.AddressV6 = name
+		endpoint.Path = pathHeadless
+	case config.KubernetesServiceTypeLoadBalancer:
+		ips := []string{}
+		for _, ingress := range service.Status.LoadBalancer.Ingress {
+			if ingress.IP != "" {
+				ips = append(ips, ingress.IP)
+			}
+		}
+		endpoint.AddressV4, endpoint.AddressV6 = splitIPFamilies(ips)
+		if endpoint.AddressV4 == "" && endpoint.AddressV6 == "" {
+			// The address family is chosen by the clients when resolving the LoadBalancer hostname
+			for _, ingress := range service.Status.LoadBalancer.Ingress {
+				if ingress.Hostname != "" {
+					endpoint.AddressV4 = ingress.Hostname
+					endpoint.AddressV6 = ingress.Hostname
+					break
+				}
+			}
+		}
+		if endpoint.AddressV4 == "" && endpoint.AddressV6 == "" {
+			return nil, fmt.Errorf("failed to get service %s/%s loadbalancer ingress", service.ObjectMeta.Namespace, service.ObjectMeta.Name)
+		}
+		endpoint.Path = pathLoadBalancer
+	case config.KubernetesServiceTypeExported:
+		// The address family is chosen by the clients when resolving the name
+		name := fmt.Sprintf("%s.%s.svc.clusterset.local", service.ObjectMeta.Name, service.ObjectMeta.Namespace)
+		endpoint.AddressV4 = name
+		endpoint.AddressV6 = name
+		endpoint.Path = pathExported
+	default:
+		ips := service.Spec.ClusterIPs
+		if len(ips) == 0 && service.Spec.ClusterIP != "" {
+			ips = []string{service.Spec.ClusterIP}
+		}
+		endpoint.AddressV4, endpoint.AddressV6 = splitIPFamilies(ips)
+		if endpoint.AddressV4 == "" && endpoint.AddressV6 == "" {
+			return nil, fmt.Errorf("failed to get service %s/%s cluster IP, got '%s'", service.ObjectMeta.Namespace, service.ObjectMeta.Name, service.Spec.ClusterIP)
+		}
+		endpoint.Path = pathClusterIP
+	}
+
+	return endpoint, nil
+}
+
+// multusNetwork return the Multus network the clients connect to the server on
+func (k Kubernetes) multusNetwork() string {
+	if k.config.Multus.Network != "" {
+		return k.config.Multus.Network
+	}
+	return k.config.Multus.Networks[0]
+}
+
+// splitIPFamilies return the first IPv4 and the first IPv6 address of the list, invalid IPs (e.g., `None`) are ignored
+func splitIPFamilies(ips []string) (string, string) {
+	var v4, v6 string
+	for _, ip := range ips {
+		parsed := net.ParseIP(ip)
+		if parsed == nil {
+			continue
+		}
+		if parsed.To4() != nil {
+			if v4 == "" {
+				v4 = ip
+			}
+		} else if v6 == "" {
+			v6 = ip
+		}
+	}
+	return v4, v6
+}
+
+// nodeInternalIPs return the internal IPs of the Node
+func nodeInternalIPs(node *corev1.Node) []string {
+	ips := []string{}
+	for _, address := range node.Status.Addresses {
+		if address.Type == corev1.NodeInternalIP {
+			ips = append(ips, address.Address)
+		}
+	}
+	return ips
+}
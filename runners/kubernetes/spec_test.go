@@ -15,8 +15,269 @@ package kubernetes
 
 import (
 	"testing"
+
+	"github.com/cloudical-io/ancientt/pkg/config"
+	"github.com/cloudical-io/ancientt/testers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 func TestGetPodSpec(t *testing.T) {
 	// TODO
 }
+
+func TestGetServiceSpec(t *testing.T) {
+	task := &testers.Task{
+		Host:  &testers.Host{Name: "node-1"},
+		Ports: testers.Ports{TCP: []int32{5601}},
+	}
+
+	tests := []struct {
+		serviceType config.KubernetesServiceType
+		specType    corev1.ServiceType
+		clusterIP   string
+	}{
+		{config.KubernetesServiceTypeClusterIP, corev1.ServiceTypeClusterIP, ""},
+		{config.KubernetesServiceTypeNodePort, corev1.ServiceTypeNodePort, ""},
+		{config.KubernetesServiceTypeHeadless, corev1.ServiceTypeClusterIP, corev1.ClusterIPNone},
+		{config.KubernetesServiceTypeLoadBalancer, corev1.ServiceTypeLoadBalancer, ""},
+		{config.KubernetesServiceTypeExported, corev1.ServiceTypeClusterIP, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.serviceType), func(t *testing.T) {
+			k := Kubernetes{config: &config.RunnerKubernetes{
+				Namespace: "ancientt",
+				Service:   &config.KubernetesService{Type: tt.serviceType},
+			}}
+
+			service := k.getServiceSpec("ancientt-server-iperf3-1", "ancientt-iperf3-1", "ancientt", task)
+			assert.Equal(t, "ancientt-server-iperf3-1", service.ObjectMeta.Name)
+			assert.Equal(t, "ancientt", service.ObjectMeta.Namespace)
+			assert.Equal(t, "ancientt-server-iperf3-1", service.Spec.Selector["app.kubernetes.io/instance"])
+			assert.Equal(t, tt.specType, service.Spec.Type)
+			assert.Equal(t, tt.clusterIP, service.Spec.ClusterIP)
+			require.Equal(t, 1, len(service.Spec.Ports))
+			assert.Equal(t, "tcp-5601", service.Spec.Ports[0].Name)
+			assert.Equal(t, int32(5601), service.Spec.Ports[0].Port)
+			assert.Equal(t, corev1.ProtocolTCP, service.Spec.Ports[0].Protocol)
+		})
+	}
+}
+
+func TestGetServerEndpoint(t *testing.T) {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "server", Namespace: "ancientt"},
+		Status:     corev1.PodStatus{PodIP: "10.244.1.10", HostIP: "192.0.2.10"},
+	}
+	dualStackPod := pod.DeepCopy()
+	dualStackPod.Status.PodIPs = []corev1.PodIP{{IP: "10.244.1.10"}, {IP: "fd00:10:244:1::a"}}
+	ipv6Pod := pod.DeepCopy()
+	ipv6Pod.Status.PodIP = "fd00:10:244:1::a"
+	multusPod := pod.DeepCopy()
+	multusPod.ObjectMeta.Annotations = map[string]string{
+		"k8s.v1.cni.cncf.io/network-status": `[{"name":"cbr0","interface":"eth0","ips":["10.244.1.10"],"default":true},` +
+			`{"name":"ancientt/storage","interface":"net1","ips":["192.168.100.10","fd00:100::a"]}]`,
+	}
+
+	service := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "server", Namespace: "ancientt"},
+		Spec: corev1.ServiceSpec{
+			ClusterIP:  "10.96.0.20",
+			ClusterIPs: []string{"10.96.0.20", "fd00:10:96::14"},
+			Ports:      []corev1.ServicePort{{Port: 5601, NodePort: 30601}},
+		},
+	}
+	loadBalancerService := service.DeepCopy()
+	loadBalancerService.Status.LoadBalancer.Ingress = []corev1.LoadBalancerIngress{{IP: "198.51.100.20"}, {IP: "2001:db8:100::14"}}
+	hostnameService := service.DeepCopy()
+	hostnameService.Status.LoadBalancer.Ingress = []corev1.LoadBalancerIngress{{Hostname: "server.example.com"}}
+
+	tests := []struct {
+		name        string
+		serviceType config.KubernetesServiceType
+		pod         *corev1.Pod
+		service     *corev1.Service
+		nodeIPs     []string
+		multus      *config.KubernetesMultus
+		expected    *serverEndpoint
+	}{
+		{
+			name:     "pod IP",
+			pod:      pod,
+			expected: &serverEndpoint{AddressV4: "10.244.1.10", Port: 5601, Path: pathPodIP},
+		},
+		{
+			name:     "dual-stack pod IPs",
+			pod:      dualStackPod,
+			expected: &serverEndpoint{AddressV4: "10.244.1.10", AddressV6: "fd00:10:244:1::a", Port: 5601, Path: pathPodIP},
+		},
+		{
+			name:     "IPv6 pod IP",
+			pod:      ipv6Pod,
+			expected: &serverEndpoint{AddressV6: "fd00:10:244:1::a", Port: 5601, Path: pathPodIP},
+		},
+		{
+			name:        "cluster IPs",
+			serviceType: config.KubernetesServiceTypeClusterIP,
+			pod:         pod,
+			service:     service,
+			expected:    &serverEndpoint{AddressV4: "10.96.0.20", AddressV6: "fd00:10:96::14", Port: 5601, Path: pathClusterIP},
+		},
+		{
+			name:        "node port",
+			serviceType: config.KubernetesServiceTypeNodePort,
+			pod:         pod,
+			service:     service,
+			nodeIPs:     []string{"192.0.2.10", "2001:db8::10"},
+			expected:    &serverEndpoint{AddressV4: "192.0.2.10", AddressV6: "2001:db8::10", Port: 30601, Path: pathNodePort},
+		},
+		{
+			name:        "node port without node IPs",
+			serviceType: config.KubernetesServiceTypeNodePort,
+			pod:         pod,
+			service:     service,
+			expected:    &serverEndpoint{AddressV4: "192.0.2.10", Port: 30601, Path: pathNodePort},
+		},
+		{
+			name:        "headless",
+			serviceType: config.KubernetesServiceTypeHeadless,
+			pod:         pod,
+			service:     service,
+			expected:    &serverEndpoint{AddressV4: "server.ancientt.svc", AddressV6: "server.ancientt.svc", Port: 5601, Path: pathHeadless},
+		},
+		{
+			name:        "loadbalancer",
+			serviceType: config.KubernetesServiceTypeLoadBalancer,
+			pod:         pod,
+			service:     loadBalancerService,
+			expected:    &serverEndpoint{AddressV4: "198.51.100.20", AddressV6: "2001:db8:100::14", Port: 5601, Path: pathLoadBalancer},
+		},
+		{
+			name:        "loadbalancer hostname",
+			serviceType: config.KubernetesServiceTypeLoadBalancer,
+			pod:         pod,
+			service:     hostnameService,
+			expected:    &serverEndpoint{AddressV4: "server.example.com", AddressV6: "server.example.com", Port: 5601, Path: pathLoadBalancer},
+		},
+		{
+			name:        "exported",
+			serviceType: config.KubernetesServiceTypeExported,
+			pod:         pod,
+			service:     service,
+			expected:    &serverEndpoint{AddressV4: "server.ancientt.svc.clusterset.local", AddressV6: "server.ancientt.svc.clusterset.local", Port: 5601, Path: pathExported},
+		},
+		{
+			name:     "multus network",
+			pod:      multusPod,
+			multus:   &config.KubernetesMultus{Networks: []string{"storage@net1"}},
+			expected: &serverEndpoint{AddressV4: "192.168.100.10", AddressV6: "fd00:100::a", Port: 5601, Path: pathMultus},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			k := Kubernetes{config: &config.RunnerKubernetes{}}
+			if tt.serviceType != "" {
+				k.config.Service = &config.KubernetesService{Type: tt.serviceType}
+			}
+			k.config.Multus = tt.multus
+
+			endpoint, err := k.getServerEndpoint(tt.pod, tt.service, tt.nodeIPs, 5601)
+			require.Nil(t, err)
+			assert.Equal(t, tt.expected, endpoint)
+		})
+	}
+
+	// No NodePort allocated for the port
+	k := Kubernetes{config: &config.RunnerKubernetes{Service: &config.KubernetesService{Type: config.KubernetesServiceTypeNodePort}}}
+	_, err := k.getServerEndpoint(pod, service, nil, 5201)
+	assert.NotNil(t, err)
+
+	// LoadBalancer without ingress
+	k = Kubernetes{config: &config.RunnerKubernetes{Service: &config.KubernetesService{Type: config.KubernetesServiceTypeLoadBalancer}}}
+	_, err = k.getServerEndpoint(pod, service, nil, 5601)
+	assert.NotNil(t, err)
+
+	// Server Pod not attached to the Multus network
+	k = Kubernetes{config: &config.RunnerKubernetes{Multus: &config.KubernetesMultus{Networks: []string{"storage"}, Network: "backup"}}}
+	_, err = k.getServerEndpoint(multusPod, nil, nil, 5601)
+	assert.NotNil(t, err)
+}
+
+func TestApplyPodTemplateToPod(t *testing.T) {
+	k := Kubernetes{config: &config.RunnerKubernetes{
+		Namespace: "ancientt",
+		Hosts:     &config.KubernetesHosts{},
+		PodTemplate: &config.KubernetesPodTemplate{
+			Clients: map[string]interface{}{
+				"metadata": map[interface{}]interface{}{
+					"labels": map[interface{}]interface{}{"team": "network"},
+				},
+				"spec": map[interface{}]interface{}{
+					"priorityClassName": "high",
+					"runtimeClassName":  "kata",
+					"dnsPolicy":         "None",
+					"imagePullSecrets": []interface{}{
+						map[interface{}]interface{}{"name": "registry"},
+					},
+					"containers": []interface{}{
+						map[interface{}]interface{}{
+							"name": "ancientt",
+							"resources": map[interface{}]interface{}{
+								"limits": map[interface{}]interface{}{"cpu": "2", "memory": "1Gi"},
+							},
+							"securityContext": map[interface{}]interface{}{
+								"capabilities": map[interface{}]interface{}{
+									"add": []interface{}{"NET_RAW"},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}}
+	task := &testers.Task{
+		Host:    &testers.Host{Name: "node-1"},
+		Command: "iperf3",
+		Args:    []string{"-c", "10.0.0.1"},
+	}
+
+	pod := k.getPodSpec("ancientt-client-iperf3-1", "ancientt-iperf3-1", "ancientt", "node-1", task)
+	pod, err := k.applyPodTemplateToPod(pod, clientsRole)
+	require.Nil(t, err)
+
+	assert.Equal(t, "network", pod.ObjectMeta.Labels["team"])
+	assert.Equal(t, "ancientt-client-iperf3-1", pod.ObjectMeta.Labels["app.kubernetes.io/instance"])
+	assert.Equal(t, "high", pod.Spec.PriorityClassName)
+	require.NotNil(t, pod.Spec.RuntimeClassName)
+	assert.Equal(t, "kata", *pod.Spec.RuntimeClassName)
+	assert.Equal(t, corev1.DNSNone, pod.Spec.DNSPolicy)
+	assert.Equal(t, []corev1.LocalObjectReference{{Name: "registry"}}, pod.Spec.ImagePullSecrets)
+	assert.Equal(t, "node-1", pod.Spec.NodeSelector[corev1.LabelHostname])
+
+	// The container is merged by its name, keeping the command and args
+	require.Equal(t, 1, len(pod.Spec.Containers))
+	container := pod.Spec.Containers[0]
+	assert.Equal(t, "iperf3", container.Command[0])
+	assert.Equal(t, task.Args, container.Args)
+	assert.Equal(t, "1Gi", container.Resources.Limits.Memory().String())
+	require.NotNil(t, container.SecurityContext)
+	assert.Equal(t, []corev1.Capability{"NET_RAW"}, container.SecurityContext.Capabilities.Add)
+
+	// The server Pod has no template
+	serverPod := k.getPodSpec("ancientt-server-iperf3-1", "ancientt-iperf3-1", "ancientt", "node-1", task)
+	merged, err := k.applyPodTemplateToPod(serverPod, serverRole)
+	require.Nil(t, err)
+	assert.Equal(t, serverPod, merged)
+
+	// Unknown fields are an error
+	k.config.PodTemplate.Server = map[string]interface{}{
+		"spec": map[interface{}]interface{}{"priorityClass": "high"},
+	}
+	_, err = k.applyPodTemplateToPod(serverPod, serverRole)
+	assert.NotNil(t, err)
+}
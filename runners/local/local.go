@@ -0,0 +1,330 @@
+/*
+Copyright 2022 Cloudical Deutschland GmbH. All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package local
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"time"
+
+	"github.com/cloudical-io/ancientt/parsers"
+	"github.com/cloudical-io/ancientt/pkg/cmdtemplate"
+	"github.com/cloudical-io/ancientt/pkg/config"
+	"github.com/cloudical-io/ancientt/pkg/executor"
+	"github.com/cloudical-io/ancientt/pkg/hostsfilter"
+	"github.com/cloudical-io/ancientt/runners"
+	"github.com/cloudical-io/ancientt/testers"
+	"github.com/sirupsen/logrus"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// Name Local Runner Name
+	Name = "local"
+)
+
+func init() {
+	runners.Factories[Name] = NewRunner
+}
+
+// Local Local runner struct
+type Local struct {
+	runners.Runner
+	logger     *log.Entry
+	config     *config.RunnerLocal
+	runOptions config.RunOptions
+	executor   executor.Executor
+}
+
+// NewRunner return a new Local Runner
+func NewRunner(cfg *config.Config) (runners.Runner, error) {
+	conf := cfg.Runner.Local
+	if conf == nil {
+		conf = &config.RunnerLocal{}
+		conf.SetDefaults()
+	}
+
+	return &Local{
+		logger:   log.WithFields(logrus.Fields{"runner": Name}),
+		config:   conf,
+		executor: executor.NewCommandExecutor("runner:local"),
+	}, nil
+}
+
+func (l *Local) netnsEnabled() bool {
+	return l.config.NetworkNamespaces != nil && *l.config.NetworkNamespaces.Enabled
+}
+
+// GetHostsForTest return the list of local hosts for the given test config
+func (l *Local) GetHostsForTest(test *config.Test) (*testers.Hosts, error) {
+	available, err := l.getHosts()
+	if err != nil {
+		return nil, err
+	}
+
+	hosts := &testers.Hosts{
+		Clients: map[string]*testers.Host{},
+		Servers: map[string]*testers.Host{},
+	}
+
+	for _, servers := range test.Hosts.Servers {
+		seed, err := filterHosts(available, servers, hosts.Servers)
+		if err != nil {
+			return nil, err
+		}
+		hosts.AddSeed("servers", servers.Name, seed)
+	}
+	for _, clients := range test.Hosts.Clients {
+		seed, err := filterHosts(available, clients, hosts.Clients)
+		if err != nil {
+			return nil, err
+		}
+		hosts.AddSeed("clients", clients.Name, seed)
+	}
+
+	return hosts, nil
+}
+
+func (l *Local) getHosts() ([]*testers.Host, error) {
+	var netnsHosts map[string]*netnsHost
+	if l.netnsEnabled() {
+		var err error
+		if netnsHosts, _, _, err = l.netnsHosts(); err != nil {
+			return nil, err
+		}
+	}
+
+	hosts := []*testers.Host{}
+	for _, host := range l.config.Hosts {
+		addresses := &testers.IPAddresses{
+			IPv4: []string{"127.0.0.1"},
+			IPv6: []string{"::1"},
+		}
+		if netnsHosts != nil {
+			addresses = &testers.IPAddresses{
+				IPv4: []string{netnsHosts[host.Name].address},
+				IPv6: []string{netnsHosts[host.Name].addressV6},
+			}
+		}
+
+		labels := host.Labels
+		if labels == nil {
+			labels = map[string]string{}
+		}
+		hosts = append(hosts, &testers.Host{
+			Name:      host.Name,
+			Labels:    labels,
+			Addresses: addresses,
+		})
+	}
+
+	return hosts, nil
+}
+
+func filterHosts(available []*testers.Host, filter config.Hosts, out map[string]*testers.Host) (*int64, error) {
+	filtered, seed, err := hostsfilter.FilterHostsList(available, filter)
+	if err != nil {
+		return nil, err
+	}
+	for _, host := range filtered {
+		if _, ok := out[host.Name]; ok {
+			continue
+		}
+		// Static hosts lists of a test only contain the names of the hosts
+		found := false
+		for _, h := range available {
+			if h.Name == host.Name {
+				out[host.Name] = h
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("host %s not found in local runner hosts list", host.Name)
+		}
+	}
+
+	return seed, nil
+}
+
+// Prepare create the network namespaces when enabled
+func (l *Local) Prepare(runOpts config.RunOptions, plan *testers.Plan) error {
+	l.runOptions = runOpts
+
+	if !l.netnsEnabled() {
+		return nil
+	}
+
+	return l.setupNetworkNamespaces()
+}
+
+// command return the command and args to run the task, in the host's network namespace when enabled
+func (l *Local) command(task *testers.Task) (string, []string, error) {
+	if !l.netnsEnabled() {
+		return task.Command, task.Args, nil
+	}
+
+	hosts, _, _, err := l.netnsHosts()
+	if err != nil {
+		return "", nil, err
+	}
+	host, ok := hosts[task.Host.Name]
+	if !ok {
+		return "", nil, fmt.Errorf("host %s not found in local runner hosts list", task.Host.Name)
+	}
+
+	return l.config.IPCommand, append([]string{"netns", "exec", host.namespace, task.Command}, task.Args...), nil
+}
+
+// Execute run the given commands and return the logs of it and / or error
+func (l *Local) Execute(plan *testers.Plan, parser chan<- parsers.Input) error {
+	return runners.ExecutePlan(l.logger, plan, l.runOptions, func(round int, index int, task *testers.Task) error {
+		return l.runTasks(round, index, task, plan.TestStartTime, plan.Tester, parser)
+	})
+}
+
+func (l *Local) runTasks(round int, index int, mainTask *testers.Task, plannedTime time.Time, tester string, parser chan<- parsers.Input) error {
+	logger := l.logger.WithFields(logrus.Fields{"round": round, "hostname": mainTask.Host.Name})
+
+	// Create initial cmdtemplate.Variables, the servers of a round run at the same time with `parallelServers` and
+	// without network namespaces all listen on the same addresses, so each server gets its own port
+	templateVars := cmdtemplate.Variables{
+		ServerPort: 5601 + int32(index),
+	}
+	if len(mainTask.Host.Addresses.IPv4) > 0 {
+		templateVars.ServerAddressV4 = mainTask.Host.Addresses.IPv4[0]
+	}
+	if len(mainTask.Host.Addresses.IPv6) > 0 {
+		templateVars.ServerAddressV6 = mainTask.Host.Addresses.IPv6[0]
+	}
+
+	if err := cmdtemplate.Template(mainTask, templateVars); err != nil {
+		erro := fmt.Errorf("failed to template main task command and / or args. %+v", err)
+		logger.Error(erro)
+		mainTask.Status.AddFailedServer(mainTask.Host, erro)
+		return erro
+	}
+
+	command, args, err := l.command(mainTask)
+	if err != nil {
+		mainTask.Status.AddFailedServer(mainTask.Host, err)
+		return err
+	}
+
+	mainCtx, mainCancel := context.WithCancel(context.Background())
+	defer mainCancel()
+
+	mainDoneCh := make(chan error, 1)
+	go func() {
+		mainDoneCh <- l.executor.ExecuteCommand(mainCtx, "runner:local: run main task command", command, args...)
+	}()
+
+	// Give the server a moment to start listening, then make sure it is still running
+	select {
+	case err := <-mainDoneCh:
+		erro := fmt.Errorf("main task exited right after start. %+v", err)
+		logger.Error(erro)
+		mainTask.Status.AddFailedServer(mainTask.Host, erro)
+		return erro
+	case <-time.After(250 * time.Millisecond):
+	}
+
+	pool := runners.NewClientPool(l.runOptions)
+	for i, task := range mainTask.SubTasks {
+		logger.WithField("client", task.Host.Name).
+			Infof("running sub task %d of %d", i+1, len(mainTask.SubTasks))
+
+		task := task
+		pool.Go(func() {
+			// Template command and args for each task
+			if err := cmdtemplate.Template(task, templateVars); err != nil {
+				erro := fmt.Errorf("failed to template task command and / or args. %+v", err)
+				logger.WithFields(logrus.Fields{"client": task.Host.Name, "error": erro}).
+					Error("error during runTasks")
+				mainTask.Status.AddFailedClient(task.Host, erro)
+				return
+			}
+
+			command, args, err := l.command(task)
+			if err != nil {
+				mainTask.Status.AddFailedClient(task.Host, err)
+				return
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), l.config.Timeouts.TaskCommandTimeout)
+			defer cancel()
+
+			testTime := time.Now()
+			// Only the stdout output is given to the parser, warnings on stderr would break parsing the output
+			out, stderr, err := l.executor.ExecuteCommandWithSeparateOutput(ctx, "runner:local: run sub task command", command, args...)
+			if err != nil {
+				erro := fmt.Errorf("sub task command failed. %+v (stderr: %s)", err, bytes.TrimSpace(stderr))
+				logger.WithField("client", task.Host.Name).Error(erro)
+				mainTask.Status.AddFailedClient(task.Host, erro)
+				return
+			}
+			if len(stderr) > 0 {
+				logger.WithField("client", task.Host.Name).Warnf("sub task command printed to stderr: %s", bytes.TrimSpace(stderr))
+			}
+
+			mainTask.Status.AddSuccessfulClient(task.Host)
+
+			r := ioutil.NopCloser(bytes.NewReader(out))
+			parser <- parsers.Input{
+				TestStartTime:  plannedTime,
+				TestTime:       testTime,
+				Round:          round,
+				DataStream:     &r,
+				Tester:         tester,
+				ServerHost:     mainTask.Host.Name,
+				ClientHost:     task.Host.Name,
+				IPFamily:       string(task.IPFamily),
+				AdditionalInfo: l.additionalInfo(task.Host.Name),
+			}
+		})
+	}
+	pool.Wait()
+
+	logger.Info("stopping main task")
+	mainCancel()
+	// The main task is killed by the context, so its error can be ignored
+	<-mainDoneCh
+	mainTask.Status.AddSuccessfulServer(mainTask.Host)
+
+	logger.Debug("done running tasks for test locally for plan")
+
+	return nil
+}
+
+func (l *Local) additionalInfo(host string) string {
+	if !l.netnsEnabled() {
+		return "local"
+	}
+	hosts, _, _, err := l.netnsHosts()
+	if err != nil {
+		return "local"
+	}
+	return fmt.Sprintf("netns=%s", hosts[host].namespace)
+}
+
+// Cleanup remove the network namespaces when enabled
+func (l *Local) Cleanup(plan *testers.Plan) error {
+	if !l.netnsEnabled() {
+		return nil
+	}
+
+	return l.cleanupNetworkNamespaces()
+}
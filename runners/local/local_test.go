@@ -0,0 +1,187 @@
+/*
+Copyright 2022 Cloudical Deutschland GmbH. All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package local
+
+import (
+	"context"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/cloudical-io/ancientt/parsers"
+	"github.com/cloudical-io/ancientt/pkg/config"
+	exectest "github.com/cloudical-io/ancientt/pkg/executor/test"
+	"github.com/cloudical-io/ancientt/pkg/util"
+	"github.com/cloudical-io/ancientt/testers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testHosts = config.TestHosts{
+	Servers: []config.Hosts{{Name: "servers", Hosts: []string{"local-0"}}},
+	Clients: []config.Hosts{{Name: "clients", Hosts: []string{"local-1"}}},
+}
+
+func newTestRunner(t *testing.T, netns bool) *Local {
+	cfg := config.New()
+	cfg.Runner.Local = &config.RunnerLocal{
+		NetworkNamespaces: &config.LocalNetworkNamespaces{
+			Enabled: util.BoolFalsePointer(),
+		},
+	}
+	if netns {
+		cfg.Runner.Local.NetworkNamespaces.Enabled = util.BoolTruePointer()
+	}
+	cfg.Runner.Local.SetDefaults()
+
+	runner, err := NewRunner(cfg)
+	require.Nil(t, err)
+
+	return runner.(*Local)
+}
+
+func newTestPlan(hosts *testers.Hosts, command string, args ...string) *testers.Plan {
+	return &testers.Plan{
+		TestStartTime: time.Now(),
+		Tester:        "test",
+		RunOptions:    config.RunOptions{ContinueOnError: util.BoolFalsePointer()},
+		Commands: [][]*testers.Task{{
+			{
+				Host:    hosts.Servers["local-0"],
+				Command: "sleep",
+				Args:    []string{"60"},
+				SubTasks: []*testers.Task{
+					{Host: hosts.Clients["local-1"], Command: command, Args: args},
+				},
+				Status: &testers.Status{
+					SuccessfulHosts: testers.StatusHosts{Servers: map[string]int{}, Clients: map[string]int{}},
+					FailedHosts:     testers.StatusHosts{Servers: map[string]int{}, Clients: map[string]int{}},
+					Errors:          map[string][]error{},
+				},
+			},
+		}},
+	}
+}
+
+func readInputs(inCh chan parsers.Input) chan string {
+	outCh := make(chan string, 1)
+	go func() {
+		for input := range inCh {
+			out, _ := io.ReadAll(*input.DataStream)
+			outCh <- string(out)
+		}
+	}()
+	return outCh
+}
+
+func TestGetHostsForTest(t *testing.T) {
+	l := newTestRunner(t, false)
+
+	hosts, err := l.GetHostsForTest(&config.Test{Hosts: testHosts})
+	require.Nil(t, err)
+	require.Equal(t, 1, len(hosts.Servers))
+	require.Equal(t, 1, len(hosts.Clients))
+	assert.Equal(t, []string{"127.0.0.1"}, hosts.Servers["local-0"].Addresses.IPv4)
+
+	l = newTestRunner(t, true)
+	hosts, err = l.GetHostsForTest(&config.Test{Hosts: testHosts})
+	require.Nil(t, err)
+	assert.Equal(t, []string{"10.213.0.1"}, hosts.Servers["local-0"].Addresses.IPv4)
+	assert.Equal(t, []string{"10.213.0.2"}, hosts.Clients["local-1"].Addresses.IPv4)
+	assert.Equal(t, []string{"fd00:213::2"}, hosts.Clients["local-1"].Addresses.IPv6)
+
+	_, err = l.GetHostsForTest(&config.Test{Hosts: config.TestHosts{
+		Servers: []config.Hosts{{Name: "servers", Hosts: []string{"unknown"}}},
+	}})
+	assert.NotNil(t, err)
+}
+
+func TestExecute(t *testing.T) {
+	l := newTestRunner(t, false)
+
+	hosts, err := l.GetHostsForTest(&config.Test{Hosts: testHosts})
+	require.Nil(t, err)
+	// The stderr output must not be given to the parser
+	plan := newTestPlan(hosts, "sh", "-c", "echo {{ .ServerAddressV4 }}; echo warning >&2")
+	require.Nil(t, l.Prepare(plan.RunOptions, plan))
+
+	inCh := make(chan parsers.Input)
+	outCh := readInputs(inCh)
+	require.Nil(t, l.Execute(plan, inCh))
+	close(inCh)
+
+	assert.Equal(t, "127.0.0.1\n", <-outCh)
+	status := plan.Commands[0][0].Status
+	assert.Equal(t, 1, status.SuccessfulHosts.Servers["local-0"])
+	assert.Equal(t, 1, status.SuccessfulHosts.Clients["local-1"])
+	require.Nil(t, l.Cleanup(plan))
+}
+
+func TestExecuteNetworkNamespaces(t *testing.T) {
+	l := newTestRunner(t, true)
+
+	var lock sync.Mutex
+	ipCommands := []string{}
+	l.executor = exectest.MockExecutor{
+		MockExecuteCommand: func(ctx context.Context, actionName string, command string, arg ...string) error {
+			lock.Lock()
+			ipCommands = append(ipCommands, strings.Join(append([]string{command}, arg...), " "))
+			lock.Unlock()
+			<-ctx.Done()
+			return ctx.Err()
+		},
+		MockExecuteCommandWithOutput: func(ctx context.Context, actionName string, command string, arg ...string) (string, error) {
+			lock.Lock()
+			defer lock.Unlock()
+			ipCommands = append(ipCommands, strings.Join(append([]string{command}, arg...), " "))
+			return "", nil
+		},
+		MockExecuteCommandWithOutputByte: func(ctx context.Context, actionName string, command string, arg ...string) ([]byte, error) {
+			lock.Lock()
+			defer lock.Unlock()
+			ipCommands = append(ipCommands, strings.Join(append([]string{command}, arg...), " "))
+			return []byte("{}"), nil
+		},
+	}
+
+	hosts, err := l.GetHostsForTest(&config.Test{Hosts: testHosts})
+	require.Nil(t, err)
+	plan := newTestPlan(hosts, "iperf3", "--client={{ .ServerAddressV4 }}")
+	require.Nil(t, l.Prepare(plan.RunOptions, plan))
+
+	assert.Contains(t, ipCommands, "ip link add ancienttbr type bridge")
+	assert.Contains(t, ipCommands, "ip netns add ancientt-0")
+	assert.Contains(t, ipCommands, "ip link add ancientt0h type veth peer name ancientt0n")
+	assert.Contains(t, ipCommands, "ip -n ancientt-1 addr add 10.213.0.2/24 dev ancientt1n")
+	assert.Contains(t, ipCommands, "ip -n ancientt-1 addr add fd00:213::2/64 dev ancientt1n nodad")
+
+	inCh := make(chan parsers.Input)
+	outCh := readInputs(inCh)
+	require.Nil(t, l.Execute(plan, inCh))
+	close(inCh)
+	assert.Equal(t, "{}", <-outCh)
+
+	assert.Contains(t, ipCommands, "ip netns exec ancientt-0 sleep 60")
+	assert.Contains(t, ipCommands, "ip netns exec ancientt-1 iperf3 --client=10.213.0.1")
+
+	ipCommands = []string{}
+	require.Nil(t, l.Cleanup(plan))
+	assert.Equal(t, []string{
+		"ip netns delete ancientt-0",
+		"ip netns delete ancientt-1",
+		"ip link delete ancienttbr",
+	}, ipCommands)
+}
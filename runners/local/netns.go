@@ -0,0 +1,175 @@
+/*
+Copyright 2022 Cloudical Deutschland GmbH. All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package local
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"net"
+)
+
+// netnsHost network namespace and veth pair names and addresses of a host
+type netnsHost struct {
+	namespace string
+	vethHost  string
+	vethNS    string
+	address   string
+	addressV6 string
+}
+
+// bridgeName name of the bridge the veth pairs of the hosts are connected to
+func (l *Local) bridgeName() string {
+	return fmt.Sprintf("%sbr", l.config.NetworkNamespaces.Prefix)
+}
+
+// netnsHosts calculate the network namespace names and addresses for the hosts, addresses are assigned in the order of
+// the hosts starting with the first usable address of the IPv4 and IPv6 subnet. The prefix lengths of the subnets are
+// returned as well.
+func (l *Local) netnsHosts() (map[string]*netnsHost, int, int, error) {
+	subnet, prefixLength, err := netnsSubnet(l.config.NetworkNamespaces.Subnet, net.IPv4len, len(l.config.Hosts))
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	subnetV6, prefixLengthV6, err := netnsSubnet(l.config.NetworkNamespaces.SubnetV6, net.IPv6len, len(l.config.Hosts))
+	if err != nil {
+		return nil, 0, 0, err
+	}
+
+	hosts := map[string]*netnsHost{}
+	prefix := l.config.NetworkNamespaces.Prefix
+	for i, host := range l.config.Hosts {
+		hosts[host.Name] = &netnsHost{
+			namespace: fmt.Sprintf("%s-%d", prefix, i),
+			vethHost:  fmt.Sprintf("%s%dh", prefix, i),
+			vethNS:    fmt.Sprintf("%s%dn", prefix, i),
+			address:   addToIP(subnet, uint32(i)+1).String(),
+			addressV6: addToIP(subnetV6, uint32(i)+1).String(),
+		}
+	}
+
+	return hosts, prefixLength, prefixLengthV6, nil
+}
+
+// netnsSubnet parse the subnet and check that it is of the IP family (by length) and big enough for the hosts
+func netnsSubnet(cidr string, length int, hosts int) (net.IP, int, error) {
+	ip, subnet, err := net.ParseCIDR(cidr)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to parse network namespaces subnet %s. %+v", cidr, err)
+	}
+	ones, bits := subnet.Mask.Size()
+	if bits != length*8 {
+		return nil, 0, fmt.Errorf("network namespaces subnet %s is of the wrong IP family", cidr)
+	}
+	// The network and (for IPv4) the broadcast address can't be used
+	if bits-ones < 32 && uint64(hosts) > (uint64(1)<<uint(bits-ones))-2 {
+		return nil, 0, fmt.Errorf("network namespaces subnet %s too small for %d hosts", subnet.String(), hosts)
+	}
+
+	ip = ip.Mask(subnet.Mask)
+	if length == net.IPv4len {
+		ip = ip.To4()
+	}
+	return ip, ones, nil
+}
+
+// addToIP return a copy of the IP with n added to it
+func addToIP(ip net.IP, n uint32) net.IP {
+	out := make(net.IP, len(ip))
+	copy(out, ip)
+	last := len(out) - net.IPv4len
+	binary.BigEndian.PutUint32(out[last:], binary.BigEndian.Uint32(out[last:])+n)
+	return out
+}
+
+// setupNetworkNamespaces create a network namespace per host connected through veth pairs to a bridge
+func (l *Local) setupNetworkNamespaces() error {
+	// Remove left behind network namespaces and bridge, e.g., from a run with `--no-cleanup`
+	l.cleanupNetworkNamespaces()
+
+	hosts, prefixLength, prefixLengthV6, err := l.netnsHosts()
+	if err != nil {
+		return err
+	}
+
+	bridge := l.bridgeName()
+	if err := l.ip("create bridge", "link", "add", bridge, "type", "bridge"); err != nil {
+		return err
+	}
+	if err := l.ip("set bridge up", "link", "set", bridge, "up"); err != nil {
+		return err
+	}
+
+	for _, host := range l.config.Hosts {
+		h := hosts[host.Name]
+		l.logger.WithField("hostname", host.Name).Debugf("creating network namespace %s with addresses %s and %s", h.namespace, h.address, h.addressV6)
+
+		for _, step := range []struct {
+			action string
+			args   []string
+		}{
+			{"create network namespace", []string{"netns", "add", h.namespace}},
+			{"create veth pair", []string{"link", "add", h.vethHost, "type", "veth", "peer", "name", h.vethNS}},
+			{"move veth into network namespace", []string{"link", "set", h.vethNS, "netns", h.namespace}},
+			{"attach veth to bridge", []string{"link", "set", h.vethHost, "master", bridge}},
+			{"set veth up", []string{"link", "set", h.vethHost, "up"}},
+			{"set loopback up in network namespace", []string{"-n", h.namespace, "link", "set", "lo", "up"}},
+			{"add address in network namespace", []string{"-n", h.namespace, "addr", "add", fmt.Sprintf("%s/%d", h.address, prefixLength), "dev", h.vethNS}},
+			// Without duplicate address detection the address is usable right away and not only after a few seconds
+			{"add IPv6 address in network namespace", []string{"-n", h.namespace, "addr", "add", fmt.Sprintf("%s/%d", h.addressV6, prefixLengthV6), "dev", h.vethNS, "nodad"}},
+			{"set veth up in network namespace", []string{"-n", h.namespace, "link", "set", h.vethNS, "up"}},
+		} {
+			if err := l.ip(step.action, step.args...); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// cleanupNetworkNamespaces remove the network namespaces and bridge, deleting a network namespace removes the veth
+// pair in it as well
+func (l *Local) cleanupNetworkNamespaces() error {
+	hosts, _, _, err := l.netnsHosts()
+	if err != nil {
+		return err
+	}
+
+	var lastErr error
+	for _, host := range l.config.Hosts {
+		if err := l.ip("delete network namespace", "netns", "delete", hosts[host.Name].namespace); err != nil {
+			l.logger.WithField("hostname", host.Name).Debug(err)
+			lastErr = err
+		}
+	}
+	if err := l.ip("delete bridge", "link", "delete", l.bridgeName()); err != nil {
+		l.logger.Debug(err)
+		lastErr = err
+	}
+
+	return lastErr
+}
+
+func (l *Local) ip(action string, args ...string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), l.config.Timeouts.CommandTimeout)
+	defer cancel()
+
+	out, err := l.executor.ExecuteCommandWithOutput(ctx, "runner:local: "+action, l.config.IPCommand, args...)
+	if err != nil {
+		return fmt.Errorf("failed to %s (ip %v). %+v (output: %s)", action, args, err, out)
+	}
+
+	return nil
+}
@@ -60,10 +60,11 @@ func (m Mock) GetHostsForTest(test *config.Test) (*testers.Hosts, error) {
 
 	// Go through Hosts Servers list to get the servers hosts
 	for _, servers := range test.Hosts.Servers {
-		filtered, err := hostsfilter.FilterHostsList(mockHosts, servers)
+		filtered, seed, err := hostsfilter.FilterHostsList(mockHosts, servers)
 		if err != nil {
 			return nil, err
 		}
+		hosts.AddSeed("servers", servers.Name, seed)
 		for _, host := range filtered {
 			if _, ok := hosts.Servers[host.Name]; !ok {
 				hosts.Servers[host.Name] = host
@@ -73,10 +74,11 @@ func (m Mock) GetHostsForTest(test *config.Test) (*testers.Hosts, error) {
 
 	// Go through Hosts Clients list to get the clients hosts
 	for _, clients := range test.Hosts.Clients {
-		filtered, err := hostsfilter.FilterHostsList(mockHosts, clients)
+		filtered, seed, err := hostsfilter.FilterHostsList(mockHosts, clients)
 		if err != nil {
 			return nil, err
 		}
+		hosts.AddSeed("clients", clients.Name, seed)
 		for _, host := range filtered {
 			if _, ok := hosts.Clients[host.Name]; !ok {
 				hosts.Clients[host.Name] = host
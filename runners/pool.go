@@ -0,0 +1,142 @@
+/*
+Copyright 2022 Cloudical Deutschland GmbH. All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package runners
+
+import (
+	"sync"
+	"time"
+
+	"github.com/cloudical-io/ancientt/pkg/config"
+	"github.com/cloudical-io/ancientt/testers"
+	log "github.com/sirupsen/logrus"
+)
+
+// Pool runs funcs concurrently with a limit on how many are running at the same time
+type Pool struct {
+	slots chan struct{}
+	wg    sync.WaitGroup
+}
+
+// NewPool return a new Pool running up to size funcs at the same time, a size of zero or less means no limit
+func NewPool(size int) *Pool {
+	p := &Pool{}
+	if size > 0 {
+		p.slots = make(chan struct{}, size)
+	}
+	return p
+}
+
+// NewClientPool return a Pool for the client tasks of a server task. In `sequential` mode one client task is run at a
+// time, in `parallel` mode up to `ParallelCount` client tasks (no limit when not set).
+func NewClientPool(runOpts config.RunOptions) *Pool {
+	if runOpts.Mode != config.RunModeParallel {
+		return NewPool(1)
+	}
+	return NewPool(runOpts.ParallelCount)
+}
+
+// NewServerPool return a Pool for the server tasks of a round. In `sequential` mode one server task is run at a time,
+// in `parallel` mode up to `ParallelServers` server tasks.
+func NewServerPool(runOpts config.RunOptions) *Pool {
+	if runOpts.Mode != config.RunModeParallel || runOpts.ParallelServers < 1 {
+		return NewPool(1)
+	}
+	return NewPool(runOpts.ParallelServers)
+}
+
+// Go run the func in a goroutine, blocks until the func can be run without exceeding the limit of the Pool
+func (p *Pool) Go(fn func()) {
+	if p.slots != nil {
+		p.slots <- struct{}{}
+	}
+	p.wg.Add(1)
+	go func() {
+		defer func() {
+			if p.slots != nil {
+				<-p.slots
+			}
+			p.wg.Done()
+		}()
+		fn()
+	}()
+}
+
+// Wait wait for all funcs of the Pool to return
+func (p *Pool) Wait() {
+	p.wg.Wait()
+}
+
+// ExecutePlan run the server tasks of the plan round by round using the given func, up to `ParallelServers` server
+// tasks of a round are run at the same time in `parallel` mode. Before a sleep task and at the end of each round, the
+// running server tasks are waited for. When `ContinueOnError` is false, no further server tasks are started after an
+// error and the first error is returned. The func gets the index of the server task in the round, to give the
+// concurrently running server tasks unique names and ports.
+func ExecutePlan(logger *log.Entry, plan *testers.Plan, runOpts config.RunOptions, run func(round int, index int, task *testers.Task) error) error {
+	continueOnError := runOpts.ContinueOnError == nil || *runOpts.ContinueOnError
+
+	var mutex sync.Mutex
+	var firstErr error
+
+	for round, tasks := range plan.Commands {
+		logger.Infof("running commands round %d of %d", round+1, len(plan.Commands))
+
+		pool := NewServerPool(runOpts)
+		for i, task := range tasks {
+			if task.Sleep != 0 {
+				pool.Wait()
+				logger.Infof("waiting %s to pass before continuing next round", task.Sleep.String())
+				time.Sleep(task.Sleep)
+				continue
+			}
+
+			mutex.Lock()
+			failed := firstErr != nil
+			mutex.Unlock()
+			if failed {
+				break
+			}
+
+			logger.Infof("running task round %d of %d", i+1, len(tasks))
+			round, index, task := round, i, task
+			pool.Go(func() {
+				// A server task run before this one got its slot might have failed in the meantime
+				mutex.Lock()
+				failed := firstErr != nil
+				mutex.Unlock()
+				if failed {
+					return
+				}
+
+				if err := run(round, index, task); err != nil {
+					if continueOnError {
+						logger.Warnf("continuing after err. %+v", err)
+						return
+					}
+					mutex.Lock()
+					if firstErr == nil {
+						firstErr = err
+					}
+					mutex.Unlock()
+				}
+			})
+		}
+		pool.Wait()
+
+		if firstErr != nil {
+			return firstErr
+		}
+	}
+
+	return nil
+}
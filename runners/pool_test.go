@@ -0,0 +1,193 @@
+/*
+Copyright 2022 Cloudical Deutschland GmbH. All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package runners
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/cloudical-io/ancientt/pkg/config"
+	"github.com/cloudical-io/ancientt/pkg/util"
+	"github.com/cloudical-io/ancientt/testers"
+	"github.com/cloudical-io/ancientt/testers/iperf3"
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+// concurrency tracks how many funcs are running at the same time
+type concurrency struct {
+	mutex   sync.Mutex
+	running int
+	max     int
+	total   int
+}
+
+func (c *concurrency) run() {
+	c.mutex.Lock()
+	c.running++
+	c.total++
+	if c.running > c.max {
+		c.max = c.running
+	}
+	c.mutex.Unlock()
+
+	time.Sleep(20 * time.Millisecond)
+
+	c.mutex.Lock()
+	c.running--
+	c.mutex.Unlock()
+}
+
+func TestClientPool(t *testing.T) {
+	tests := []struct {
+		name    string
+		runOpts config.RunOptions
+		max     int
+	}{
+		{
+			name:    "sequential",
+			runOpts: config.RunOptions{Mode: config.RunModeSequential, ParallelCount: 5},
+			max:     1,
+		},
+		{
+			name:    "parallel limited",
+			runOpts: config.RunOptions{Mode: config.RunModeParallel, ParallelCount: 3},
+			max:     3,
+		},
+		{
+			name:    "parallel unlimited",
+			runOpts: config.RunOptions{Mode: config.RunModeParallel},
+			max:     10,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &concurrency{}
+			pool := NewClientPool(tt.runOpts)
+			for i := 0; i < 10; i++ {
+				pool.Go(c.run)
+			}
+			pool.Wait()
+
+			assert.Equal(t, 10, c.total)
+			assert.Equal(t, tt.max, c.max)
+		})
+	}
+}
+
+func generatePlan(rounds int, servers int, sleep time.Duration) *testers.Plan {
+	plan := &testers.Plan{}
+	for i := 0; i < rounds; i++ {
+		tasks := []*testers.Task{}
+		for j := 0; j < servers; j++ {
+			tasks = append(tasks, &testers.Task{Host: &testers.Host{Name: fmt.Sprintf("server%d", j)}})
+		}
+		if sleep != 0 {
+			tasks = append(tasks, &testers.Task{Sleep: sleep})
+		}
+		plan.Commands = append(plan.Commands, tasks)
+	}
+	return plan
+}
+
+func TestExecutePlanParallelServers(t *testing.T) {
+	logger := log.WithField("test", t.Name())
+	runOpts := config.RunOptions{
+		Mode:            config.RunModeParallel,
+		ParallelServers: 2,
+	}
+
+	c := &concurrency{}
+	var mutex sync.Mutex
+	rounds := []int{}
+	err := ExecutePlan(logger, generatePlan(2, 5, time.Millisecond), runOpts, func(round int, index int, task *testers.Task) error {
+		mutex.Lock()
+		rounds = append(rounds, round)
+		mutex.Unlock()
+		c.run()
+		return nil
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, 10, c.total)
+	assert.Equal(t, 2, c.max)
+	// The server tasks of a round are done before the next round starts
+	assert.Equal(t, []int{0, 0, 0, 0, 0, 1, 1, 1, 1, 1}, rounds)
+}
+
+func TestExecutePlanParallelServersWithInterval(t *testing.T) {
+	logger := log.WithField("test", t.Name())
+	test := &config.Test{
+		Type: "iperf3",
+		RunOptions: config.RunOptions{
+			Rounds:          2,
+			Interval:        time.Millisecond,
+			Mode:            config.RunModeParallel,
+			ParallelServers: 2,
+		},
+		Pairing: config.Pairing{Strategy: config.PairingStrategyMesh},
+		IPerf3:  &config.IPerf3{},
+	}
+	test.IPerf3.SetDefaults()
+	tester, err := iperf3.NewIPerf3Tester(nil, test)
+	assert.Nil(t, err)
+	servers := map[string]*testers.Host{}
+	for i := 0; i < 4; i++ {
+		name := fmt.Sprintf("host%d", i)
+		servers[name] = &testers.Host{Name: name}
+	}
+	plan, err := tester.Plan(&testers.Environment{Hosts: &testers.Hosts{Servers: servers, Clients: map[string]*testers.Host{}}}, test)
+	assert.Nil(t, err)
+
+	// The interval doesn't keep the server tasks of a round from running at the same time
+	c := &concurrency{}
+	var mutex sync.Mutex
+	maxPerRound := map[int]int{}
+	err = ExecutePlan(logger, plan, test.RunOptions, func(round int, index int, task *testers.Task) error {
+		c.run()
+		mutex.Lock()
+		if c.max > maxPerRound[round] {
+			maxPerRound[round] = c.max
+		}
+		mutex.Unlock()
+		return nil
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, 8, c.total)
+	assert.Equal(t, 2, maxPerRound[0])
+}
+
+func TestExecutePlanErrors(t *testing.T) {
+	logger := log.WithField("test", t.Name())
+
+	// Continue on error runs all server tasks
+	calls := 0
+	err := ExecutePlan(logger, generatePlan(2, 2, 0), config.RunOptions{ContinueOnError: util.BoolTruePointer()}, func(round int, index int, task *testers.Task) error {
+		calls++
+		return fmt.Errorf("task failed")
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, 4, calls)
+
+	// Without continue on error the first error is returned and no other server tasks are started
+	calls = 0
+	err = ExecutePlan(logger, generatePlan(2, 2, 0), config.RunOptions{ContinueOnError: util.BoolFalsePointer()}, func(round int, index int, task *testers.Task) error {
+		calls++
+		return fmt.Errorf("task %s failed", task.Host.Name)
+	})
+	assert.Equal(t, fmt.Errorf("task server0 failed"), err)
+	assert.Equal(t, 1, calls)
+}
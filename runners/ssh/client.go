@@ -0,0 +1,296 @@
+/*
+Copyright 2022 Cloudical Deutschland GmbH. All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ssh
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/cloudical-io/ancientt/pkg/config"
+	homedir "github.com/mitchellh/go-homedir"
+	gossh "golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+// clients SSH client connections to the hosts, each host is only connected to once
+type clients struct {
+	sync.Mutex
+	config *config.RunnerSSH
+	// authMethods auth methods of the hosts without own private key files
+	authMethods []gossh.AuthMethod
+	// hostAuthMethods auth methods of the hosts with own private key files
+	hostAuthMethods map[string][]gossh.AuthMethod
+	hostKeyCB       gossh.HostKeyCallback
+	conns           map[string]*gossh.Client
+	// agentSock path of the ssh agent socket, the agent is (re-)connected to when its signers are needed
+	agentSock string
+	agentLock sync.Mutex
+	agentConn net.Conn
+}
+
+func newClients(conf *config.RunnerSSH, hosts map[string]*config.SSHHost) (*clients, error) {
+	c := &clients{
+		config:          conf,
+		hostAuthMethods: map[string][]gossh.AuthMethod{},
+		conns:           map[string]*gossh.Client{},
+	}
+
+	if *conf.InsecureIgnoreHostKey {
+		c.hostKeyCB = gossh.InsecureIgnoreHostKey()
+	} else {
+		knownHostsFile := conf.KnownHostsFile
+		if knownHostsFile == "" {
+			var err error
+			if knownHostsFile, err = homedir.Expand("~/.ssh/known_hosts"); err != nil {
+				return nil, err
+			}
+		}
+		hostKeyCB, err := knownhosts.New(knownHostsFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load known hosts file %s. %+v", knownHostsFile, err)
+		}
+		c.hostKeyCB = hostKeyCB
+	}
+
+	var agentAuth gossh.AuthMethod
+	if *conf.UseAgent {
+		if c.agentSock = os.Getenv("SSH_AUTH_SOCK"); c.agentSock != "" {
+			agentAuth = gossh.PublicKeysCallback(c.agentSigners)
+		}
+	}
+
+	var err error
+	if c.authMethods, err = loadAuthMethods(conf.PrivateKeyFiles, agentAuth); err != nil {
+		return nil, err
+	}
+
+	for name, host := range hosts {
+		if len(host.PrivateKeyFiles) == 0 {
+			if len(c.authMethods) == 0 {
+				return nil, fmt.Errorf("no ssh authentication methods available for host %s, configure private key files and / or an ssh agent", name)
+			}
+			continue
+		}
+		methods, err := loadAuthMethods(host.PrivateKeyFiles, agentAuth)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load private keys of host %s. %+v", name, err)
+		}
+		c.hostAuthMethods[name] = methods
+	}
+
+	return c, nil
+}
+
+// loadAuthMethods return the auth methods for the private key files (see loadPrivateKeys()) and the ssh agent
+func loadAuthMethods(files []string, agentAuth gossh.AuthMethod) ([]gossh.AuthMethod, error) {
+	methods := []gossh.AuthMethod{}
+
+	signers, err := loadPrivateKeys(files)
+	if err != nil {
+		return nil, err
+	}
+	if len(signers) > 0 {
+		methods = append(methods, gossh.PublicKeys(signers...))
+	}
+	if agentAuth != nil {
+		methods = append(methods, agentAuth)
+	}
+
+	return methods, nil
+}
+
+// loadPrivateKeys load the given private key files, when no files are given the default key files which exist are
+// loaded
+func loadPrivateKeys(files []string) ([]gossh.Signer, error) {
+	ignoreMissing := false
+	if len(files) == 0 {
+		ignoreMissing = true
+		for _, name := range []string{"id_rsa", "id_ecdsa", "id_ed25519"} {
+			file, err := homedir.Expand(filepath.Join("~/.ssh", name))
+			if err != nil {
+				return nil, err
+			}
+			files = append(files, file)
+		}
+	}
+
+	signers := []gossh.Signer{}
+	for _, file := range files {
+		file, err := homedir.Expand(file)
+		if err != nil {
+			return nil, err
+		}
+		key, err := ioutil.ReadFile(file)
+		if err != nil {
+			if ignoreMissing && os.IsNotExist(err) {
+				continue
+			}
+			return nil, fmt.Errorf("failed to read private key file %s. %+v", file, err)
+		}
+		signer, err := gossh.ParsePrivateKey(key)
+		if err != nil {
+			if _, ok := err.(*gossh.PassphraseMissingError); ok && ignoreMissing {
+				continue
+			}
+			return nil, fmt.Errorf("failed to parse private key file %s. %+v", file, err)
+		}
+		signers = append(signers, signer)
+	}
+
+	return signers, nil
+}
+
+// agentSigners return the signers of the ssh agent, connecting to the agent when not connected yet
+func (c *clients) agentSigners() ([]gossh.Signer, error) {
+	c.agentLock.Lock()
+	defer c.agentLock.Unlock()
+
+	if c.agentConn == nil {
+		conn, err := net.Dial("unix", c.agentSock)
+		if err != nil {
+			return nil, fmt.Errorf("failed to connect to ssh agent. %+v", err)
+		}
+		c.agentConn = conn
+	}
+
+	return agent.NewClient(c.agentConn).Signers()
+}
+
+// get return the (cached) client connection for the host, the hosts are connected to at the same time
+func (c *clients) get(ctx context.Context, host *config.SSHHost) (*gossh.Client, error) {
+	c.Lock()
+	conn, ok := c.conns[host.Name]
+	c.Unlock()
+	if ok {
+		return conn, nil
+	}
+
+	authMethods, ok := c.hostAuthMethods[host.Name]
+	if !ok {
+		authMethods = c.authMethods
+	}
+
+	clientConfig := &gossh.ClientConfig{
+		User:            host.User,
+		Auth:            authMethods,
+		HostKeyCallback: c.hostKeyCB,
+		Timeout:         c.config.Timeouts.ConnectTimeout,
+	}
+
+	address := net.JoinHostPort(host.Address, strconv.Itoa(host.Port))
+	dialer := net.Dialer{Timeout: c.config.Timeouts.ConnectTimeout}
+	netConn, err := dialer.DialContext(ctx, "tcp", address)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to host %s (%s). %+v", host.Name, address, err)
+	}
+	sshConn, chans, reqs, err := gossh.NewClientConn(netConn, address, clientConfig)
+	if err != nil {
+		netConn.Close()
+		return nil, fmt.Errorf("failed to establish ssh connection to host %s (%s). %+v", host.Name, address, err)
+	}
+
+	conn = gossh.NewClient(sshConn, chans, reqs)
+
+	c.Lock()
+	defer c.Unlock()
+	// Another task might have connected to the host in the meantime
+	if existing, ok := c.conns[host.Name]; ok {
+		conn.Close()
+		return existing, nil
+	}
+	c.conns[host.Name] = conn
+
+	return conn, nil
+}
+
+// run run the command on the host and return its output
+func (c *clients) run(ctx context.Context, host *config.SSHHost, command string) ([]byte, error) {
+	conn, err := c.get(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+
+	session, err := conn.NewSession()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create ssh session on host %s. %+v", host.Name, err)
+	}
+	defer session.Close()
+
+	var stdout bytes.Buffer
+	var stderr bytes.Buffer
+	session.Stdout = &stdout
+	session.Stderr = &stderr
+
+	if err := session.Start(command); err != nil {
+		return nil, fmt.Errorf("failed to start command on host %s. %+v", host.Name, err)
+	}
+	if err := waitSession(ctx, session); err != nil {
+		return stdout.Bytes(), fmt.Errorf("command %q failed on host %s. %+v (stderr: %s)", command, host.Name, err, strings.TrimSpace(stderr.String()))
+	}
+
+	return stdout.Bytes(), nil
+}
+
+// waitSession wait for the session's command to exit, the session is closed when the context is done before
+func waitSession(ctx context.Context, session *gossh.Session) error {
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- session.Wait()
+	}()
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+		session.Close()
+		return ctx.Err()
+	}
+}
+
+// close close all client connections and the ssh agent connection
+func (c *clients) close() error {
+	c.Lock()
+	defer c.Unlock()
+
+	var errs []string
+	for name, conn := range c.conns {
+		if err := conn.Close(); err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %+v", name, err))
+		}
+		delete(c.conns, name)
+	}
+
+	c.agentLock.Lock()
+	if c.agentConn != nil {
+		if err := c.agentConn.Close(); err != nil {
+			errs = append(errs, fmt.Sprintf("ssh agent: %+v", err))
+		}
+		c.agentConn = nil
+	}
+	c.agentLock.Unlock()
+	if len(errs) > 0 {
+		return fmt.Errorf("errors closing ssh connections. %s", strings.Join(errs, ", "))
+	}
+
+	return nil
+}
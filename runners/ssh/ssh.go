@@ -0,0 +1,517 @@
+/*
+Copyright 2022 Cloudical Deutschland GmbH. All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ssh
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"os/user"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cloudical-io/ancientt/parsers"
+	"github.com/cloudical-io/ancientt/pkg/cmdtemplate"
+	"github.com/cloudical-io/ancientt/pkg/config"
+	"github.com/cloudical-io/ancientt/pkg/hostsfilter"
+	"github.com/cloudical-io/ancientt/pkg/util"
+	"github.com/cloudical-io/ancientt/runners"
+	"github.com/cloudical-io/ancientt/testers"
+	"github.com/sirupsen/logrus"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// Name SSH Runner Name
+	Name = "ssh"
+)
+
+func init() {
+	runners.Factories[Name] = NewRunner
+}
+
+// SSH SSH runner struct
+type SSH struct {
+	runners.Runner
+	logger     *log.Entry
+	config     *config.RunnerSSH
+	runOptions config.RunOptions
+	hosts      map[string]*config.SSHHost
+	hostsOrder []string
+	clients    *clients
+	// servers running server (main) tasks per task, as the same host can run multiple server tasks in parallel
+	servers     map[*testers.Task]*server
+	serversLock sync.Mutex
+}
+
+// NewRunner return a new SSH Runner
+func NewRunner(cfg *config.Config) (runners.Runner, error) {
+	conf := cfg.Runner.SSH
+	if conf == nil {
+		return nil, fmt.Errorf("no ssh runner config given")
+	}
+
+	s := &SSH{
+		logger:  log.WithFields(logrus.Fields{"runner": Name}),
+		config:  conf,
+		hosts:   map[string]*config.SSHHost{},
+		servers: map[*testers.Task]*server{},
+	}
+
+	hosts := conf.Hosts
+	if conf.SSHConfigFile != "" {
+		sshConfigHosts, err := loadSSHConfig(conf.SSHConfigFile)
+		if err != nil {
+			return nil, err
+		}
+		hosts = append(hosts, sshConfigHosts...)
+	}
+
+	defaultUser := conf.User
+	if defaultUser == "" {
+		current, err := user.Current()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get current user for ssh runner. %+v", err)
+		}
+		defaultUser = current.Username
+	}
+
+	for _, host := range hosts {
+		// The static hosts list comes first, so it takes precedence over the ssh config hosts
+		if _, ok := s.hosts[host.Name]; ok {
+			continue
+		}
+		h := *host
+		if h.Address == "" {
+			h.Address = h.Name
+		}
+		if h.Port == 0 {
+			h.Port = conf.Port
+		}
+		if h.User == "" {
+			h.User = defaultUser
+		}
+		if h.Labels == nil {
+			h.Labels = map[string]string{}
+		}
+		s.hosts[h.Name] = &h
+		s.hostsOrder = append(s.hostsOrder, h.Name)
+	}
+
+	if len(s.hosts) == 0 {
+		return nil, fmt.Errorf("no hosts given for ssh runner, set hosts and / or a ssh config file")
+	}
+
+	var err error
+	if s.clients, err = newClients(conf, s.hosts); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// GetHostsForTest return a list of hosts for the given test config from the configured hosts
+func (s *SSH) GetHostsForTest(test *config.Test) (*testers.Hosts, error) {
+	hosts := &testers.Hosts{
+		Clients: map[string]*testers.Host{},
+		Servers: map[string]*testers.Host{},
+	}
+
+	available := []*testers.Host{}
+	for _, name := range s.hostsOrder {
+		host, err := s.getTestersHost(s.hosts[name])
+		if err != nil {
+			return nil, err
+		}
+		available = append(available, host)
+	}
+
+	for _, servers := range test.Hosts.Servers {
+		seed, err := s.filterHosts(available, servers, hosts.Servers)
+		if err != nil {
+			return nil, err
+		}
+		hosts.AddSeed("servers", servers.Name, seed)
+	}
+	for _, clients := range test.Hosts.Clients {
+		seed, err := s.filterHosts(available, clients, hosts.Clients)
+		if err != nil {
+			return nil, err
+		}
+		hosts.AddSeed("clients", clients.Name, seed)
+	}
+
+	return hosts, nil
+}
+
+func (s *SSH) filterHosts(available []*testers.Host, filter config.Hosts, out map[string]*testers.Host) (*int64, error) {
+	filtered, seed, err := hostsfilter.FilterHostsList(available, filter)
+	if err != nil {
+		return nil, err
+	}
+	for _, host := range filtered {
+		if _, ok := out[host.Name]; ok {
+			continue
+		}
+		// Static hosts lists of a test only contain the names of the hosts
+		sshHost, ok := s.hosts[host.Name]
+		if !ok {
+			return nil, fmt.Errorf("host %s not found in ssh runner hosts list", host.Name)
+		}
+		if out[host.Name], err = s.getTestersHost(sshHost); err != nil {
+			return nil, err
+		}
+	}
+
+	return seed, nil
+}
+
+// getTestersHost return the testers.Host for a host, the addresses are resolved when none have been configured
+func (s *SSH) getTestersHost(host *config.SSHHost) (*testers.Host, error) {
+	addresses := &testers.IPAddresses{
+		IPv4: host.IPv4,
+		IPv6: host.IPv6,
+	}
+
+	if len(addresses.IPv4) == 0 && len(addresses.IPv6) == 0 {
+		ips := []net.IP{}
+		if ip := net.ParseIP(host.Address); ip != nil {
+			ips = append(ips, ip)
+		} else {
+			var err error
+			if ips, err = net.LookupIP(host.Address); err != nil {
+				return nil, fmt.Errorf("failed to resolve address of host %s. %+v", host.Name, err)
+			}
+		}
+		for _, ip := range ips {
+			if ip.To4() != nil {
+				addresses.IPv4 = append(addresses.IPv4, ip.String())
+			} else {
+				addresses.IPv6 = append(addresses.IPv6, ip.String())
+			}
+		}
+	}
+
+	return &testers.Host{
+		Name:      host.Name,
+		Labels:    host.Labels,
+		Addresses: addresses,
+	}, nil
+}
+
+// Prepare connect to the hosts of the plan so connection issues show up before the test is run
+func (s *SSH) Prepare(runOpts config.RunOptions, plan *testers.Plan) error {
+	s.runOptions = runOpts
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.config.Timeouts.ConnectTimeout)
+	defer cancel()
+
+	for _, tasks := range plan.Commands {
+		for _, task := range tasks {
+			if task.Sleep != 0 {
+				continue
+			}
+			for _, t := range append([]*testers.Task{task}, task.SubTasks...) {
+				host, ok := s.hosts[t.Host.Name]
+				if !ok {
+					return fmt.Errorf("host %s not found in ssh runner hosts list", t.Host.Name)
+				}
+				if _, err := s.clients.get(ctx, host); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// Execute run the given commands and return the logs of it and / or error
+func (s *SSH) Execute(plan *testers.Plan, parser chan<- parsers.Input) error {
+	return runners.ExecutePlan(s.logger, plan, s.runOptions, func(round int, index int, task *testers.Task) error {
+		return s.runTasks(round, index, task, plan.TestStartTime, plan.Tester, parser)
+	})
+}
+
+func (s *SSH) runTasks(round int, index int, mainTask *testers.Task, plannedTime time.Time, tester string, parser chan<- parsers.Input) error {
+	logger := s.logger.WithFields(logrus.Fields{"round": round, "hostname": mainTask.Host.Name})
+
+	// Create initial cmdtemplate.Variables
+	// Each server task of a round gets its own port, as server tasks on the same host can run in parallel
+	templateVars := cmdtemplate.Variables{
+		ServerPort: 5601 + int32(index),
+	}
+	if len(mainTask.Host.Addresses.IPv4) > 0 {
+		templateVars.ServerAddressV4 = mainTask.Host.Addresses.IPv4[0]
+	}
+	if len(mainTask.Host.Addresses.IPv6) > 0 {
+		templateVars.ServerAddressV6 = mainTask.Host.Addresses.IPv6[0]
+	}
+
+	if err := cmdtemplate.Template(mainTask, templateVars); err != nil {
+		erro := fmt.Errorf("failed to template main task command and / or args. %+v", err)
+		logger.Error(erro)
+		mainTask.Status.AddFailedServer(mainTask.Host, erro)
+		return erro
+	}
+
+	server, err := s.startServer(mainTask)
+	if err != nil {
+		logger.Error(err)
+		mainTask.Status.AddFailedServer(mainTask.Host, err)
+		return err
+	}
+
+	pool := runners.NewClientPool(s.runOptions)
+	for i, task := range mainTask.SubTasks {
+		logger.WithField("client", task.Host.Name).
+			Infof("running sub task %d of %d", i+1, len(mainTask.SubTasks))
+
+		task := task
+		pool.Go(func() {
+			// Template command and args for each task
+			if err := cmdtemplate.Template(task, templateVars); err != nil {
+				erro := fmt.Errorf("failed to template task command and / or args. %+v", err)
+				logger.WithFields(logrus.Fields{"client": task.Host.Name, "error": erro}).
+					Error("error during runTasks")
+				mainTask.Status.AddFailedClient(task.Host, erro)
+				return
+			}
+
+			if err := s.runClient(round, mainTask, task, plannedTime, tester, parser); err != nil {
+				logger.WithField("client", task.Host.Name).Error(err)
+				mainTask.Status.AddFailedClient(task.Host, err)
+				return
+			}
+			mainTask.Status.AddSuccessfulClient(task.Host)
+		})
+	}
+	pool.Wait()
+
+	logger.Info("stopping main task")
+	if err := server.stop(); err != nil {
+		logger.Error(err)
+		mainTask.Status.AddFailedServer(mainTask.Host, err)
+		return err
+	}
+	mainTask.Status.AddSuccessfulServer(mainTask.Host)
+
+	logger.Debug("done running tasks for test through ssh for plan")
+
+	return nil
+}
+
+// runClient run the client task and stream its output to the parser. When the command fails, the DataStream is closed
+// with parsers.ErrTaskFailed, so the parser skips the output instead of stopping.
+func (s *SSH) runClient(round int, mainTask *testers.Task, task *testers.Task, plannedTime time.Time, tester string, parser chan<- parsers.Input) error {
+	ctx, cancel := context.WithTimeout(context.Background(), s.config.Timeouts.TaskCommandTimeout)
+	defer cancel()
+
+	conn, err := s.clients.get(ctx, s.hosts[task.Host.Name])
+	if err != nil {
+		return err
+	}
+	session, err := conn.NewSession()
+	if err != nil {
+		return fmt.Errorf("failed to create ssh session. %+v", err)
+	}
+	defer session.Close()
+
+	reader, writer := io.Pipe()
+	var stderr strings.Builder
+	session.Stdout = writer
+	session.Stderr = &stderr
+
+	testTime := time.Now()
+	if err := session.Start(util.ShellQuote(append([]string{task.Command}, task.Args...)...)); err != nil {
+		writer.Close()
+		return fmt.Errorf("failed to start sub task command. %+v", err)
+	}
+
+	// The parser closes the DataStream after reading it
+	r := io.ReadCloser(reader)
+	select {
+	case parser <- parsers.Input{
+		TestStartTime:  plannedTime,
+		TestTime:       testTime,
+		Round:          round,
+		DataStream:     &r,
+		Tester:         tester,
+		ServerHost:     mainTask.Host.Name,
+		ClientHost:     task.Host.Name,
+		IPFamily:       string(task.IPFamily),
+		AdditionalInfo: fmt.Sprintf("ssh=%s@%s", s.hosts[task.Host.Name].User, s.hosts[task.Host.Name].Address),
+	}:
+	case <-ctx.Done():
+		erro := fmt.Errorf("timed out sending sub task output to parser. %+v", ctx.Err())
+		writer.CloseWithError(erro)
+		return erro
+	}
+
+	if err := waitSession(ctx, session); err != nil {
+		erro := fmt.Errorf("sub task command failed. %+v (stderr: %s)", err, strings.TrimSpace(stderr.String()))
+		writer.CloseWithError(fmt.Errorf("%w. %+v", parsers.ErrTaskFailed, erro))
+		return erro
+	}
+
+	return writer.Close()
+}
+
+// server running server (main) task
+type server struct {
+	runner  *SSH
+	task    *testers.Task
+	host    *config.SSHHost
+	pid     int
+	session io.Closer
+	doneCh  chan error
+}
+
+// startServer start the server (main) task in the background, the shell prints its PID before it is replaced by the
+// server command so the server can be stopped reliably
+func (s *SSH) startServer(mainTask *testers.Task) (*server, error) {
+	host, ok := s.hosts[mainTask.Host.Name]
+	if !ok {
+		return nil, fmt.Errorf("host %s not found in ssh runner hosts list", mainTask.Host.Name)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.config.Timeouts.TaskCommandTimeout)
+	defer cancel()
+
+	conn, err := s.clients.get(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+	session, err := conn.NewSession()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create ssh session. %+v", err)
+	}
+	stdout, err := session.StdoutPipe()
+	if err != nil {
+		session.Close()
+		return nil, err
+	}
+
+	command := fmt.Sprintf("echo $$; exec %s", util.ShellQuote(append([]string{mainTask.Command}, mainTask.Args...)...))
+	if err := session.Start(command); err != nil {
+		session.Close()
+		return nil, fmt.Errorf("failed to start main task command. %+v", err)
+	}
+
+	srv := &server{
+		runner:  s,
+		task:    mainTask,
+		host:    host,
+		session: session,
+		doneCh:  make(chan error, 1),
+	}
+
+	pidCh := make(chan string, 1)
+	go func() {
+		reader := bufio.NewReader(stdout)
+		line, _ := reader.ReadString('\n')
+		pidCh <- line
+		// Discard the server output, so the server doesn't block on a full SSH channel window
+		io.Copy(ioutil.Discard, reader)
+		srv.doneCh <- session.Wait()
+	}()
+
+	select {
+	case line := <-pidCh:
+		if srv.pid, err = strconv.Atoi(strings.TrimSpace(line)); err != nil {
+			session.Close()
+			return nil, fmt.Errorf("failed to get PID of main task. %+v", err)
+		}
+	case <-ctx.Done():
+		session.Close()
+		return nil, fmt.Errorf("timed out waiting for main task to start. %+v", ctx.Err())
+	}
+
+	s.serversLock.Lock()
+	s.servers[mainTask] = srv
+	s.serversLock.Unlock()
+
+	// Give the server a moment to start listening, then make sure it is still running
+	time.Sleep(250 * time.Millisecond)
+	select {
+	case err := <-srv.doneCh:
+		session.Close()
+		s.forgetServer(mainTask)
+		return nil, fmt.Errorf("main task exited right after start. %+v", err)
+	default:
+	}
+
+	return srv, nil
+}
+
+// stop stop the server by sending it the TERM signal and, when it hasn't exited in time, the KILL signal
+func (srv *server) stop() error {
+	defer srv.session.Close()
+	defer srv.runner.forgetServer(srv.task)
+
+	if err := srv.runner.kill(srv.host, srv.pid, "TERM"); err != nil {
+		srv.runner.logger.WithField("hostname", srv.host.Name).Warn(err)
+	}
+
+	select {
+	case <-srv.doneCh:
+		return nil
+	case <-time.After(srv.runner.config.Timeouts.StopTimeout):
+	}
+
+	if err := srv.runner.kill(srv.host, srv.pid, "KILL"); err != nil {
+		return fmt.Errorf("failed to kill main task. %+v", err)
+	}
+
+	return nil
+}
+
+func (s *SSH) kill(host *config.SSHHost, pid int, signal string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), s.config.Timeouts.TaskCommandTimeout)
+	defer cancel()
+
+	_, err := s.clients.run(ctx, host, fmt.Sprintf("kill -%s %d", signal, pid))
+	return err
+}
+
+func (s *SSH) forgetServer(task *testers.Task) {
+	s.serversLock.Lock()
+	defer s.serversLock.Unlock()
+	delete(s.servers, task)
+}
+
+// Cleanup kill left behind server (main) tasks and close the SSH connections
+func (s *SSH) Cleanup(plan *testers.Plan) error {
+	s.serversLock.Lock()
+	servers := []*server{}
+	for _, srv := range s.servers {
+		servers = append(servers, srv)
+	}
+	s.serversLock.Unlock()
+
+	for _, srv := range servers {
+		s.logger.WithFields(logrus.Fields{"hostname": srv.host.Name, "pid": srv.pid}).Info("killing left behind main task")
+		if err := s.kill(srv.host, srv.pid, "KILL"); err != nil {
+			s.logger.WithField("hostname", srv.host.Name).Warn(err)
+		}
+		s.forgetServer(srv.task)
+	}
+
+	return s.clients.close()
+}
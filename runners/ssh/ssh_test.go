@@ -0,0 +1,517 @@
+/*
+Copyright 2022 Cloudical Deutschland GmbH. All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ssh
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/binary"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"os/exec"
+	"os/user"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/cloudical-io/ancientt/parsers"
+	"github.com/cloudical-io/ancientt/pkg/config"
+	"github.com/cloudical-io/ancientt/pkg/util"
+	"github.com/cloudical-io/ancientt/testers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	gossh "golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+// testServer in-process SSH server which runs the exec requests through `sh -c`
+type testServer struct {
+	listener net.Listener
+	hostKey  gossh.Signer
+	config   *gossh.ServerConfig
+	wg       sync.WaitGroup
+}
+
+func newTestServer(t *testing.T, clientKey gossh.PublicKey) *testServer {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.Nil(t, err)
+	hostKey, err := gossh.NewSignerFromKey(key)
+	require.Nil(t, err)
+
+	srv := &testServer{
+		hostKey: hostKey,
+		config: &gossh.ServerConfig{
+			PublicKeyCallback: func(conn gossh.ConnMetadata, key gossh.PublicKey) (*gossh.Permissions, error) {
+				if bytes.Equal(key.Marshal(), clientKey.Marshal()) {
+					return nil, nil
+				}
+				return nil, assert.AnError
+			},
+		},
+	}
+	srv.config.AddHostKey(hostKey)
+
+	srv.listener, err = net.Listen("tcp", "127.0.0.1:0")
+	require.Nil(t, err)
+
+	go func() {
+		for {
+			conn, err := srv.listener.Accept()
+			if err != nil {
+				return
+			}
+			go srv.handleConn(conn)
+		}
+	}()
+
+	return srv
+}
+
+func (srv *testServer) port() int {
+	return srv.listener.Addr().(*net.TCPAddr).Port
+}
+
+func (srv *testServer) close() {
+	srv.listener.Close()
+	srv.wg.Wait()
+}
+
+func (srv *testServer) handleConn(conn net.Conn) {
+	_, chans, reqs, err := gossh.NewServerConn(conn, srv.config)
+	if err != nil {
+		return
+	}
+	go gossh.DiscardRequests(reqs)
+
+	for newChannel := range chans {
+		if newChannel.ChannelType() != "session" {
+			newChannel.Reject(gossh.UnknownChannelType, "unknown channel type")
+			continue
+		}
+		channel, requests, err := newChannel.Accept()
+		if err != nil {
+			continue
+		}
+		srv.wg.Add(1)
+		go srv.handleSession(channel, requests)
+	}
+}
+
+func (srv *testServer) handleSession(channel gossh.Channel, requests <-chan *gossh.Request) {
+	defer srv.wg.Done()
+	defer channel.Close()
+
+	for req := range requests {
+		if req.Type != "exec" {
+			req.Reply(false, nil)
+			continue
+		}
+		length := binary.BigEndian.Uint32(req.Payload)
+		command := string(req.Payload[4 : 4+length])
+		req.Reply(true, nil)
+
+		cmd := exec.Command("sh", "-c", command)
+		cmd.Stdout = channel
+		cmd.Stderr = channel.Stderr()
+		status := uint32(0)
+		if err := cmd.Run(); err != nil {
+			status = 1
+			if exitErr, ok := err.(*exec.ExitError); ok && exitErr.ExitCode() > 0 {
+				status = uint32(exitErr.ExitCode())
+			}
+		}
+		channel.SendRequest("exit-status", false, gossh.Marshal(struct{ Status uint32 }{status}))
+		return
+	}
+}
+
+// writeTestKey write a new private key file and return its path and signer
+func writeTestKey(t *testing.T, dir string, name string) (string, gossh.Signer) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.Nil(t, err)
+	keyBytes, err := x509.MarshalECPrivateKey(key)
+	require.Nil(t, err)
+	keyFile := filepath.Join(dir, name)
+	require.Nil(t, ioutil.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyBytes}), 0600))
+	signer, err := gossh.NewSignerFromKey(key)
+	require.Nil(t, err)
+
+	return keyFile, signer
+}
+
+func newTestRunner(t *testing.T) (*SSH, *testServer) {
+	dir := t.TempDir()
+
+	keyFile, signer := writeTestKey(t, dir, "id_ecdsa")
+
+	srv := newTestServer(t, signer.PublicKey())
+
+	knownHostsFile := filepath.Join(dir, "known_hosts")
+	line := knownhosts.Line([]string{knownhosts.Normalize(net.JoinHostPort("127.0.0.1", strconv.Itoa(srv.port())))}, srv.hostKey.PublicKey())
+	require.Nil(t, ioutil.WriteFile(knownHostsFile, []byte(line+"\n"), 0600))
+
+	cfg := config.New()
+	cfg.Runner.SSH = &config.RunnerSSH{
+		Hosts: []*config.SSHHost{
+			{Name: "server1", Address: "127.0.0.1", Labels: map[string]string{"role": "server"}},
+			{Name: "client1", Address: "127.0.0.1"},
+		},
+		User:                  "ancientt",
+		PrivateKeyFiles:       []string{keyFile},
+		UseAgent:              util.BoolFalsePointer(),
+		KnownHostsFile:        knownHostsFile,
+		InsecureIgnoreHostKey: util.BoolFalsePointer(),
+	}
+	cfg.Runner.SSH.SetDefaults()
+	cfg.Runner.SSH.Port = srv.port()
+
+	runner, err := NewRunner(cfg)
+	require.Nil(t, err)
+
+	return runner.(*SSH), srv
+}
+
+func TestGetHostsForTest(t *testing.T) {
+	s, srv := newTestRunner(t)
+	defer srv.close()
+
+	hosts, err := s.GetHostsForTest(&config.Test{
+		Hosts: config.TestHosts{
+			Servers: []config.Hosts{{Name: "servers", HostSelector: map[string]string{"role": "server"}}},
+			Clients: []config.Hosts{{Name: "clients", Hosts: []string{"client1"}}},
+		},
+	})
+	require.Nil(t, err)
+	require.Equal(t, 1, len(hosts.Servers))
+	require.Equal(t, 1, len(hosts.Clients))
+	assert.Equal(t, []string{"127.0.0.1"}, hosts.Servers["server1"].Addresses.IPv4)
+	assert.Equal(t, []string{"127.0.0.1"}, hosts.Clients["client1"].Addresses.IPv4)
+
+	_, err = s.GetHostsForTest(&config.Test{
+		Hosts: config.TestHosts{
+			Servers: []config.Hosts{{Name: "servers", Hosts: []string{"unknown"}}},
+		},
+	})
+	assert.NotNil(t, err)
+}
+
+func newTestStatus() *testers.Status {
+	return &testers.Status{
+		SuccessfulHosts: testers.StatusHosts{Servers: map[string]int{}, Clients: map[string]int{}},
+		FailedHosts:     testers.StatusHosts{Servers: map[string]int{}, Clients: map[string]int{}},
+		Errors:          map[string][]error{},
+	}
+}
+
+func TestExecute(t *testing.T) {
+	s, srv := newTestRunner(t)
+	defer srv.close()
+
+	hosts, err := s.GetHostsForTest(&config.Test{
+		Hosts: config.TestHosts{
+			Servers: []config.Hosts{{Name: "servers", Hosts: []string{"server1"}}},
+			Clients: []config.Hosts{{Name: "clients", Hosts: []string{"client1"}}},
+		},
+	})
+	require.Nil(t, err)
+
+	pidFile := filepath.Join(t.TempDir(), "server.pid")
+	task := &testers.Task{
+		Host:    hosts.Servers["server1"],
+		Command: "sh",
+		Args:    []string{"-c", "echo $$ > " + pidFile + "; exec sleep 60"},
+		SubTasks: []*testers.Task{
+			{
+				Host:    hosts.Clients["client1"],
+				Command: "echo",
+				Args:    []string{"connect to {{ .ServerAddressV4 }}"},
+			},
+		},
+		Status: newTestStatus(),
+	}
+	runOpts := config.RunOptions{ContinueOnError: util.BoolFalsePointer()}
+	plan := &testers.Plan{
+		TestStartTime: time.Now(),
+		Tester:        "test",
+		Commands:      [][]*testers.Task{{task}},
+		RunOptions:    runOpts,
+	}
+	require.Nil(t, s.Prepare(runOpts, plan))
+
+	inCh := make(chan parsers.Input)
+	outCh := make(chan string, 1)
+	go func() {
+		for input := range inCh {
+			out, err := io.ReadAll(*input.DataStream)
+			require.Nil(t, err)
+			require.Nil(t, (*input.DataStream).Close())
+			assert.Equal(t, "server1", input.ServerHost)
+			assert.Equal(t, "client1", input.ClientHost)
+			outCh <- string(out)
+		}
+	}()
+
+	require.Nil(t, s.Execute(plan, inCh))
+	close(inCh)
+
+	assert.Equal(t, "connect to 127.0.0.1\n", <-outCh)
+	assert.Equal(t, 1, task.Status.SuccessfulHosts.Servers["server1"])
+	assert.Equal(t, 1, task.Status.SuccessfulHosts.Clients["client1"])
+	assert.Equal(t, 0, len(task.Status.Errors))
+	assert.Equal(t, 0, len(s.servers))
+
+	// The server must have been stopped
+	pid, err := ioutil.ReadFile(pidFile)
+	require.Nil(t, err)
+	assert.NotNil(t, exec.Command("kill", "-0", strings.TrimSpace(string(pid))).Run())
+
+	require.Nil(t, s.Cleanup(plan))
+}
+
+func TestExecuteFailingClient(t *testing.T) {
+	s, srv := newTestRunner(t)
+	defer srv.close()
+
+	hosts, err := s.GetHostsForTest(&config.Test{
+		Hosts: config.TestHosts{
+			Servers: []config.Hosts{{Name: "servers", Hosts: []string{"server1"}}},
+			Clients: []config.Hosts{{Name: "clients", Hosts: []string{"client1"}}},
+		},
+	})
+	require.Nil(t, err)
+
+	task := &testers.Task{
+		Host:    hosts.Servers["server1"],
+		Command: "sleep",
+		Args:    []string{"60"},
+		SubTasks: []*testers.Task{
+			{Host: hosts.Clients["client1"], Command: "sh", Args: []string{"-c", "echo not json; exit 1"}},
+		},
+		Status: newTestStatus(),
+	}
+	runOpts := config.RunOptions{ContinueOnError: util.BoolTruePointer()}
+	plan := &testers.Plan{
+		Commands:   [][]*testers.Task{{task}},
+		RunOptions: runOpts,
+	}
+	require.Nil(t, s.Prepare(runOpts, plan))
+
+	inCh := make(chan parsers.Input)
+	errCh := make(chan error, 1)
+	go func() {
+		for input := range inCh {
+			_, err := io.Copy(ioutil.Discard, *input.DataStream)
+			errCh <- err
+		}
+	}()
+	require.Nil(t, s.Execute(plan, inCh))
+	close(inCh)
+
+	// The output of the failed client is streamed to the parser, which is told to skip it
+	assert.True(t, errors.Is(<-errCh, parsers.ErrTaskFailed))
+	assert.Equal(t, 1, task.Status.SuccessfulHosts.Servers["server1"])
+	assert.Equal(t, 1, task.Status.FailedHosts.Clients["client1"])
+	require.Nil(t, s.Cleanup(plan))
+}
+
+func TestExecuteParallelServers(t *testing.T) {
+	s, srv := newTestRunner(t)
+	defer srv.close()
+
+	hosts, err := s.GetHostsForTest(&config.Test{
+		Hosts: config.TestHosts{
+			Servers: []config.Hosts{{Name: "servers", Hosts: []string{"server1"}}},
+			Clients: []config.Hosts{{Name: "clients", Hosts: []string{"client1"}}},
+		},
+	})
+	require.Nil(t, err)
+
+	// Two server tasks on the same host run at the same time
+	tasks := []*testers.Task{}
+	for i := 0; i < 2; i++ {
+		tasks = append(tasks, &testers.Task{
+			Host:    hosts.Servers["server1"],
+			Command: "sleep",
+			Args:    []string{"60"},
+			SubTasks: []*testers.Task{
+				{Host: hosts.Clients["client1"], Command: "echo", Args: []string{"{{ .ServerPort }}"}},
+			},
+			Status: newTestStatus(),
+		})
+	}
+	runOpts := config.RunOptions{
+		ContinueOnError: util.BoolFalsePointer(),
+		Mode:            config.RunModeParallel,
+		ParallelServers: 2,
+	}
+	plan := &testers.Plan{
+		Commands:   [][]*testers.Task{tasks},
+		RunOptions: runOpts,
+	}
+	require.Nil(t, s.Prepare(runOpts, plan))
+
+	inCh := make(chan parsers.Input)
+	outCh := make(chan string, 2)
+	go func() {
+		for input := range inCh {
+			out, err := io.ReadAll(*input.DataStream)
+			require.Nil(t, err)
+			outCh <- strings.TrimSpace(string(out))
+		}
+	}()
+	require.Nil(t, s.Execute(plan, inCh))
+	close(inCh)
+
+	// Each server task has its own port and both servers have been stopped
+	assert.ElementsMatch(t, []string{"5601", "5602"}, []string{<-outCh, <-outCh})
+	for _, task := range tasks {
+		assert.Equal(t, 1, task.Status.SuccessfulHosts.Servers["server1"])
+	}
+	assert.Equal(t, 0, len(s.servers))
+	require.Nil(t, s.Cleanup(plan))
+}
+
+func TestNewClientsHostPrivateKeys(t *testing.T) {
+	dir := t.TempDir()
+	keyFile, _ := writeTestKey(t, dir, "id_ecdsa")
+	hostKeyFile, _ := writeTestKey(t, dir, "host_key")
+
+	conf := &config.RunnerSSH{
+		PrivateKeyFiles:       []string{keyFile},
+		UseAgent:              util.BoolFalsePointer(),
+		InsecureIgnoreHostKey: util.BoolTruePointer(),
+	}
+	conf.SetDefaults()
+
+	c, err := newClients(conf, map[string]*config.SSHHost{
+		"server1": {Name: "server1", PrivateKeyFiles: []string{hostKeyFile}},
+		"client1": {Name: "client1"},
+	})
+	require.Nil(t, err)
+	assert.Equal(t, 1, len(c.authMethods))
+	// Only the host with own private key files doesn't use the private keys of the runner
+	assert.Equal(t, 1, len(c.hostAuthMethods["server1"]))
+	_, ok := c.hostAuthMethods["client1"]
+	assert.False(t, ok)
+
+	_, err = newClients(conf, map[string]*config.SSHHost{
+		"server1": {Name: "server1", PrivateKeyFiles: []string{filepath.Join(dir, "missing")}},
+	})
+	assert.NotNil(t, err)
+}
+
+func TestClientsAgent(t *testing.T) {
+	dir := t.TempDir()
+	keyFile, signer := writeTestKey(t, dir, "id_ecdsa")
+	key, err := ioutil.ReadFile(keyFile)
+	require.Nil(t, err)
+	privateKey, err := gossh.ParseRawPrivateKey(key)
+	require.Nil(t, err)
+
+	keyring := agent.NewKeyring()
+	require.Nil(t, keyring.Add(agent.AddedKey{PrivateKey: privateKey}))
+	sock := filepath.Join(dir, "agent.sock")
+	listener, err := net.Listen("unix", sock)
+	require.Nil(t, err)
+	defer listener.Close()
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go agent.ServeAgent(keyring, conn)
+		}
+	}()
+	t.Setenv("SSH_AUTH_SOCK", sock)
+
+	conf := &config.RunnerSSH{
+		PrivateKeyFiles:       []string{keyFile},
+		UseAgent:              util.BoolTruePointer(),
+		InsecureIgnoreHostKey: util.BoolTruePointer(),
+	}
+	conf.SetDefaults()
+	c, err := newClients(conf, map[string]*config.SSHHost{})
+	require.Nil(t, err)
+	assert.Equal(t, 2, len(c.authMethods))
+
+	signers, err := c.agentSigners()
+	require.Nil(t, err)
+	require.Equal(t, 1, len(signers))
+	assert.Equal(t, signer.PublicKey().Marshal(), signers[0].PublicKey().Marshal())
+
+	// The agent connection is closed with the client connections and connected to again when needed
+	require.Nil(t, c.close())
+	assert.Nil(t, c.agentConn)
+	_, err = c.agentSigners()
+	assert.Nil(t, err)
+	require.Nil(t, c.close())
+}
+
+func TestExpandSSHConfigTokens(t *testing.T) {
+	host := &config.SSHHost{Name: "server1", Address: "10.0.0.1", Port: 2222, User: "test"}
+
+	out, err := expandSSHConfigTokens("%n-%h-%p-%r", host)
+	require.Nil(t, err)
+	assert.Equal(t, "server1-10.0.0.1-2222-test", out)
+
+	_, err = expandSSHConfigTokens("key_%x", host)
+	assert.NotNil(t, err)
+	_, err = expandSSHConfigTokens("key_%", host)
+	assert.NotNil(t, err)
+}
+
+func TestParseSSHConfig(t *testing.T) {
+	hosts, err := parseSSHConfig(strings.NewReader(`
+Host server1 server2
+  HostName 10.0.0.1
+  Port 2222
+
+Host client1
+  User test
+  IdentityFile ~/.ssh/test_key
+  IdentityFile %d/.ssh/%r@%h_%u_%%
+
+Host *
+  User default
+`))
+	require.Nil(t, err)
+	require.Equal(t, 3, len(hosts))
+	assert.Equal(t, "server1", hosts[0].Name)
+	assert.Equal(t, "10.0.0.1", hosts[0].Address)
+	assert.Equal(t, 2222, hosts[0].Port)
+	assert.Equal(t, "default", hosts[0].User)
+	assert.Equal(t, "server2", hosts[1].Name)
+	assert.Equal(t, "client1", hosts[2].Name)
+	assert.Equal(t, "test", hosts[2].User)
+	current, err := user.Current()
+	require.Nil(t, err)
+	assert.Equal(t, []string{
+		"~/.ssh/test_key",
+		fmt.Sprintf("%s/.ssh/test@client1_%s_%%", current.HomeDir, current.Username),
+	}, hosts[2].PrivateKeyFiles)
+	// Hosts without an IdentityFile keep the private key files of the runner
+	assert.Equal(t, 0, len(hosts[0].PrivateKeyFiles))
+}
@@ -0,0 +1,159 @@
+/*
+Copyright 2022 Cloudical Deutschland GmbH. All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ssh
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/user"
+	"strconv"
+	"strings"
+
+	"github.com/cloudical-io/ancientt/pkg/config"
+	"github.com/kevinburke/ssh_config"
+	homedir "github.com/mitchellh/go-homedir"
+)
+
+// loadSSHConfig load the hosts from an ssh_config file, returns the hosts and the identity files of them
+func loadSSHConfig(file string) ([]*config.SSHHost, error) {
+	file, err := homedir.Expand(file)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open ssh config file. %+v", err)
+	}
+	defer f.Close()
+
+	return parseSSHConfig(f)
+}
+
+func parseSSHConfig(r io.Reader) ([]*config.SSHHost, error) {
+	cfg, err := ssh_config.Decode(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse ssh config file. %+v", err)
+	}
+
+	hosts := []*config.SSHHost{}
+	seen := map[string]bool{}
+	for _, entry := range cfg.Hosts {
+		for _, pattern := range entry.Patterns {
+			alias := pattern.String()
+			// Only "plain" host entries can be used as hosts
+			if seen[alias] || strings.ContainsAny(alias, "*?!") {
+				continue
+			}
+			seen[alias] = true
+
+			host := &config.SSHHost{
+				Name:   alias,
+				Labels: map[string]string{},
+			}
+			if host.Address, err = cfg.Get(alias, "HostName"); err != nil {
+				return nil, err
+			}
+			if host.User, err = cfg.Get(alias, "User"); err != nil {
+				return nil, err
+			}
+			port, err := cfg.Get(alias, "Port")
+			if err != nil {
+				return nil, err
+			}
+			if port != "" {
+				if host.Port, err = strconv.Atoi(port); err != nil {
+					return nil, fmt.Errorf("invalid port %s for host %s in ssh config. %+v", port, alias, err)
+				}
+			}
+			// Hosts without an IdentityFile use the private key files of the runner
+			files, err := cfg.GetAll(alias, "IdentityFile")
+			if err != nil {
+				return nil, err
+			}
+			for _, file := range files {
+				expanded, err := expandSSHConfigTokens(file, host)
+				if err != nil {
+					return nil, fmt.Errorf("invalid identity file %s for host %s in ssh config. %+v", file, alias, err)
+				}
+				host.PrivateKeyFiles = append(host.PrivateKeyFiles, expanded)
+			}
+
+			hosts = append(hosts, host)
+		}
+	}
+
+	return hosts, nil
+}
+
+// expandSSHConfigTokens expand the tokens (e.g., `%d`, `%h`, `%u`) in the value of a ssh config option for the host, see
+// the TOKENS section of ssh_config(5)
+func expandSSHConfigTokens(value string, host *config.SSHHost) (string, error) {
+	if !strings.Contains(value, "%") {
+		return value, nil
+	}
+
+	current, err := user.Current()
+	if err != nil {
+		return "", fmt.Errorf("failed to get current user. %+v", err)
+	}
+	localHostname, err := os.Hostname()
+	if err != nil {
+		return "", fmt.Errorf("failed to get local hostname. %+v", err)
+	}
+
+	hostname := host.Address
+	if hostname == "" {
+		hostname = host.Name
+	}
+	remoteUser := host.User
+	if remoteUser == "" {
+		remoteUser = current.Username
+	}
+	port := "22"
+	if host.Port != 0 {
+		port = strconv.Itoa(host.Port)
+	}
+
+	tokens := map[byte]string{
+		'%': "%",
+		'd': current.HomeDir,
+		'h': hostname,
+		'i': current.Uid,
+		'l': localHostname,
+		'n': host.Name,
+		'p': port,
+		'r': remoteUser,
+		'u': current.Username,
+	}
+
+	var out strings.Builder
+	for i := 0; i < len(value); i++ {
+		if value[i] != '%' {
+			out.WriteByte(value[i])
+			continue
+		}
+		if i+1 >= len(value) {
+			return "", fmt.Errorf("unterminated token at the end of %q", value)
+		}
+		i++
+		token, ok := tokens[value[i]]
+		if !ok {
+			return "", fmt.Errorf("unknown token %%%c in %q", value[i], value)
+		}
+		out.WriteString(token)
+	}
+
+	return out.String(), nil
+}
@@ -69,8 +69,14 @@ func (t IPerf3) Plan(env *testers.Environment, test *config.Test) (*testers.Plan
 		}
 	}
 
+	pairs, err := testers.BuildPairs(env.Hosts, test.Pairing)
+	if err != nil {
+		return nil, err
+	}
+
 	for i := 0; i < test.RunOptions.Rounds; i++ {
-		for _, server := range env.Hosts.Servers {
+		for j, pair := range pairs {
+			server := pair.Server
 			round := &testers.Task{
 				Status: &testers.Status{
 					SuccessfulHosts: testers.StatusHosts{
@@ -95,25 +101,29 @@ func (t IPerf3) Plan(env *testers.Environment, test *config.Test) (*testers.Plan
 			round.Ports = ports
 
 			// Now go over each client and generate their Task
-			for _, client := range env.Hosts.Clients {
+			for _, client := range pair.Clients {
 				// Add client host to AffectedServers list
 				if _, ok := plan.AffectedServers[client.Name]; !ok {
 					plan.AffectedServers[client.Name] = client
 				}
 
-				// Build the IPerf3 command
-				cmd, args := t.buildIPerf3ClientCommand(server, client)
-				round.SubTasks = append(round.SubTasks, &testers.Task{
-					Host:    client,
-					Command: cmd,
-					Args:    args,
-					Ports:   ports,
-				})
+				// Build the IPerf3 command for each IP family
+				for _, family := range testers.IPFamilies(test.IPFamily) {
+					cmd, args := t.buildIPerf3ClientCommand(server, client, family)
+					round.SubTasks = append(round.SubTasks, &testers.Task{
+						Host:     client,
+						Command:  cmd,
+						Args:     args,
+						Ports:    ports,
+						IPFamily: family,
+					})
+				}
 			}
 			plan.Commands[i] = append(plan.Commands[i], round)
 
-			// Add the given interval after each round except the last one
-			if test.RunOptions.Interval != 0 && i != test.RunOptions.Rounds-1 {
+			// Add the given interval after each server task (batch of server tasks with `parallelServers`) except in
+			// the last round
+			if testers.IntervalAfterServerTask(test.RunOptions, i, j, len(pairs)) {
 				plan.Commands[i] = append(plan.Commands[i], &testers.Task{
 					Sleep: test.RunOptions.Interval,
 				})
@@ -146,7 +156,7 @@ func (t IPerf3) buildIPerf3ServerCommand(server *testers.Host) (string, []string
 }
 
 // buildIPerf3ClientCommand generate IPer3 client command
-func (t IPerf3) buildIPerf3ClientCommand(server *testers.Host, client *testers.Host) (string, []string) {
+func (t IPerf3) buildIPerf3ClientCommand(server *testers.Host, client *testers.Host, family config.IPFamily) (string, []string) {
 	// Base command and args
 	cmd := "iperf3"
 	args := []string{
@@ -154,7 +164,12 @@ func (t IPerf3) buildIPerf3ClientCommand(server *testers.Host, client *testers.H
 		fmt.Sprintf("--interval=%d", *t.config.Interval),
 		"--json",
 		"--port={{ .ServerPort }}",
-		"--client={{ .ServerAddressV4 }}",
+		"--client=" + testers.ServerAddressTemplate(family),
+	}
+
+	// Only use IPv6, e.g., for server DNS names with IPv4 and IPv6 addresses
+	if family == config.IPFamilyV6 {
+		args = append(args, "--version6")
 	}
 
 	// Add --udp flag when UDP should be used
@@ -15,6 +15,7 @@ package iperf3
 
 import (
 	"testing"
+	"time"
 
 	"github.com/cloudical-io/ancientt/pkg/config"
 	"github.com/cloudical-io/ancientt/testers"
@@ -44,3 +45,146 @@ func TestIPerf3Plan(t *testing.T) {
 	assert.Equal(t, 0, len(plan.AffectedServers))
 	assert.Equal(t, 0, len(plan.Commands))
 }
+
+func TestIPerf3PlanMeshPairing(t *testing.T) {
+	test := &config.Test{
+		Type: "iperf3",
+		RunOptions: config.RunOptions{
+			Rounds:   2,
+			Interval: 10 * time.Second,
+		},
+		Pairing: config.Pairing{
+			Strategy: config.PairingStrategyMesh,
+		},
+		IPerf3: &config.IPerf3{},
+	}
+	test.IPerf3.SetDefaults()
+
+	tester, err := NewIPerf3Tester(nil, test)
+	require.Nil(t, err)
+
+	env := &testers.Environment{
+		Hosts: &testers.Hosts{
+			Servers: map[string]*testers.Host{
+				"host1": {Name: "host1"},
+			},
+			Clients: map[string]*testers.Host{
+				"host2": {Name: "host2"},
+				"host3": {Name: "host3"},
+			},
+		},
+	}
+
+	plan, err := tester.Plan(env, test)
+	require.Nil(t, err)
+	assert.Equal(t, 3, len(plan.AffectedServers))
+	require.Equal(t, 2, len(plan.Commands))
+	// Each host is the server once per round, followed by the interval except for the last round
+	require.Equal(t, 6, len(plan.Commands[0]))
+	assert.Equal(t, 3, len(plan.Commands[1]))
+
+	for i, server := range []string{"host1", "host2", "host3"} {
+		task := plan.Commands[1][i]
+		assert.Equal(t, server, task.Host.Name)
+		require.Equal(t, 2, len(task.SubTasks))
+		for _, subTask := range task.SubTasks {
+			assert.NotEqual(t, server, subTask.Host.Name)
+		}
+	}
+}
+
+func TestIPerf3PlanParallelServers(t *testing.T) {
+	test := &config.Test{
+		Type: "iperf3",
+		RunOptions: config.RunOptions{
+			Rounds:          2,
+			Interval:        10 * time.Second,
+			Mode:            config.RunModeParallel,
+			ParallelServers: 2,
+		},
+		Pairing: config.Pairing{
+			Strategy: config.PairingStrategyMesh,
+		},
+		IPerf3: &config.IPerf3{},
+	}
+	test.IPerf3.SetDefaults()
+
+	tester, err := NewIPerf3Tester(nil, test)
+	require.Nil(t, err)
+
+	env := &testers.Environment{
+		Hosts: &testers.Hosts{
+			Servers: map[string]*testers.Host{
+				"host1": {Name: "host1"},
+				"host2": {Name: "host2"},
+				"host3": {Name: "host3"},
+			},
+			Clients: map[string]*testers.Host{},
+		},
+	}
+
+	plan, err := tester.Plan(env, test)
+	require.Nil(t, err)
+	require.Equal(t, 2, len(plan.Commands))
+	// The interval follows each batch of two server tasks, the last batch has only one server task
+	require.Equal(t, 5, len(plan.Commands[0]))
+	for i, task := range plan.Commands[0] {
+		assert.Equal(t, i == 2 || i == 4, task.Sleep != 0, "task %d", i)
+	}
+	assert.Equal(t, 3, len(plan.Commands[1]))
+}
+
+func TestIPerf3PlanIPFamily(t *testing.T) {
+	env := &testers.Environment{
+		Hosts: &testers.Hosts{
+			Servers: map[string]*testers.Host{
+				"host1": {Name: "host1"},
+			},
+			Clients: map[string]*testers.Host{
+				"host2": {Name: "host2"},
+			},
+		},
+	}
+
+	tests := []struct {
+		family   config.IPFamily
+		families []config.IPFamily
+	}{
+		{"", []config.IPFamily{config.IPFamilyV4}},
+		{config.IPFamilyV6, []config.IPFamily{config.IPFamilyV6}},
+		{config.IPFamilyBoth, []config.IPFamily{config.IPFamilyV4, config.IPFamilyV6}},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.family), func(t *testing.T) {
+			test := &config.Test{
+				Type:       "iperf3",
+				RunOptions: config.RunOptions{Rounds: 1},
+				IPFamily:   tt.family,
+				IPerf3:     &config.IPerf3{},
+			}
+			test.IPerf3.SetDefaults()
+
+			tester, err := NewIPerf3Tester(nil, test)
+			require.Nil(t, err)
+
+			plan, err := tester.Plan(env, test)
+			require.Nil(t, err)
+			require.Equal(t, 1, len(plan.Commands[0]))
+
+			// The client runs the test once per IP family against the address of the family
+			subTasks := plan.Commands[0][0].SubTasks
+			require.Equal(t, len(tt.families), len(subTasks))
+			for i, family := range tt.families {
+				assert.Equal(t, "host2", subTasks[i].Host.Name)
+				assert.Equal(t, family, subTasks[i].IPFamily)
+				if family == config.IPFamilyV6 {
+					assert.Contains(t, subTasks[i].Args, "--client={{ .ServerAddressV6 }}")
+					assert.Contains(t, subTasks[i].Args, "--version6")
+				} else {
+					assert.Contains(t, subTasks[i].Args, "--client={{ .ServerAddressV4 }}")
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,38 @@
+/*
+Copyright 2022 Cloudical Deutschland GmbH. All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package testers
+
+import (
+	"github.com/cloudical-io/ancientt/pkg/config"
+)
+
+// IPFamilies return the IP families a client runs the test with for the given test IP family option
+func IPFamilies(family config.IPFamily) []config.IPFamily {
+	switch family {
+	case config.IPFamilyV6:
+		return []config.IPFamily{config.IPFamilyV6}
+	case config.IPFamilyBoth:
+		return []config.IPFamily{config.IPFamilyV4, config.IPFamilyV6}
+	default:
+		return []config.IPFamily{config.IPFamilyV4}
+	}
+}
+
+// ServerAddressTemplate return the command template variable of the server address for the IP family
+func ServerAddressTemplate(family config.IPFamily) string {
+	if family == config.IPFamilyV6 {
+		return "{{ .ServerAddressV6 }}"
+	}
+	return "{{ .ServerAddressV4 }}"
+}
@@ -0,0 +1,162 @@
+/*
+Copyright 2022 Cloudical Deutschland GmbH. All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package testers
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/cloudical-io/ancientt/pkg/config"
+	"github.com/sirupsen/logrus"
+	log "github.com/sirupsen/logrus"
+)
+
+// Pair server host and the client hosts which test against it
+type Pair struct {
+	Server  *Host
+	Clients []*Host
+}
+
+// BuildPairs pair the server and client hosts using the given pairing strategy. The pairs and their clients are sorted
+// by host name, servers without clients are not returned.
+func BuildPairs(hosts *Hosts, pairing config.Pairing) ([]*Pair, error) {
+	servers := sortedHosts(hosts.Servers)
+	clients := sortedHosts(hosts.Clients)
+
+	var pairs []*Pair
+	switch pairing.Strategy {
+	case "", config.PairingStrategyAll:
+		pairs = pairEach(servers, clients, func(server *Host, client *Host) bool {
+			return true
+		})
+	case config.PairingStrategyMesh:
+		all := mergeHosts(hosts)
+		pairs = pairEach(all, all, func(server *Host, client *Host) bool {
+			return server.Name != client.Name
+		})
+	case config.PairingStrategyRing:
+		all := mergeHosts(hosts)
+		if len(all) < 2 {
+			break
+		}
+		for i, server := range all {
+			client := all[(i+1)%len(all)]
+			pairs = append(pairs, &Pair{Server: server, Clients: []*Host{client}})
+		}
+	case config.PairingStrategyOneToOne:
+		pairs = pairOneToOne(servers, clients)
+	case config.PairingStrategySameZone, config.PairingStrategyCrossZone:
+		if pairing.TopologyLabel == "" {
+			return nil, fmt.Errorf("no topology label given for pairing strategy %s", pairing.Strategy)
+		}
+		sameZone := pairing.Strategy == config.PairingStrategySameZone
+		pairs = pairEach(servers, clients, func(server *Host, client *Host) bool {
+			serverZone, ok := server.Labels[pairing.TopologyLabel]
+			if !ok {
+				return false
+			}
+			clientZone, ok := client.Labels[pairing.TopologyLabel]
+			if !ok {
+				return false
+			}
+			return server.Name != client.Name && (serverZone == clientZone) == sameZone
+		})
+	default:
+		return nil, fmt.Errorf("unknown pairing strategy %s", pairing.Strategy)
+	}
+
+	return pairs, nil
+}
+
+// pairOneToOne zip the servers and clients, the clients are rotated by the first offset which doesn't pair a host with
+// itself. When there is no such offset, the hosts which would be paired with themselves are dropped.
+func pairOneToOne(servers []*Host, clients []*Host) []*Pair {
+	count := len(servers)
+	if len(clients) < count {
+		count = len(clients)
+	}
+
+	offset := 0
+	for ; offset < len(clients); offset++ {
+		selfPair := false
+		for i := 0; i < count; i++ {
+			if servers[i].Name == clients[(i+offset)%len(clients)].Name {
+				selfPair = true
+				break
+			}
+		}
+		if !selfPair {
+			break
+		}
+	}
+	if offset == len(clients) {
+		offset = 0
+	}
+
+	pairs := []*Pair{}
+	dropped := []string{}
+	for i := 0; i < count; i++ {
+		client := clients[(i+offset)%len(clients)]
+		if servers[i].Name == client.Name {
+			dropped = append(dropped, client.Name)
+			continue
+		}
+		pairs = append(pairs, &Pair{Server: servers[i], Clients: []*Host{client}})
+	}
+	if len(dropped) > 0 {
+		log.WithFields(logrus.Fields{"pairing": config.PairingStrategyOneToOne}).Warnf("hosts %v can't be paired without pairing them with themselves, they are not tested", dropped)
+	}
+
+	return pairs
+}
+
+// pairEach pair each server with each client for which the match func returns true
+func pairEach(servers []*Host, clients []*Host, match func(server *Host, client *Host) bool) []*Pair {
+	pairs := []*Pair{}
+	for _, server := range servers {
+		pair := &Pair{Server: server}
+		for _, client := range clients {
+			if match(server, client) {
+				pair.Clients = append(pair.Clients, client)
+			}
+		}
+		if len(pair.Clients) > 0 {
+			pairs = append(pairs, pair)
+		}
+	}
+	return pairs
+}
+
+// mergeHosts return the server and client hosts as one list sorted by name
+func mergeHosts(hosts *Hosts) []*Host {
+	all := map[string]*Host{}
+	for name, host := range hosts.Servers {
+		all[name] = host
+	}
+	for name, host := range hosts.Clients {
+		all[name] = host
+	}
+	return sortedHosts(all)
+}
+
+func sortedHosts(hosts map[string]*Host) []*Host {
+	list := make([]*Host, 0, len(hosts))
+	for _, host := range hosts {
+		list = append(list, host)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Name < list[j].Name
+	})
+	return list
+}
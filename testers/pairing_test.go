@@ -0,0 +1,172 @@
+/*
+Copyright 2022 Cloudical Deutschland GmbH. All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package testers
+
+import (
+	"testing"
+
+	"github.com/cloudical-io/ancientt/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func generateHosts(zones map[string]string) map[string]*Host {
+	hosts := map[string]*Host{}
+	for name, zone := range zones {
+		host := &Host{
+			Name:   name,
+			Labels: map[string]string{},
+		}
+		if zone != "" {
+			host.Labels["zone"] = zone
+		}
+		hosts[name] = host
+	}
+	return hosts
+}
+
+// pairNames return the pairs as server name to client names
+func pairNames(pairs []*Pair) [][]string {
+	out := [][]string{}
+	for _, pair := range pairs {
+		names := []string{pair.Server.Name}
+		for _, client := range pair.Clients {
+			names = append(names, client.Name)
+		}
+		out = append(out, names)
+	}
+	return out
+}
+
+func TestBuildPairs(t *testing.T) {
+	tests := []struct {
+		name     string
+		hosts    *Hosts
+		pairing  config.Pairing
+		expected [][]string
+		err      bool
+	}{
+		{
+			name: "all is the default",
+			hosts: &Hosts{
+				Servers: generateHosts(map[string]string{"a": "", "b": ""}),
+				Clients: generateHosts(map[string]string{"a": "", "c": ""}),
+			},
+			pairing:  config.Pairing{},
+			expected: [][]string{{"a", "a", "c"}, {"b", "a", "c"}},
+		},
+		{
+			name: "mesh",
+			hosts: &Hosts{
+				Servers: generateHosts(map[string]string{"a": "", "b": ""}),
+				Clients: generateHosts(map[string]string{"a": "", "c": ""}),
+			},
+			pairing:  config.Pairing{Strategy: config.PairingStrategyMesh},
+			expected: [][]string{{"a", "b", "c"}, {"b", "a", "c"}, {"c", "a", "b"}},
+		},
+		{
+			name: "ring",
+			hosts: &Hosts{
+				Servers: generateHosts(map[string]string{"c": "", "a": ""}),
+				Clients: generateHosts(map[string]string{"b": ""}),
+			},
+			pairing:  config.Pairing{Strategy: config.PairingStrategyRing},
+			expected: [][]string{{"a", "b"}, {"b", "c"}, {"c", "a"}},
+		},
+		{
+			name: "ring with one host",
+			hosts: &Hosts{
+				Servers: generateHosts(map[string]string{"a": ""}),
+				Clients: generateHosts(map[string]string{"a": ""}),
+			},
+			pairing:  config.Pairing{Strategy: config.PairingStrategyRing},
+			expected: [][]string{},
+		},
+		{
+			name: "oneToOne",
+			hosts: &Hosts{
+				Servers: generateHosts(map[string]string{"s1": "", "s2": "", "s3": ""}),
+				Clients: generateHosts(map[string]string{"c1": "", "c2": ""}),
+			},
+			pairing:  config.Pairing{Strategy: config.PairingStrategyOneToOne},
+			expected: [][]string{{"s1", "c1"}, {"s2", "c2"}},
+		},
+		{
+			name: "oneToOne rotates the clients to avoid pairing a host with itself",
+			hosts: &Hosts{
+				Servers: generateHosts(map[string]string{"a": "", "b": "", "c": ""}),
+				Clients: generateHosts(map[string]string{"a": "", "b": "", "c": ""}),
+			},
+			pairing:  config.Pairing{Strategy: config.PairingStrategyOneToOne},
+			expected: [][]string{{"a", "b"}, {"b", "c"}, {"c", "a"}},
+		},
+		{
+			name: "oneToOne with one host",
+			hosts: &Hosts{
+				Servers: generateHosts(map[string]string{"a": ""}),
+				Clients: generateHosts(map[string]string{"a": ""}),
+			},
+			pairing:  config.Pairing{Strategy: config.PairingStrategyOneToOne},
+			expected: [][]string{},
+		},
+		{
+			name: "sameZone",
+			hosts: &Hosts{
+				Servers: generateHosts(map[string]string{"a1": "a", "b1": "b", "x": ""}),
+				Clients: generateHosts(map[string]string{"a1": "a", "a2": "a", "b2": "b", "y": ""}),
+			},
+			pairing:  config.Pairing{Strategy: config.PairingStrategySameZone, TopologyLabel: "zone"},
+			expected: [][]string{{"a1", "a2"}, {"b1", "b2"}},
+		},
+		{
+			name: "crossZone",
+			hosts: &Hosts{
+				Servers: generateHosts(map[string]string{"a1": "a", "b1": "b", "x": ""}),
+				Clients: generateHosts(map[string]string{"a1": "a", "a2": "a", "b2": "b", "y": ""}),
+			},
+			pairing:  config.Pairing{Strategy: config.PairingStrategyCrossZone, TopologyLabel: "zone"},
+			expected: [][]string{{"a1", "b2"}, {"b1", "a1", "a2"}},
+		},
+		{
+			name: "sameZone without topology label",
+			hosts: &Hosts{
+				Servers: generateHosts(map[string]string{"a": ""}),
+				Clients: generateHosts(map[string]string{"b": ""}),
+			},
+			pairing: config.Pairing{Strategy: config.PairingStrategySameZone},
+			err:     true,
+		},
+		{
+			name: "unknown strategy",
+			hosts: &Hosts{
+				Servers: generateHosts(map[string]string{"a": ""}),
+				Clients: generateHosts(map[string]string{"b": ""}),
+			},
+			pairing: config.Pairing{Strategy: "star"},
+			err:     true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pairs, err := BuildPairs(tt.hosts, tt.pairing)
+			if tt.err {
+				assert.NotNil(t, err)
+				return
+			}
+			require.Nil(t, err)
+			assert.Equal(t, tt.expected, pairNames(pairs))
+		})
+	}
+}
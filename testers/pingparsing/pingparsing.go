@@ -58,8 +58,14 @@ func (t PingParsing) Plan(env *testers.Environment, test *config.Test) (*testers
 		Commands:        make([][]*testers.Task, test.RunOptions.Rounds),
 	}
 
+	pairs, err := testers.BuildPairs(env.Hosts, test.Pairing)
+	if err != nil {
+		return nil, err
+	}
+
 	for i := 0; i < test.RunOptions.Rounds; i++ {
-		for _, server := range env.Hosts.Servers {
+		for j, pair := range pairs {
+			server := pair.Server
 			round := &testers.Task{
 				Status: &testers.Status{
 					SuccessfulHosts: testers.StatusHosts{
@@ -85,24 +91,28 @@ func (t PingParsing) Plan(env *testers.Environment, test *config.Test) (*testers
 			round.Command, round.Args = t.buildPingParsingServerCommand(server)
 
 			// Now go over each client and generate their Task
-			for _, client := range env.Hosts.Clients {
+			for _, client := range pair.Clients {
 				// Add client host to AffectedServers list
 				if _, ok := plan.AffectedServers[client.Name]; !ok {
 					plan.AffectedServers[client.Name] = client
 				}
 
-				// Build the PingParsing command
-				cmd, args := t.buildPingParsingClientCommand(server, client)
-				round.SubTasks = append(round.SubTasks, &testers.Task{
-					Host:    client,
-					Command: cmd,
-					Args:    args,
-				})
+				// Build the PingParsing command for each IP family
+				for _, family := range testers.IPFamilies(test.IPFamily) {
+					cmd, args := t.buildPingParsingClientCommand(server, client, family)
+					round.SubTasks = append(round.SubTasks, &testers.Task{
+						Host:     client,
+						Command:  cmd,
+						Args:     args,
+						IPFamily: family,
+					})
+				}
 			}
 			plan.Commands[i] = append(plan.Commands[i], round)
 
-			// Add the given interval after each round except the last one
-			if test.RunOptions.Interval != 0 && i != test.RunOptions.Rounds-1 {
+			// Add the given interval after each server task (batch of server tasks with `parallelServers`) except in
+			// the last round
+			if testers.IntervalAfterServerTask(test.RunOptions, i, j, len(pairs)) {
 				plan.Commands[i] = append(plan.Commands[i], &testers.Task{
 					Sleep: test.RunOptions.Interval,
 				})
@@ -119,7 +129,7 @@ func (t PingParsing) buildPingParsingServerCommand(server *testers.Host) (string
 }
 
 // buildPingParsingClientCommand
-func (t PingParsing) buildPingParsingClientCommand(server *testers.Host, client *testers.Host) (string, []string) {
+func (t PingParsing) buildPingParsingClientCommand(server *testers.Host, client *testers.Host, family config.IPFamily) (string, []string) {
 	// Base command and args
 	cmd := "pingparsing"
 	args := []string{
@@ -129,8 +139,13 @@ func (t PingParsing) buildPingParsingClientCommand(server *testers.Host, client
 		fmt.Sprintf("-w=%s", *t.config.Deadline),
 		fmt.Sprintf("--timeout=%s", *t.config.Timeout),
 		fmt.Sprintf("-I=%s", t.config.Interface),
-		"{{ .ServerAddressV4 }}",
 	}
 
+	if family == config.IPFamilyV6 {
+		args = append(args, "--ipv6")
+	}
+
+	args = append(args, testers.ServerAddressTemplate(family))
+
 	return cmd, args
 }
@@ -15,6 +15,8 @@ package testers
 
 import (
 	"fmt"
+	"sort"
+	"sync"
 	"time"
 
 	"github.com/cloudical-io/ancientt/pkg/config"
@@ -39,6 +41,20 @@ type Environment struct {
 type Hosts struct {
 	Clients map[string]*Host `yaml:"clients"`
 	Servers map[string]*Host `yaml:"servers"`
+	// Seeds used for the random selection of hosts, by `clients/NAME` and `servers/NAME` of the hosts selections
+	Seeds map[string]int64 `yaml:"seeds,omitempty"`
+}
+
+// AddSeed record the seed used for the random selection of the named clients or servers hosts selection, nil seeds
+// (no random selection) are ignored
+func (h *Hosts) AddSeed(role string, name string, seed *int64) {
+	if seed == nil {
+		return
+	}
+	if h.Seeds == nil {
+		h.Seeds = map[string]int64{}
+	}
+	h.Seeds[fmt.Sprintf("%s/%s", role, name)] = *seed
 }
 
 // Host host information, like labels and addresses (will most of the time be filled by the runners.Runner)
@@ -61,6 +77,7 @@ type Plan struct {
 	Commands        [][]*Task         `json:"commands"`
 	Tester          string            `json:"tester"`
 	RunOptions      config.RunOptions `json:"runOptions"`
+	Seeds           map[string]int64  `json:"seeds,omitempty"`
 }
 
 // PrettyPrint "pretty" prints a plan
@@ -70,6 +87,13 @@ func (p Plan) PrettyPrint() {
 		fmt.Println(server.Name)
 	}
 	fmt.Println("=> END AffectedServers")
+	if len(p.Seeds) > 0 {
+		fmt.Println("-> BEGIN Seeds")
+		for _, name := range p.SeedNames() {
+			fmt.Printf("%s: %d\n", name, p.Seeds[name])
+		}
+		fmt.Println("=> END Seeds")
+	}
 	fmt.Println("-> BEGIN Commands")
 	for k, commands := range p.Commands {
 		round := k + 1
@@ -83,7 +107,7 @@ func (p Plan) PrettyPrint() {
 			fmt.Printf("----> RUN %s %s (Additional info: %+v; %+v)\n", command.Command, command.Args, command.Ports, command.Sleep)
 			for _, task := range command.SubTasks {
 				fmt.Printf("-----> BEGIN Client %s\n", task.Host.Name)
-				fmt.Printf("------> RUN %s %s (Additional info: %+v)\n", task.Command, task.Args, task.Ports)
+				fmt.Printf("------> RUN %s %s (Additional info: %+v; %s)\n", task.Command, task.Args, task.Ports, task.IPFamily)
 				fmt.Printf("=====> END Client %s\n", task.Host.Name)
 			}
 			fmt.Printf("===> END Server %s\n", command.Host.Name)
@@ -93,6 +117,16 @@ func (p Plan) PrettyPrint() {
 	fmt.Println("=> END Commands")
 }
 
+// SeedNames return the names of the seeds of the plan sorted
+func (p Plan) SeedNames() []string {
+	names := make([]string, 0, len(p.Seeds))
+	for name := range p.Seeds {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
 // Task information for the task to execute
 type Task struct {
 	Host     *Host         `json:"host"`
@@ -102,6 +136,8 @@ type Task struct {
 	Ports    Ports         `json:"ports"`
 	SubTasks []*Task       `json:"subTasks"`
 	Status   *Status       `yaml:"status"`
+	// IPFamily the client task connects to the server with
+	IPFamily config.IPFamily `json:"ipFamily,omitempty"`
 }
 
 // Ports TCP and UDP ports list
@@ -110,8 +146,9 @@ type Ports struct {
 	UDP []int32
 }
 
-// Status status info for a task
+// Status status info for a task, the Add* funcs are safe to be called concurrently by the client tasks
 type Status struct {
+	mutex           sync.Mutex
 	SuccessfulHosts StatusHosts        `json:"successfulHosts"`
 	FailedHosts     StatusHosts        `json:"failedHosts"`
 	Errors          map[string][]error `json:"errors"`
@@ -125,6 +162,9 @@ type StatusHosts struct {
 
 // AddFailedServer add a server host that failed with error to the Status list
 func (st *Status) AddFailedServer(host *Host, err error) {
+	st.mutex.Lock()
+	defer st.mutex.Unlock()
+
 	if _, ok := st.Errors[host.Name]; !ok {
 		st.Errors[host.Name] = []error{}
 	}
@@ -140,6 +180,9 @@ func (st *Status) AddFailedServer(host *Host, err error) {
 
 // AddFailedClient add a client host that failed with error to the Status list
 func (st *Status) AddFailedClient(host *Host, err error) {
+	st.mutex.Lock()
+	defer st.mutex.Unlock()
+
 	if _, ok := st.Errors[host.Name]; !ok {
 		st.Errors[host.Name] = []error{}
 	}
@@ -155,6 +198,9 @@ func (st *Status) AddFailedClient(host *Host, err error) {
 
 // AddSuccessfulServer add a successful server host to the list
 func (st *Status) AddSuccessfulServer(host *Host) {
+	st.mutex.Lock()
+	defer st.mutex.Unlock()
+
 	// Increase successful host counter
 	if _, ok := st.SuccessfulHosts.Servers[host.Name]; !ok {
 		st.SuccessfulHosts.Servers[host.Name] = 1
@@ -165,6 +211,9 @@ func (st *Status) AddSuccessfulServer(host *Host) {
 
 // AddSuccessfulClient add a successful client host to the list
 func (st *Status) AddSuccessfulClient(host *Host) {
+	st.mutex.Lock()
+	defer st.mutex.Unlock()
+
 	// Increase successful host counter
 	if _, ok := st.SuccessfulHosts.Clients[host.Name]; !ok {
 		st.SuccessfulHosts.Clients[host.Name] = 1
@@ -172,3 +221,18 @@ func (st *Status) AddSuccessfulClient(host *Host) {
 		st.SuccessfulHosts.Clients[host.Name]++
 	}
 }
+
+// IntervalAfterServerTask return true when the interval sleep task is added after the server task with the index of the
+// count server tasks of the round. The server tasks of a batch of `ParallelServers` are run at the same time, so the
+// interval is only added after each batch. There is no interval after the last round.
+func IntervalAfterServerTask(runOpts config.RunOptions, round int, index int, count int) bool {
+	if runOpts.Interval == 0 || round == runOpts.Rounds-1 {
+		return false
+	}
+
+	batch := 1
+	if runOpts.Mode == config.RunModeParallel && runOpts.ParallelServers > 1 {
+		batch = runOpts.ParallelServers
+	}
+	return (index+1)%batch == 0 || index == count-1
+}